@@ -0,0 +1,152 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
+	"github.com/newrelic/infrastructure-agent/internal/httpapi"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+)
+
+const defaultLogLevelDuration = time.Duration(log.DefaultVerboseMin) * time.Minute
+
+const commandsUsage = `Commands:
+  status                          print the agent status report (requires status_server_enabled: true)
+
+The following commands require the agent control server (control_server_enabled: true) and have to be
+run by the user running the agent, as the control socket is only accessible by it:
+  integrations                    list the running integrations
+  run <integration>               run once the integration with the given name
+  stop <integration>              stop the integration with the given name
+  restart <integration>           restart the integration with the given name
+  reload                          reload the agent configuration
+  log-level <level> [duration]    set the log level for a duration (default 5m)
+
+Without command, verbose logging is enabled for 5 minutes.
+`
+
+var errUsage = errors.New("invalid command usage")
+
+// controlClient sends the control requests to the agent.
+type controlClient interface {
+	Status() (status.Report, error)
+	Integrations() ([]status.IntegrationReport, error)
+	RunIntegration(name string) error
+	StopIntegration(name string) error
+	RestartIntegration(name string) error
+	ReloadConfig() (httpapi.ConfigReloadResponse, error)
+	SetLogLevel(level string, duration time.Duration) error
+}
+
+var _ controlClient = (*httpapi.ControlClient)(nil)
+
+// runCommand executes the command described by args, writing its output.
+func runCommand(client controlClient, args []string, out io.Writer) error {
+	cmd, cmdArgs := args[0], args[1:]
+	switch cmd {
+	case "status":
+		if len(cmdArgs) != 0 {
+			return errUsage
+		}
+		report, err := client.Status()
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+
+	case "integrations":
+		if len(cmdArgs) != 0 {
+			return errUsage
+		}
+		integrations, err := client.Integrations()
+		if err != nil {
+			return err
+		}
+		return printIntegrations(integrations, out)
+
+	case "run", "stop", "restart":
+		if len(cmdArgs) != 1 {
+			return errUsage
+		}
+		action := map[string]func(string) error{
+			"run":     client.RunIntegration,
+			"stop":    client.StopIntegration,
+			"restart": client.RestartIntegration,
+		}[cmd]
+		if err := action(cmdArgs[0]); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(out, "Integration %q: %s requested.\n", cmdArgs[0], cmd)
+		return err
+
+	case "reload":
+		if len(cmdArgs) != 0 {
+			return errUsage
+		}
+		resp, err := client.ReloadConfig()
+		if err != nil {
+			return err
+		}
+		return printReload(out, resp)
+
+	case "log-level":
+		if len(cmdArgs) < 1 || len(cmdArgs) > 2 {
+			return errUsage
+		}
+		duration := defaultLogLevelDuration
+		if len(cmdArgs) == 2 {
+			var err error
+			if duration, err = time.ParseDuration(cmdArgs[1]); err != nil {
+				return fmt.Errorf("invalid duration: %w", err)
+			}
+		}
+		if err := client.SetLogLevel(cmdArgs[0], duration); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(out, "Log level set to %s for %s.\n", cmdArgs[0], duration)
+		return err
+	}
+	return errUsage
+}
+
+func printReload(out io.Writer, resp httpapi.ConfigReloadResponse) error {
+	fmt.Fprintln(out, "Configuration reloaded.")
+	if len(resp.Applied) > 0 {
+		fmt.Fprintf(out, "Applied: %s\n", strings.Join(resp.Applied, ", "))
+	}
+	if len(resp.RestartRequired) > 0 {
+		fmt.Fprintf(out, "Restart required to apply: %s\n", strings.Join(resp.RestartRequired, ", "))
+	}
+	return nil
+}
+
+func printIntegrations(integrations []status.IntegrationReport, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCONFIG FILE\tLAST RUN\tNEXT RUN\tEXIT CODE\tERROR")
+	for _, i := range integrations {
+		exitCode := "-"
+		if i.LastExitCode != nil {
+			exitCode = fmt.Sprint(*i.LastExitCode)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			i.Name, i.ConfigFile, orDash(i.LastRun), orDash(i.NextRun), exitCode, orDash(i.LastError))
+	}
+	return w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
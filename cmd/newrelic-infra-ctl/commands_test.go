@@ -0,0 +1,113 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
+	"github.com/newrelic/infrastructure-agent/internal/httpapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	calls []string
+}
+
+func (c *fakeClient) Status() (status.Report, error) {
+	c.calls = append(c.calls, "status")
+	return status.Report{Config: &status.ConfigReport{ReachabilityTimeout: "10s"}}, nil
+}
+
+func (c *fakeClient) Integrations() ([]status.IntegrationReport, error) {
+	code := 3
+	return []status.IntegrationReport{
+		{Name: "nri-redis", ConfigFile: "/etc/redis.yml", LastExitCode: &code, LastError: "exit status 3"},
+	}, nil
+}
+
+func (c *fakeClient) RunIntegration(name string) error {
+	c.calls = append(c.calls, "run "+name)
+	return nil
+}
+
+func (c *fakeClient) StopIntegration(name string) error {
+	c.calls = append(c.calls, "stop "+name)
+	return errors.New("not found")
+}
+
+func (c *fakeClient) RestartIntegration(name string) error {
+	c.calls = append(c.calls, "restart "+name)
+	return nil
+}
+
+func (c *fakeClient) ReloadConfig() (httpapi.ConfigReloadResponse, error) {
+	c.calls = append(c.calls, "reload")
+	return httpapi.ConfigReloadResponse{Applied: []string{"log.level"}, RestartRequired: []string{"license_key"}}, nil
+}
+
+func (c *fakeClient) SetLogLevel(level string, duration time.Duration) error {
+	c.calls = append(c.calls, "log-level "+level+" "+duration.String())
+	return nil
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantCall  string
+		wantOut   string
+		wantError bool
+	}{
+		{args: []string{"status"}, wantCall: "status", wantOut: `"reachability_timeout": "10s"`},
+		{args: []string{"run", "nri-redis"}, wantCall: "run nri-redis", wantOut: `Integration "nri-redis": run requested.`},
+		{args: []string{"stop", "nri-redis"}, wantCall: "stop nri-redis", wantError: true},
+		{args: []string{"restart", "nri-redis"}, wantCall: "restart nri-redis"},
+		{args: []string{"reload"}, wantCall: "reload", wantOut: "Restart required to apply: license_key"},
+		{args: []string{"log-level", "debug"}, wantCall: "log-level debug 5m0s"},
+		{args: []string{"log-level", "trace", "30s"}, wantCall: "log-level trace 30s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args[0], func(t *testing.T) {
+			client := &fakeClient{}
+			out := &bytes.Buffer{}
+
+			err := runCommand(client, tt.args, out)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, []string{tt.wantCall}, client.calls)
+			assert.Contains(t, out.String(), tt.wantOut)
+		})
+	}
+}
+
+func TestRunCommand_Integrations(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	require.NoError(t, runCommand(&fakeClient{}, []string{"integrations"}, out))
+
+	assert.Contains(t, out.String(), "NAME")
+	assert.Contains(t, out.String(), "nri-redis")
+	assert.Contains(t, out.String(), "/etc/redis.yml")
+	assert.Contains(t, out.String(), "exit status 3")
+}
+
+func TestRunCommand_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{"unknown"},
+		{"run"},
+		{"stop", "a", "b"},
+		{"status", "extra"},
+		{"log-level"},
+	} {
+		err := runCommand(&fakeClient{}, args, &bytes.Buffer{})
+		assert.True(t, errors.Is(err, errUsage), "args: %v", args)
+	}
+}
@@ -6,6 +6,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"runtime"
 	"syscall"
 
+	"github.com/newrelic/infrastructure-agent/internal/httpapi"
 	"github.com/newrelic/infrastructure-agent/pkg/ipc"
 
 	"github.com/newrelic/infrastructure-agent/pkg/config"
@@ -21,9 +23,11 @@ import (
 )
 
 var (
-	agentPID    int
-	containerID string
-	apiVersion  string
+	agentPID         int
+	containerID      string
+	apiVersion       string
+	statusServerPort int
+	controlSocket    string
 )
 
 func init() {
@@ -47,11 +51,43 @@ func init() {
 		config.DefaultDockerApiVersion,
 		"Docker API version [Optional] (Containerised agent)",
 	)
+
+	flag.IntVar(
+		&statusServerPort,
+		"status-port",
+		config.DefaultStatusServerPort,
+		"New Relic infrastructure agent status server port, used by the status command",
+	)
+
+	flag.StringVar(
+		&controlSocket,
+		"control-socket",
+		config.DefaultControlServerSocket,
+		"New Relic infrastructure agent control socket path, used by the control commands",
+	)
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [command]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprint(flag.CommandLine.Output(), "\n"+commandsUsage)
+	}
 }
 
 func main() {
 	flag.Parse()
 
+	if flag.NArg() > 0 {
+		err := runCommand(httpapi.NewControlClient(statusServerPort, controlSocket), flag.Args(), os.Stdout)
+		if errors.Is(err, errUsage) {
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err != nil {
+			logrus.WithError(err).Fatal("Command failed.")
+		}
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	// Enables Control+C termination
 	go func() {
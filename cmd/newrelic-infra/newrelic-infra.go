@@ -39,6 +39,7 @@ import (
 	"github.com/newrelic/infrastructure-agent/internal/agent/cmdchannel/runintegration"
 	"github.com/newrelic/infrastructure-agent/internal/agent/cmdchannel/service"
 	"github.com/newrelic/infrastructure-agent/internal/agent/cmdchannel/stopintegration"
+	"github.com/newrelic/infrastructure-agent/internal/agent/control"
 	"github.com/newrelic/infrastructure-agent/internal/agent/status"
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/files"
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/integration"
@@ -89,6 +90,9 @@ var (
 	buildDate    = ""
 )
 
+// dmSpoolDir is the folder, under the agent dir, storing the failed dimensional metrics submissions.
+const dmSpoolDir = "dm_spool"
+
 func elapsedTime() time.Duration {
 	return time.Since(startTime)
 }
@@ -204,16 +208,7 @@ func main() {
 		os.Exit(0)
 	}
 
-	// override YAML with CLI flags
-	if verbose > config.NonVerboseLogging {
-		cfg.Verbose = verbose
-	}
-	if cpuprofile != "" {
-		cfg.CPUProfile = cpuprofile
-	}
-	if memprofile != "" {
-		cfg.MemProfile = memprofile
-	}
+	overrideWithFlags(cfg)
 
 	if cfg.Log.IsSmartLogging() {
 		wlog.EnableSmartVerboseMode(cfg.Log.GetSmartLogLevelLimit())
@@ -257,6 +252,45 @@ func main() {
 	}
 }
 
+// overrideWithFlags overrides YAML with CLI flags.
+func overrideWithFlags(cfg *config.Config) {
+	if verbose > config.NonVerboseLogging {
+		cfg.Verbose = verbose
+	}
+	if cpuprofile != "" {
+		cfg.CPUProfile = cpuprofile
+	}
+	if memprofile != "" {
+		cfg.MemProfile = memprofile
+	}
+}
+
+// reloadConfig loads again the configuration file the agent was started with.
+func reloadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	overrideWithFlags(cfg)
+	return cfg, nil
+}
+
+// applyLogConfig applies the reloaded log level, format and filters.
+func applyLogConfig(cfg *config.Config, changes config.Changes) {
+	if changes.Has("log.level", "verbose") {
+		level, err := wlog.ParseLevel(cfg.Log.Level)
+		if err != nil {
+			alog.WithError(err).Warn("Cannot apply reloaded log level.")
+		} else {
+			wlog.SetLevel(level)
+			logrus.SetLevel(level)
+		}
+	}
+	if changes.Has("log.format", "log_format", "log.include_filters", "log.exclude_filters") {
+		configureLogFormat(cfg.Log)
+	}
+}
+
 func logConfig(c *config.Config) {
 	// Log the configuration.
 	c.LogInfo()
@@ -363,6 +397,11 @@ func initializeAgentAndRun(c *config.Config, logFwCfg config.LogForward) error {
 	wlog.Instrument(instruments.Measure)
 
 	metricsSenderConfig := dm.NewConfig(c.DMIngestURL(), c.Fedramp, c.License, time.Duration(c.DMSubmissionPeriod)*time.Second, c.MaxMetricBatchEntitiesCount, c.MaxMetricBatchEntitiesQueue)
+	if c.DMSpoolEnabled {
+		// max age format is validated when loading the config
+		spoolMaxAge, _ := time.ParseDuration(c.DMSpoolMaxAge)
+		metricsSenderConfig = metricsSenderConfig.WithSpool(filepath.Join(c.AgentDir, dmSpoolDir), int64(c.DMSpoolMaxSizeMB)*1024*1024, spoolMaxAge)
+	}
 	dmSender, err := dm.NewDMSender(metricsSenderConfig, transport, agt.Context.IdContext().AgentIdentity)
 	if err != nil {
 		return err
@@ -413,18 +452,39 @@ func initializeAgentAndRun(c *config.Config, logFwCfg config.LogForward) error {
 		aslog.WithError(err).Warn("Commands initial fetch failed.")
 	}
 
-	if c.StatusServerEnabled || c.HTTPServerEnabled {
+	reloader := control.NewReloader(c, reloadConfig,
+		applyLogConfig,
+		func(cfg *config.Config, changes config.Changes) {
+			agt.ApplyConfig(cfg, changes)
+		},
+		func(cfg *config.Config, changes config.Changes) {
+			if changes.Has("plugin_dir") {
+				integrationManager.SetConfigPaths(cfg.PluginInstanceDirs)
+			}
+		},
+	)
+	controller := control.NewController(agt.Context.Ctx, integrationManager, reloader)
+
+	if c.StatusServerEnabled || c.HTTPServerEnabled || c.ControlServerEnabled {
 		rlog := wlog.WithComponent("status.Reporter")
 		timeoutD, err := time.ParseDuration(c.StartupConnectionTimeout)
 		if err != nil {
 			// This should never happen, as the correct format is checked during NormalizeConfig.
 			aslog.WithError(err).Error("invalid startup_connection_timeout value, cannot run status server")
 		} else {
-			rep := status.NewReporter(agt.Context.Ctx, rlog, c.StatusEndpoints, timeoutD, transport, agt.Context.AgentIdnOrEmpty, c.License, userAgent)
+			statusOpts := []status.ReporterOption{
+				status.WithIntegrations(integrationManager.IntegrationsStatus),
+				status.WithSamplers(agt.SamplersStatus),
+			}
+			if !c.IsForwardOnly {
+				statusOpts = append(statusOpts, status.WithInventory(agt.InventoryStatus))
+			}
+			rep := status.NewReporter(agt.Context.Ctx, rlog, c.StatusEndpoints, timeoutD, transport, agt.Context.AgentIdnOrEmpty, c.License, userAgent, statusOpts...)
 
 			apiSrv, err := httpapi.NewServer(rep, integrationEmitter)
 			if c.HTTPServerEnabled {
 				apiSrv.Ingest.Enable(c.HTTPServerHost, c.HTTPServerPort)
+				apiSrv.PrometheusEntity(c.HTTPServerPrometheusEntity)
 			}
 
 			if c.HTTPServerCert != "" && c.HTTPServerKey != "" {
@@ -439,6 +499,10 @@ func initializeAgentAndRun(c *config.Config, logFwCfg config.LogForward) error {
 				apiSrv.Status.Enable("localhost", c.StatusServerPort)
 			}
 
+			if c.ControlServerEnabled {
+				apiSrv.Control(controller, c.ControlServerSocket)
+			}
+
 			if err != nil {
 				aslog.WithError(err).Error("cannot run api server")
 			} else {
@@ -448,7 +512,14 @@ func initializeAgentAndRun(c *config.Config, logFwCfg config.LogForward) error {
 	}
 
 	if c.TCPServerEnabled {
-		go socketapi.NewServer(integrationEmitter, c.TCPServerPort).Serve(agt.Context.Ctx)
+		socketCfg := socketapi.Config{
+			Host:        c.TCPServerHost,
+			Port:        c.TCPServerPort,
+			UnixSocket:  c.TCPServerUnixSocket,
+			MaxLineSize: c.TCPServerMaxLineSize,
+			RateLimit:   c.TCPServerRateLimit,
+		}
+		go socketapi.NewServer(integrationEmitter, socketCfg).Serve(agt.Context.Ctx)
 	}
 
 	// Start all plugins we want the agent to run.
@@ -483,6 +554,18 @@ func initializeAgentAndRun(c *config.Config, logFwCfg config.LogForward) error {
 
 	go integrationManager.Start(agt.Context.Ctx)
 
+	reload := func() {
+		if _, err := controller.ReloadConfig(); err != nil {
+			aslog.WithError(err).Warn("Cannot reload configuration.")
+		}
+	}
+	go control.ReloadOnSignal(agt.Context.Ctx, reload)
+	if c.WatchConfigFile {
+		if file := config.ResolveConfigFile(configFile); file != "" {
+			go control.ReloadOnFileChange(agt.Context.Ctx, file, reload)
+		}
+	}
+
 	go ccService.Run(agt.Context.Ctx, agt.Context.AgentIdnOrEmpty, initCmdResponse)
 
 	pluginRegistry := legacy.NewPluginRegistry(pluginSourceDirs, c.PluginInstanceDirs)
@@ -37,6 +37,7 @@ import (
 	"github.com/newrelic/infrastructure-agent/internal/agent/debug"
 	"github.com/newrelic/infrastructure-agent/internal/agent/delta"
 	"github.com/newrelic/infrastructure-agent/internal/agent/id"
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
 	"github.com/newrelic/infrastructure-agent/pkg/disk"
 	"github.com/newrelic/infrastructure-agent/pkg/entity"
 	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
@@ -80,11 +81,30 @@ type Agent struct {
 	agentID             *entity.ID                               // pointer as it's referred from several points
 	mtx                 sync.Mutex                               // Protect plugins
 	notificationHandler *ctl.NotificationHandlerWithCancellation // Handle ipc messaging.
+	ffRetriever         feature_flags.Retriever                  // Used to rebuild the sample matchers on config reload.
 }
 
 type inventoryState struct {
 	readyToReap    bool
 	sendErrorCount uint32
+	// submission status, read concurrently by the status reporter
+	statusLock  sync.RWMutex
+	lastSend    time.Time
+	lastSuccess time.Time
+	lastErr     error
+	errorCount  uint32
+}
+
+func (s *inventoryState) setSendStatus(sent time.Time, err error) {
+	s.statusLock.Lock()
+	defer s.statusLock.Unlock()
+
+	s.lastSend = sent
+	s.lastErr = err
+	s.errorCount = s.sendErrorCount
+	if err == nil {
+		s.lastSuccess = sent
+	}
 }
 
 // inventory holds the reaper and sender for the inventories of a given entity (local or remote), as well as their status
@@ -136,6 +156,7 @@ type AgentContext interface {
 type context struct {
 	Ctx            context2.Context
 	CancelFn       context2.CancelFunc
+	cfgLock        sync.RWMutex // replaced on config reload
 	cfg            *config.Config
 	id             *id.Context
 	agentKey       atomic.Value
@@ -150,7 +171,8 @@ type context struct {
 	resolver           hostname.ResolverChangeNotifier
 	EntityMap          entity.KnownIDs
 	idLookup           host.IDLookup
-	shouldIncludeEvent sampler.IncludeSampleMatchFn
+	shouldIncludeLock  sync.RWMutex
+	shouldIncludeMatch sampler.IncludeSampleMatchFn
 }
 
 func (c *context) Context() context2.Context {
@@ -213,7 +235,7 @@ func NewContext(
 		servicePids:        make(map[string]map[int]string),
 		resolver:           resolver,
 		idLookup:           lookup,
-		shouldIncludeEvent: sampleMatchFn,
+		shouldIncludeMatch: sampleMatchFn,
 		agentKey:           agentKey,
 	}
 }
@@ -271,7 +293,7 @@ func NewAgent(
 	cloudHarvester.Initialize()
 
 	idLookupTable := NewIdLookup(hostnameResolver, cloudHarvester, cfg.DisplayName)
-	sampleMatchFn := sampler.NewSampleMatchFn(cfg.EnableProcessMetrics, cfg.IncludeMetricsMatchers, ffRetriever)
+	sampleMatchFn := sampler.NewSampleMatchFn(cfg.EnableProcessMetrics, cfg.IncludeMetricsMatchers, cfg.ExcludeMetricsMatchers, ffRetriever)
 	ctx := NewContext(cfg, buildVersion, hostnameResolver, idLookupTable, sampleMatchFn)
 
 	agentKey, err := idLookupTable.AgentKey()
@@ -338,7 +360,7 @@ func NewAgent(
 	// notificationHandler will map ipc messages to functions
 	notificationHandler := ctl.NewNotificationHandlerWithCancellation(ctx.Ctx)
 
-	return New(
+	a, err = New(
 		cfg,
 		ctx,
 		userAgent,
@@ -352,6 +374,11 @@ func NewAgent(
 		fpHarvester,
 		notificationHandler,
 	)
+	if err != nil {
+		return nil, err
+	}
+	a.ffRetriever = ffRetriever
+	return a, nil
 }
 
 // New creates a new agent using given context and services.
@@ -421,8 +448,8 @@ func New(
 	}
 
 	// Create input channel for plugins to feed data back to the agent
-	llog.WithField(config.TracesFieldName, config.FeatureTrace).Tracef("inventory parallelize queue: %v", a.Context.cfg.InventoryQueueLen)
-	a.Context.ch = make(chan PluginOutput, a.Context.cfg.InventoryQueueLen)
+	llog.WithField(config.TracesFieldName, config.FeatureTrace).Tracef("inventory parallelize queue: %v", a.Context.Config().InventoryQueueLen)
+	a.Context.ch = make(chan PluginOutput, a.Context.Config().InventoryQueueLen)
 	a.Context.activeEntities = make(chan string, activeEntitiesBufferLength)
 
 	if cfg.RegisterEnabled {
@@ -475,7 +502,7 @@ func (a *Agent) registerEntityInventory(entity entity.Entity) error {
 	var inv inventory
 
 	var err error
-	if a.Context.cfg.RegisterEnabled {
+	if a.Context.Config().RegisterEnabled {
 		inv.sender, err = newPatchSenderVortex(entityKey, a.Context.getAgentKey(), a.Context, a.store, a.userAgent, a.Context.Identity, a.provideIDs, a.entityMap, a.httpClient)
 	} else {
 		fileName := a.store.EntityFolder(entity.Key.String())
@@ -525,9 +552,61 @@ func (a *Agent) Terminate() {
 }
 
 func (a *Agent) RegisterMetricsSender(s registerableSender) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
 	a.metricsSender = s
 }
 
+// SamplersStatus returns the status of the samplers of the registered metrics sender, if it reports it.
+func (a *Agent) SamplersStatus() []status.SamplerReport {
+	a.mtx.Lock()
+	sender := a.metricsSender
+	a.mtx.Unlock()
+
+	if sr, ok := sender.(interface {
+		SamplersStatus() []status.SamplerReport
+	}); ok {
+		return sr.SamplersStatus()
+	}
+	return nil
+}
+
+// InventoryStatus returns the status of the inventory submission.
+func (a *Agent) InventoryStatus() status.InventoryReport {
+	a.inv.statusLock.RLock()
+	defer a.inv.statusLock.RUnlock()
+
+	report := status.InventoryReport{
+		LastSend:    status.FormatTime(a.inv.lastSend),
+		LastSuccess: status.FormatTime(a.inv.lastSuccess),
+		ErrorCount:  a.inv.errorCount,
+	}
+	if a.inv.lastErr != nil {
+		report.Error = a.inv.lastErr.Error()
+	}
+	return report
+}
+
+// ApplyConfig replaces the agent config with the reloaded one and applies its live changes: it rebuilds
+// the process metrics matchers and runs again the custom attributes plugin.
+func (a *Agent) ApplyConfig(cfg *config.Config, changes config.Changes) {
+	a.Context.setConfig(cfg)
+	if changes.Has("enable_process_metrics", "include_matching_metrics", "exclude_matching_metrics") {
+		a.Context.setSampleMatchFn(sampler.NewSampleMatchFn(cfg.EnableProcessMetrics, cfg.IncludeMetricsMatchers, cfg.ExcludeMetricsMatchers, a.ffRetriever))
+		alog.Info("Metrics matchers reloaded.")
+	}
+
+	if changes.Has("custom_attributes") {
+		a.mtx.Lock()
+		defer a.mtx.Unlock()
+		for _, p := range a.plugins {
+			if p.Id() == ids.CustomAttrsID {
+				go p.Run()
+			}
+		}
+	}
+}
+
 // RegisterPlugin takes a Plugin instance and registers it in the
 // agent's plugin map
 func (a *Agent) RegisterPlugin(p Plugin) {
@@ -657,7 +736,7 @@ func (a *Agent) Run() (err error) {
 	// start listening for ipc messages
 	_ = a.notificationHandler.Start()
 
-	cfg := a.Context.cfg
+	cfg := a.Context.Config()
 
 	f := a.cpuProfileStart()
 	if f != nil {
@@ -841,12 +920,12 @@ func (a *Agent) Run() (err error) {
 func (a *Agent) cpuProfileStart() *os.File {
 
 	// Start CPU profiling
-	if a.Context.cfg.CPUProfile == "" {
+	if a.Context.Config().CPUProfile == "" {
 		return nil
 	}
 
 	clog.Debug("Starting CPU profiling.")
-	f, err := os.Create(a.Context.cfg.CPUProfile)
+	f, err := os.Create(a.Context.Config().CPUProfile)
 	if err != nil {
 		clog.WithError(err).Error("could not create CPU profile file")
 		return nil
@@ -860,7 +939,7 @@ func (a *Agent) cpuProfileStart() *os.File {
 }
 
 func (a *Agent) cpuProfileStop(f *os.File) {
-	clog := alog.WithField("cpuProfile", a.Context.cfg.CPUProfile)
+	clog := alog.WithField("cpuProfile", a.Context.Config().CPUProfile)
 	clog.Debug("Stopping CPU profiling.")
 	pprof.StopCPUProfile()
 	helpers.CloseQuietly(f)
@@ -868,7 +947,7 @@ func (a *Agent) cpuProfileStop(f *os.File) {
 
 func (a *Agent) intervalMemoryProfile() {
 
-	cfg := a.Context.cfg
+	cfg := a.Context.Config()
 
 	if cfg.MemProfileInterval <= 0 {
 		return
@@ -890,10 +969,10 @@ func (a *Agent) intervalMemoryProfile() {
 
 func (a *Agent) dumpMemoryProfile(agentRuntimeMark int) {
 
-	if a.Context.cfg.MemProfile == "" {
+	if a.Context.Config().MemProfile == "" {
 		return
 	}
-	memProfileFilename := fmt.Sprintf("%s_%09ds", a.Context.cfg.MemProfile, agentRuntimeMark)
+	memProfileFilename := fmt.Sprintf("%s_%09ds", a.Context.Config().MemProfile, agentRuntimeMark)
 
 	mlog := alog.WithField("memProfile", memProfileFilename)
 	mlog.Debug("Starting memory profiling.")
@@ -941,9 +1020,12 @@ func (a *Agent) exitGracefully(sendTimer *time.Timer, reapTimer, removeEntitiesT
 
 func (a *Agent) sendInventory(sendTimer *time.Timer) {
 	backoffMax := config.MAX_BACKOFF
+	started := time.Now()
+	var sendErr error
 	for _, i := range a.inventories {
 		err := i.sender.Process()
 		if err != nil {
+			sendErr = err
 			if ingestError, ok := err.(*inventoryapi.IngestError); ok &&
 				ingestError.StatusCode == http.StatusTooManyRequests {
 				alog.Warn("server is rate limiting inventory submission")
@@ -960,7 +1042,8 @@ func (a *Agent) sendInventory(sendTimer *time.Timer) {
 			a.inv.sendErrorCount = 0
 		}
 	}
-	sendTimerVal := helpers.ExpBackoff(a.Context.cfg.SendInterval,
+	a.inv.setSendStatus(started, sendErr)
+	sendTimerVal := helpers.ExpBackoff(a.Context.Config().SendInterval,
 		time.Duration(backoffMax)*time.Second,
 		a.inv.sendErrorCount)
 	sendTimer.Reset(sendTimerVal)
@@ -1027,7 +1110,7 @@ func (c *context) SendEvent(event sample.Event, entityKey entity.Key) {
 	}
 
 	// truncates string fields larger than 4095 chars
-	if c.cfg.TruncTextValues {
+	if c.Config().TruncTextValues {
 		var truncated bool
 		origValue := fmt.Sprintf("+%v", event)
 		event, truncated = metric.TruncateLength(event, metric.NRDBLimit)
@@ -1063,9 +1146,17 @@ func (c *context) Unregister(id ids.PluginID) {
 }
 
 func (c *context) Config() *config.Config {
+	c.cfgLock.RLock()
+	defer c.cfgLock.RUnlock()
 	return c.cfg
 }
 
+func (c *context) setConfig(cfg *config.Config) {
+	c.cfgLock.Lock()
+	defer c.cfgLock.Unlock()
+	c.cfg = cfg
+}
+
 func (c *context) EntityKey() string {
 	return c.getAgentKey()
 }
@@ -1123,6 +1214,19 @@ func triggerAddReconnecting(l log.Entry) func(pluginID interface{}, plugin inter
 	}
 }
 
+// shouldIncludeEvent returns whether the event passes the configured metrics matchers.
+func (c *context) shouldIncludeEvent(event interface{}) bool {
+	c.shouldIncludeLock.RLock()
+	defer c.shouldIncludeLock.RUnlock()
+	return c.shouldIncludeMatch(event)
+}
+
+func (c *context) setSampleMatchFn(fn sampler.IncludeSampleMatchFn) {
+	c.shouldIncludeLock.Lock()
+	defer c.shouldIncludeLock.Unlock()
+	c.shouldIncludeMatch = fn
+}
+
 // HostnameResolver returns the host name resolver associated to the agent context
 func (c *context) HostnameResolver() hostname.Resolver {
 	return c.resolver
@@ -1145,7 +1249,7 @@ func (a *Agent) connect() {
 	alog.Debug("Performing connect.")
 	a.Context.SetAgentIdentity(a.connectSrv.Connect())
 
-	updateFreq := time.Duration(a.Context.cfg.FingerprintUpdateFreqSec) * time.Second
+	updateFreq := time.Duration(a.Context.Config().FingerprintUpdateFreqSec) * time.Second
 	ticker := time.NewTicker(updateFreq)
 
 	for range ticker.C {
@@ -1164,7 +1268,7 @@ func (a *Agent) enableVerboseLogging() error {
 	log.EnableTemporaryVerbose()
 
 	a.LogExternalPluginsInfo()
-	a.Context.cfg.LogInfo()
+	a.Context.Config().LogInfo()
 	a.ExternalPluginsHealthCheck()
 	return nil
 }
@@ -0,0 +1,118 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package control handles the agent control requests received from newrelic-infra-ctl through the
+// control socket, and the agent configuration reloads.
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
+	"github.com/newrelic/infrastructure-agent/internal/httpapi"
+	v4 "github.com/newrelic/infrastructure-agent/pkg/integrations/v4"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/sirupsen/logrus"
+)
+
+var clog = log.WithComponent("Control")
+
+// integrationsAttributes are the live agent config options read by the integrations manager. Changes in
+// the integrations configuration files are already picked up by the manager itself, and other options
+// used by integrations, like passthrough_environment, require an agent restart.
+var integrationsAttributes = []string{"plugin_dir"}
+
+// IntegrationsManager manages the lifecycle of the v4 integrations.
+type IntegrationsManager interface {
+	IntegrationsStatus() []status.IntegrationReport
+	RunIntegration(ctx context.Context, name string) error
+	StopIntegration(name string) error
+	RestartIntegration(name string) error
+	Reload(ctx context.Context)
+}
+
+// Controller executes the control requests over the agent components.
+type Controller struct {
+	ctx          context.Context
+	integrations IntegrationsManager
+	reloader     *Reloader // nil when the agent configuration can't be reloaded
+	// setLogLevel is replaceable for testing purposes
+	setLogLevel func(level logrus.Level, duration time.Duration) error
+}
+
+// NewController creates a controller whose background tasks are bound to the passed context.
+// The reloader is optional, when nil only the integrations configuration is reloaded.
+func NewController(ctx context.Context, integrations IntegrationsManager, reloader *Reloader) *Controller {
+	return &Controller{
+		ctx:          ctx,
+		integrations: integrations,
+		reloader:     reloader,
+		setLogLevel:  log.SetTemporaryLevel,
+	}
+}
+
+// Integrations returns the status of the running integrations.
+func (c *Controller) Integrations() []status.IntegrationReport {
+	return c.integrations.IntegrationsStatus()
+}
+
+// RunIntegration executes once, in background, the integrations with the given name.
+func (c *Controller) RunIntegration(name string) error {
+	clog.WithField("integration_name", name).Info("Running integration on request.")
+	return integrationErr(c.integrations.RunIntegration(c.ctx, name), name)
+}
+
+// StopIntegration stops the running integrations with the given name.
+func (c *Controller) StopIntegration(name string) error {
+	return integrationErr(c.integrations.StopIntegration(name), name)
+}
+
+// RestartIntegration starts again the integrations with the given name.
+func (c *Controller) RestartIntegration(name string) error {
+	return integrationErr(c.integrations.RestartIntegration(name), name)
+}
+
+// ReloadConfig reloads the agent configuration, applying the live changes, and then the integrations
+// configuration when the changes affect them. Without reloader, only the integrations are reloaded.
+func (c *Controller) ReloadConfig() (resp httpapi.ConfigReloadResponse, err error) {
+	if c.reloader == nil {
+		c.integrations.Reload(c.ctx)
+		return resp, nil
+	}
+
+	changes, err := c.reloader.Reload()
+	if err != nil {
+		return resp, err
+	}
+	resp.Applied = changes.Live
+	resp.RestartRequired = changes.Restart
+	if changes.Has(integrationsAttributes...) {
+		c.integrations.Reload(c.ctx)
+	}
+	return resp, nil
+}
+
+// SetLogLevel sets the agent log level for the given duration.
+func (c *Controller) SetLogLevel(level string, duration time.Duration) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("%w: %s", httpapi.ErrBadRequest, err)
+	}
+	if err := c.setLogLevel(lvl, duration); err != nil {
+		if errors.Is(err, log.ErrTemporaryLevelSet) {
+			return fmt.Errorf("%w: %s", httpapi.ErrBadRequest, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func integrationErr(err error, name string) error {
+	if errors.Is(err, v4.ErrIntegrationNotFound) {
+		return fmt.Errorf("%w: integration %q", httpapi.ErrNotFound, name)
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package control
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
+	"github.com/newrelic/infrastructure-agent/internal/httpapi"
+	v4 "github.com/newrelic/infrastructure-agent/pkg/integrations/v4"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeManager struct {
+	reloaded bool
+}
+
+func (m *fakeManager) IntegrationsStatus() []status.IntegrationReport {
+	return []status.IntegrationReport{{Name: "nri-redis"}}
+}
+
+func (m *fakeManager) RunIntegration(_ context.Context, name string) error {
+	return m.find(name)
+}
+
+func (m *fakeManager) StopIntegration(name string) error {
+	return m.find(name)
+}
+
+func (m *fakeManager) RestartIntegration(name string) error {
+	return m.find(name)
+}
+
+func (m *fakeManager) Reload(_ context.Context) {
+	m.reloaded = true
+}
+
+func (m *fakeManager) find(name string) error {
+	if name != "nri-redis" {
+		return v4.ErrIntegrationNotFound
+	}
+	return nil
+}
+
+var _ httpapi.Controller = (*Controller)(nil)
+
+func TestController_Integrations(t *testing.T) {
+	m := &fakeManager{}
+	c := NewController(context.Background(), m, nil)
+
+	assert.Equal(t, []status.IntegrationReport{{Name: "nri-redis"}}, c.Integrations())
+	assert.NoError(t, c.RunIntegration("nri-redis"))
+	assert.NoError(t, c.StopIntegration("nri-redis"))
+	assert.NoError(t, c.RestartIntegration("nri-redis"))
+	resp, err := c.ReloadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, resp)
+	assert.True(t, m.reloaded)
+
+	for _, action := range []func(string) error{c.RunIntegration, c.StopIntegration, c.RestartIntegration} {
+		err := action("unknown")
+		assert.True(t, errors.Is(err, httpapi.ErrNotFound))
+		assert.Contains(t, err.Error(), "unknown")
+	}
+}
+
+func TestController_SetLogLevel(t *testing.T) {
+	c := NewController(context.Background(), &fakeManager{}, nil)
+	var gotLevel logrus.Level
+	var gotDuration time.Duration
+	c.setLogLevel = func(level logrus.Level, duration time.Duration) error {
+		gotLevel, gotDuration = level, duration
+		return nil
+	}
+
+	require.NoError(t, c.SetLogLevel("debug", time.Minute))
+	assert.Equal(t, logrus.DebugLevel, gotLevel)
+	assert.Equal(t, time.Minute, gotDuration)
+
+	err := c.SetLogLevel("loud", time.Minute)
+	assert.True(t, errors.Is(err, httpapi.ErrBadRequest))
+
+	c.setLogLevel = func(logrus.Level, time.Duration) error {
+		return log.ErrTemporaryLevelSet
+	}
+	err = c.SetLogLevel("debug", time.Minute)
+	assert.True(t, errors.Is(err, httpapi.ErrBadRequest))
+}
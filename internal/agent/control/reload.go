@@ -0,0 +1,140 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package control
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// fileChangesDelay groups the several events an editor or a configuration management tool produce
+// when writing a file into a single reload.
+const fileChangesDelay = time.Second
+
+// ConfigLoader loads the agent configuration from its sources.
+type ConfigLoader func() (*config.Config, error)
+
+// ConfigApplier applies to a running component the live changes of the reloaded agent configuration, cfg,
+// which replaces the former one.
+type ConfigApplier func(cfg *config.Config, changes config.Changes)
+
+// Reloader reloads the agent configuration, applying the options that can be changed on a running agent.
+type Reloader struct {
+	lock     sync.Mutex
+	cfg      *config.Config
+	load     ConfigLoader
+	appliers []ConfigApplier
+}
+
+// NewReloader creates a reloader comparing the running agent configuration, cfg, with the loaded one.
+// Appliers are called on every reload with live changes, in the provided order, with a new configuration
+// holding them, so the running one is never modified.
+func NewReloader(cfg *config.Config, load ConfigLoader, appliers ...ConfigApplier) *Reloader {
+	return &Reloader{
+		cfg:      cfg,
+		load:     load,
+		appliers: appliers,
+	}
+}
+
+// Reload loads the configuration again and applies its live changes. It returns all the changes found,
+// including the ones requiring an agent restart.
+func (r *Reloader) Reload() (config.Changes, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	updated, err := r.load()
+	if err != nil {
+		return config.Changes{}, fmt.Errorf("cannot load configuration: %w", err)
+	}
+
+	live, changes := r.cfg.WithLive(updated)
+	if len(changes.Live) > 0 {
+		r.cfg = live
+		for _, apply := range r.appliers {
+			apply(r.cfg, changes)
+		}
+	}
+
+	rlog := clog.WithFieldsF(func() logrus.Fields {
+		return logrus.Fields{
+			"applied":         changes.Live,
+			"restartRequired": changes.Restart,
+		}
+	})
+	if len(changes.Restart) > 0 {
+		rlog.Warn("Configuration reloaded, some changes require restarting the agent.")
+	} else {
+		rlog.Info("Configuration reloaded.")
+	}
+	return changes, nil
+}
+
+// ReloadOnSignal calls reload every time the agent receives a SIGHUP signal, until the context is done.
+func ReloadOnSignal(ctx context.Context, reload func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigs:
+			clog.Info("Reloading configuration on SIGHUP.")
+			reload()
+		}
+	}
+}
+
+// ReloadOnFileChange calls reload every time the file is written or replaced, until the context is
+// done. The file folder is watched, so files replaced by renaming another one are also tracked.
+func ReloadOnFileChange(ctx context.Context, file string, reload func()) {
+	flog := clog.WithField("file", file)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		flog.WithError(err).Warn("Cannot watch configuration file changes.")
+		return
+	}
+	defer watcher.Close()
+
+	file = filepath.Clean(file)
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		flog.WithError(err).Warn("Cannot watch configuration file changes.")
+		return
+	}
+	flog.Debug("Watching configuration file changes.")
+
+	// stopped until the first event
+	delay := time.NewTimer(fileChangesDelay)
+	delay.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			delay.Stop()
+			return
+		case event := <-watcher.Events:
+			if filepath.Clean(event.Name) != file || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			delay.Reset(fileChangesDelay)
+		case <-delay.C:
+			flog.Info("Reloading configuration on file change.")
+			reload()
+		case err := <-watcher.Errors:
+			flog.WithError(err).Debug("Error watching configuration file changes.")
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package control
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/httpapi"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	// GIVEN a running config and an updated one with live and restart changes
+	cfg := config.NewConfig()
+	cfg.License = "license"
+	cfg.MetricsSystemSampleRate = 5
+
+	updated := config.NewConfig()
+	updated.License = "new-license"
+	updated.MetricsSystemSampleRate = 30
+
+	var applied []config.Changes
+	var live *config.Config
+	reloader := NewReloader(cfg, func() (*config.Config, error) {
+		return updated, nil
+	}, func(c *config.Config, changes config.Changes) {
+		live = c
+		applied = append(applied, changes)
+	})
+
+	// WHEN reloaded
+	changes, err := reloader.Reload()
+
+	// THEN live changes are passed to the appliers with a new config holding them
+	require.NoError(t, err)
+	assert.Equal(t, config.Changes{Live: []string{"metrics_system_sample_rate"}, Restart: []string{"license_key"}}, changes)
+	assert.Equal(t, []config.Changes{changes}, applied)
+	require.NotNil(t, live)
+	assert.Equal(t, 30, live.MetricsSystemSampleRate)
+	assert.Equal(t, "license", live.License)
+	// AND the running config isn't modified
+	assert.Equal(t, 5, cfg.MetricsSystemSampleRate)
+
+	// AND appliers aren't called again when there are no further live changes
+	_, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+}
+
+func TestReloader_LoadError(t *testing.T) {
+	reloader := NewReloader(config.NewConfig(), func() (*config.Config, error) {
+		return nil, errors.New("invalid license")
+	})
+
+	_, err := reloader.Reload()
+
+	assert.EqualError(t, err, "cannot load configuration: invalid license")
+}
+
+func TestController_ReloadConfig(t *testing.T) {
+	cfg := config.NewConfig()
+	updated := config.NewConfig()
+	updated.CustomAttributes = config.CustomAttributeMap{"env": "production"}
+	updated.CollectorURL = "https://collector.example.com"
+	m := &fakeManager{}
+	c := NewController(context.Background(), m, NewReloader(cfg, func() (*config.Config, error) {
+		return updated, nil
+	}))
+
+	resp, err := c.ReloadConfig()
+
+	require.NoError(t, err)
+	assert.Equal(t, httpapi.ConfigReloadResponse{
+		Applied:         []string{"custom_attributes"},
+		RestartRequired: []string{"collector_url"},
+	}, resp)
+	// the integrations are not affected by the changes
+	assert.False(t, m.reloaded)
+}
+
+func TestController_ReloadConfig_Integrations(t *testing.T) {
+	cfg := config.NewConfig()
+	updated := config.NewConfig()
+	updated.PluginDir = "/opt/integrations.d"
+	m := &fakeManager{}
+	c := NewController(context.Background(), m, NewReloader(cfg, func() (*config.Config, error) {
+		return updated, nil
+	}))
+
+	resp, err := c.ReloadConfig()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plugin_dir"}, resp.Applied)
+	assert.True(t, m.reloaded)
+}
+
+func TestReloadOnFileChange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "newrelic-infra.yml")
+	require.NoError(t, ioutil.WriteFile(file, []byte("license_key: abc\n"), 0644))
+
+	reloads := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ReloadOnFileChange(ctx, file, func() { reloads <- struct{}{} })
+	// give time to the watcher to start
+	time.Sleep(100 * time.Millisecond)
+
+	// WHEN other files in the folder are written
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.yml"), []byte("a: b\n"), 0644))
+	// AND the config file is written several times
+	for i := 0; i < 3; i++ {
+		require.NoError(t, ioutil.WriteFile(file, []byte("license_key: xyz\n"), 0644))
+	}
+
+	// THEN a single reload is requested
+	select {
+	case <-reloads:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "reload not requested")
+	}
+	select {
+	case <-reloads:
+		assert.Fail(t, "unexpected reload")
+	case <-time.After(2 * fileChangesDelay):
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/newrelic/infrastructure-agent/pkg/backend/backoff"
+	"github.com/newrelic/infrastructure-agent/pkg/backend/spool"
 
 	"github.com/newrelic/infrastructure-agent/internal/agent/id"
 
@@ -38,6 +40,9 @@ const (
 	EVENT_BATCH_TIMER_DURATION = 1 // seconds, How often we will queue batches of events even if we haven't hit max batch size
 )
 
+// eventSpoolDir is the folder, under the agent dir, storing the posts that couldn't be sent.
+const eventSpoolDir = "event_spool"
+
 var ilog = log.WithComponent("MetricsIngestSender")
 
 type eventData struct {
@@ -80,7 +85,8 @@ type metricsIngestSender struct {
 	agentIDProvide           id.Provide
 	connectEnabled           bool
 	getBackoffTimer          func(time.Duration) *time.Timer
-	postCount                uint64 // counts post requests for debugging purposes
+	postCount                uint64       // counts post requests for debugging purposes
+	spool                    *spool.Spool // nil when the events spool is disabled
 }
 
 func newMetricsIngestSender(ctx *context, licenseKey, userAgent string, httpClient backendhttp.Client, connectEnabled bool) *metricsIngestSender {
@@ -108,6 +114,11 @@ func newMetricsIngestSender(ctx *context, licenseKey, userAgent string, httpClie
 		maxMetricsBatchSizeBytes = config.DefaultMaxMetricsBatchSizeBytes
 	}
 
+	var eventSpool *spool.Spool
+	if cfg.EventSpoolEnabled {
+		eventSpool = newEventSpool(cfg)
+	}
+
 	return &metricsIngestSender{
 		eventQueue:               make(chan eventData, eventQueue),
 		batchQueue:               make(chan eventBatch, batchQueue),
@@ -123,9 +134,27 @@ func newMetricsIngestSender(ctx *context, licenseKey, userAgent string, httpClie
 		connectEnabled:           connectEnabled,
 		getBackoffTimer:          time.NewTimer,
 		postCount:                0,
+		spool:                    eventSpool,
 	}
 }
 
+// newEventSpool creates the events spool under the agent folder, returning nil if it can't be created.
+func newEventSpool(cfg *config.Config) *spool.Spool {
+	maxAge, err := time.ParseDuration(cfg.EventSpoolMaxAge)
+	if err != nil || maxAge <= 0 {
+		ilog.WithField("eventSpoolMaxAge", cfg.EventSpoolMaxAge).Warn("Invalid events spool max age, disabling the events spool.")
+		return nil
+	}
+	dir := filepath.Join(cfg.AgentDir, eventSpoolDir)
+	s, err := spool.New(dir, int64(cfg.EventSpoolMaxSizeMB)*1024*1024, maxAge)
+	if err != nil {
+		ilog.WithError(err).WithField("dir", dir).Warn("Cannot create events spool, disabling it.")
+		return nil
+	}
+	ilog.WithField("dir", dir).WithField("spooledPosts", s.Depth()).Debug("Events spool enabled.")
+	return s
+}
+
 // Start a couple of background routines to handle incoming data and post it to the server periodically.
 func (sender *metricsIngestSender) Start() (err error) {
 	if sender.stopChannel != nil {
@@ -140,7 +169,7 @@ func (sender *metricsIngestSender) Start() (err error) {
 
 	go func() {
 		defer sender.internalRoutineWaits.Done()
-		reportEventQueueMetrics(sender.eventQueue, sender.stopChannel)
+		reportEventQueueMetrics(sender.eventQueue, sender.spool, sender.stopChannel)
 	}()
 
 	go func() {
@@ -202,7 +231,7 @@ func (sender *metricsIngestSender) QueueEvent(event sample.Event, key entity.Key
 	}
 }
 
-func reportEventQueueMetrics(queue chan eventData, stopChannel chan bool) {
+func reportEventQueueMetrics(queue chan eventData, eventSpool *spool.Spool, stopChannel chan bool) {
 	sendTimer := time.NewTicker(time.Millisecond * 500)
 	for {
 		select {
@@ -213,6 +242,14 @@ func reportEventQueueMetrics(queue chan eventData, stopChannel chan bool) {
 			instrumentation.SelfInstrumentation.RecordMetric(goContext.Background(), metric)
 			metric = instrumentation.NewGauge("agent.eventQueueUtilization", float64((len(queue)*100)/cap(queue)))
 			instrumentation.SelfInstrumentation.RecordMetric(goContext.Background(), metric)
+			if eventSpool != nil {
+				metric = instrumentation.NewGauge("agent.eventSpoolDepth", float64(eventSpool.Depth()))
+				instrumentation.SelfInstrumentation.RecordMetric(goContext.Background(), metric)
+				metric = instrumentation.NewGauge("agent.eventSpoolBytes", float64(eventSpool.Bytes()))
+				instrumentation.SelfInstrumentation.RecordMetric(goContext.Background(), metric)
+				metric = instrumentation.NewGauge("agent.eventSpoolDroppedBytes", float64(eventSpool.DroppedBytes()))
+				instrumentation.SelfInstrumentation.RecordMetric(goContext.Background(), metric)
+			}
 		case <-stopChannel:
 			sendTimer.Stop()
 			return
@@ -288,6 +325,12 @@ type MetricPost struct {
 // MetricPostBatch HTTP post batching all the MetricPost per entity to be sent to the ingest service.
 type MetricPostBatch []*MetricPost
 
+// spooledPost is the on-disk representation of a post that couldn't be sent.
+type spooledPost struct {
+	AgentKey string          `json:"agentKey"`
+	Post     MetricPostBatch `json:"post"`
+}
+
 func newMetricPost(entityKey entity.Key, entityID, agentID entity.ID, agentKey string) *MetricPost {
 	mp := &MetricPost{
 		IsAgent: entityKey.String() == agentKey,
@@ -305,6 +348,27 @@ func newMetricPost(entityKey entity.Key, entityID, agentID entity.ID, agentKey s
 	return mp
 }
 
+// newMetricPostBatch groups the events of a batch by entity, returning also the key of the agent reporting them.
+func newMetricPostBatch(batch eventBatch, agentID entity.ID) (MetricPostBatch, string) {
+	agentKey := ""
+	var bulkPost MetricPostBatch
+	dataByEntity := make(map[entity.Key]*MetricPost)
+	// We need to rebuild the array of events as a []json.RawMessage, or else JSON marshalling won't handle them correctly.
+	for _, event := range batch {
+		entityData := dataByEntity[event.entityKey]
+		if entityData == nil {
+			entityData = newMetricPost(event.entityKey, event.entityID, agentID, event.agentKey)
+			dataByEntity[event.entityKey] = entityData
+			bulkPost = append(bulkPost, entityData)
+		}
+		entityData.Events = append(entityData.Events, event.data)
+		if event.agentKey != "" {
+			agentKey = event.agentKey
+		}
+	}
+	return bulkPost, agentKey
+}
+
 // getLoggingField will add an identifier for the MetricPost for the logs.
 func (mp *MetricPost) getLoggingField() logrus.Fields {
 	if len(mp.ExternalKeys) > 0 {
@@ -347,38 +411,22 @@ func (sender *metricsIngestSender) sendBatches() {
 			pclog := ilog.WithField("postCount", sender.postCount)
 			sender.postCount++
 
-			agentKey := ""
-			dataByEntity := make(map[entity.Key]*MetricPost)
-
 			ctx, seg := txn.StartSegment(ctx, "getAgentId")
 			agentID := sender.agentID()
 			seg.End()
 
 			ctx, seg = txn.StartSegment(ctx, "rebuildEvents")
-			// We need to rebuild the array of events as a []json.RawMessage, or else JSON marshalling won't handle them correctly.
-			for _, event := range batch {
-				entityData := dataByEntity[event.entityKey]
-				if entityData == nil {
-					entityData = newMetricPost(event.entityKey, event.entityID, agentID, event.agentKey)
-					dataByEntity[event.entityKey] = entityData
-				}
-				entityData.Events = append(entityData.Events, event.data)
-				if event.agentKey != "" {
-					agentKey = event.agentKey
-				}
-			}
+			bulkPost, agentKey := newMetricPostBatch(batch, agentID)
 			seg.End()
 
 			ctx, seg = txn.StartSegment(ctx, "prepareBulkPost")
-			var bulkPost MetricPostBatch
-			for _, entityData := range dataByEntity {
+			for _, entityData := range bulkPost {
 				metric := instrumentation.NewGauge("agent.postEventsNum", float64(len(entityData.Events)))
 				instrumentation.SelfInstrumentation.RecordMetric(ctx, metric)
 				pclog.WithFieldsF(entityData.getLoggingField).
 					WithFieldsF(entityData.getTimestampLoggingFields).
 					WithField("numEvents", len(entityData.Events)).
 					Debug("Sending events to metrics-ingest.")
-				bulkPost = append(bulkPost, entityData)
 			}
 			pclog.Debug("Preparing metrics post.")
 			seg.End()
@@ -389,12 +437,19 @@ func (sender *metricsIngestSender) sendBatches() {
 				pclog.Debug("Metrics post succeeded.")
 				sender.sendErrorCount = 0
 				retryBO.Reset()
+				// connectivity is back, so the posts that failed before are sent
+				if err = sender.replaySpool(ctx); err != nil {
+					pclog.WithError(err).Debug("Cannot replay spooled metrics posts.")
+				}
 				txn.End()
 				continue
 			}
 
 			sender.sendErrorCount++
 			pclog.WithError(err).WithField("sendErrorCount", sender.sendErrorCount).Error("metric sender can't process")
+			if isRetryable(err) {
+				sender.spoolPost(bulkPost, agentKey)
+			}
 
 			e, ok := err.(*errRetry)
 			if !ok {
@@ -437,13 +492,95 @@ func (s *metricsIngestSender) agentID() entity.ID {
 }
 
 // backoff waits for the specified duration or a signal from the stop
-// channel, whichever happens first.
+// channel, whichever happens first. When the events spool is enabled, the
+// batches queued meanwhile are spooled, so the queues don't fill up.
 func (s *metricsIngestSender) backoff(d time.Duration) {
 	backoffTimer := s.getBackoffTimer(d)
-	select {
-	case <-s.stopChannel:
-	case <-backoffTimer.C:
+
+	// receiving from a nil channel blocks forever
+	var batchQueue chan eventBatch
+	if s.spool != nil {
+		batchQueue = s.batchQueue
+	}
+	for {
+		select {
+		case <-s.stopChannel:
+			return
+		case <-backoffTimer.C:
+			return
+		case batch := <-batchQueue:
+			s.spoolPost(newMetricPostBatch(batch, s.agentID()))
+		}
+	}
+}
+
+// spoolPost stores on the events spool, if enabled, a post that couldn't be sent.
+func (s *metricsIngestSender) spoolPost(post MetricPostBatch, agentKey string) {
+	if s.spool == nil {
+		return
 	}
+	payload, err := json.Marshal(spooledPost{AgentKey: agentKey, Post: post})
+	if err == nil {
+		err = s.spool.Push(payload)
+	}
+	if err != nil {
+		ilog.WithError(err).Warn("Cannot spool metrics post, discarding it.")
+	}
+}
+
+// replaySpool sends the spooled posts, from the oldest to the newest. It stops on
+// the first post failing with a retryable error, which is kept on the spool to be
+// retried later. The posts rejected by the backend are dropped.
+func (s *metricsIngestSender) replaySpool(ctx goContext.Context) error {
+	if s.spool == nil {
+		return nil
+	}
+	for {
+		select {
+		case <-s.stopChannel:
+			return nil
+		default:
+		}
+
+		entry, ok, err := s.spool.Peek()
+		if err != nil {
+			ilog.WithError(err).Warn("Dropping unreadable spooled metrics post.")
+			s.spool.Drop(entry)
+			continue
+		}
+		if !ok {
+			return nil
+		}
+		var spooled spooledPost
+		if err = json.Unmarshal(entry.Payload, &spooled); err != nil {
+			ilog.WithError(err).Warn("Dropping unreadable spooled metrics post.")
+			s.spool.Drop(entry)
+			continue
+		}
+		if err = s.doPost(ctx, spooled.Post, spooled.AgentKey); err != nil {
+			if isRetryable(err) {
+				return err
+			}
+			ilog.WithError(err).Warn("Spooled metrics post not accepted, dropping it.")
+			s.spool.Drop(entry)
+			continue
+		}
+		s.spool.Pop(entry)
+		ilog.WithField("spooledPosts", s.spool.Depth()).Debug("Spooled metrics post sent.")
+	}
+}
+
+// isRetryable returns whether a failed post may be accepted later, so it's worth
+// spooling: the errors sending it and the server error, request timeout and too
+// many requests statuses. Other statuses reject the post permanently.
+func isRetryable(err error) bool {
+	e, ok := err.(*errRetry)
+	if !ok {
+		return true
+	}
+	return e.StatusCode >= http.StatusInternalServerError ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests
 }
 
 // Make one HTTP call to push a load of events up to the server
@@ -6,6 +6,7 @@ import (
 	"compress/gzip"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"log"
 	"net"
@@ -13,6 +14,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -21,6 +23,7 @@ import (
 	"github.com/newrelic/infrastructure-agent/pkg/entity/host"
 	infra "github.com/newrelic/infrastructure-agent/test/infra/http"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/newrelic/infrastructure-agent/pkg/config"
 	"github.com/newrelic/infrastructure-agent/pkg/entity"
@@ -540,6 +543,143 @@ func TestEventSender_ResponseError(t *testing.T) {
 	}
 }
 
+func TestEventSender_SpoolReplay(t *testing.T) {
+	var lock sync.Mutex
+	backendDown := true
+	var posted []string
+	client := func(req *http.Request) (*http.Response, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if backendDown {
+			return nil, errors.New("connection refused")
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		posted = append(posted, string(body))
+		return &http.Response{StatusCode: http.StatusAccepted, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}
+
+	cfg := &config.Config{
+		PayloadCompressionLevel: gzip.NoCompression,
+		AgentDir:                t.TempDir(),
+		EventSpoolEnabled:       true,
+		EventSpoolMaxSizeMB:     1,
+		EventSpoolMaxAge:        "1h",
+	}
+	sender := newMetricsIngestSender(newTestContext("testAgent", cfg), "license", "userAgent", client, false)
+	require.NotNil(t, sender.spool)
+	require.NoError(t, sender.Start())
+	defer sender.Stop()
+
+	// GIVEN an event that couldn't be sent
+	require.NoError(t, sender.QueueEvent(mapEvent{"eventType": "TestEvent", "value": "1"}, ""))
+	assert.Eventually(t, func() bool { return sender.spool.Depth() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// WHEN the backend is reachable again
+	lock.Lock()
+	backendDown = false
+	lock.Unlock()
+	require.NoError(t, sender.QueueEvent(mapEvent{"eventType": "TestEvent", "value": "2"}, ""))
+
+	// THEN the spooled event is sent after the new one
+	assert.Eventually(t, func() bool { return sender.spool.Depth() == 0 }, 5*time.Second, 10*time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []string{
+		`[{"ExternalKeys":["testAgent"],"IsAgent":true,"Events":[{"entityKey":"testAgent","eventType":"TestEvent","value":"2"}]}]`,
+		`[{"ExternalKeys":["testAgent"],"IsAgent":true,"Events":[{"entityKey":"testAgent","eventType":"TestEvent","value":"1"}]}]`,
+	}, posted)
+}
+
+func TestEventSender_SpoolReplay_RejectedPost(t *testing.T) {
+	var lock sync.Mutex
+	backendDown := true
+	var posted []string
+	client := func(req *http.Request) (*http.Response, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if backendDown {
+			return nil, errors.New("connection refused")
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(string(body), `"value":"1"`) {
+			return &http.Response{StatusCode: http.StatusBadRequest, Status: "400 Bad Request",
+				Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		}
+		posted = append(posted, string(body))
+		return &http.Response{StatusCode: http.StatusAccepted, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}
+
+	cfg := &config.Config{
+		PayloadCompressionLevel: gzip.NoCompression,
+		AgentDir:                t.TempDir(),
+		EventSpoolEnabled:       true,
+		EventSpoolMaxSizeMB:     1,
+		EventSpoolMaxAge:        "1h",
+	}
+	sender := newMetricsIngestSender(newTestContext("testAgent", cfg), "license", "userAgent", client, false)
+	require.NotNil(t, sender.spool)
+	sender.getBackoffTimer = func(time.Duration) *time.Timer { return time.NewTimer(0) }
+	require.NoError(t, sender.Start())
+	defer sender.Stop()
+
+	// GIVEN two events that couldn't be sent, the oldest one to be rejected by the backend
+	require.NoError(t, sender.QueueEvent(mapEvent{"eventType": "TestEvent", "value": "1"}, ""))
+	require.Eventually(t, func() bool { return sender.spool.Depth() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, sender.QueueEvent(mapEvent{"eventType": "TestEvent", "value": "2"}, ""))
+	require.Eventually(t, func() bool { return sender.spool.Depth() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// WHEN the backend is reachable again
+	lock.Lock()
+	backendDown = false
+	lock.Unlock()
+	require.NoError(t, sender.QueueEvent(mapEvent{"eventType": "TestEvent", "value": "3"}, ""))
+
+	// THEN the rejected post is dropped and doesn't block the newer spooled one
+	assert.Eventually(t, func() bool { return sender.spool.Depth() == 0 }, 5*time.Second, 10*time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []string{
+		`[{"ExternalKeys":["testAgent"],"IsAgent":true,"Events":[{"entityKey":"testAgent","eventType":"TestEvent","value":"3"}]}]`,
+		`[{"ExternalKeys":["testAgent"],"IsAgent":true,"Events":[{"entityKey":"testAgent","eventType":"TestEvent","value":"2"}]}]`,
+	}, posted)
+}
+
+func TestEventSender_RejectedPostNotSpooled(t *testing.T) {
+	client := func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusRequestEntityTooLarge, Status: "413 Request Entity Too Large",
+			Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}
+	cfg := &config.Config{
+		PayloadCompressionLevel: gzip.NoCompression,
+		AgentDir:                t.TempDir(),
+		EventSpoolEnabled:       true,
+		EventSpoolMaxSizeMB:     1,
+		EventSpoolMaxAge:        "1h",
+	}
+	sender := newMetricsIngestSender(newTestContext("testAgent", cfg), "license", "userAgent", client, false)
+	require.NotNil(t, sender.spool)
+	backoffCh := make(chan time.Duration, 1)
+	sender.getBackoffTimer = func(d time.Duration) *time.Timer {
+		backoffCh <- d
+		return time.NewTimer(0)
+	}
+	require.NoError(t, sender.Start())
+	defer sender.Stop()
+
+	// WHEN an event post is rejected by the backend
+	require.NoError(t, sender.QueueEvent(mapEvent{"eventType": "TestEvent", "value": "1"}, ""))
+	<-backoffCh
+
+	// THEN it isn't spooled
+	assert.Equal(t, 0, sender.spool.Depth())
+}
+
 func newTestContext(agentKey string, cfg *config.Config) *context {
 	var atomicAgentKey atomic.Value
 	atomicAgentKey.Store(agentKey)
@@ -21,6 +21,7 @@ const (
 // Report agent status report. It contains:
 // - checks:
 //   - backend endpoints reachability statuses
+//   - integrations, samplers and inventory submission statuses, when their providers are set
 //
 // - configuration
 // fields will be empty when ReportErrors() report no errors.
@@ -30,7 +31,10 @@ type Report struct {
 }
 
 type ChecksReport struct {
-	Endpoints []EndpointReport `json:"endpoints,omitempty"`
+	Endpoints    []EndpointReport    `json:"endpoints,omitempty"`
+	Integrations []IntegrationReport `json:"integrations,omitempty"`
+	Samplers     []SamplerReport     `json:"samplers,omitempty"`
+	Inventory    *InventoryReport    `json:"inventory,omitempty"`
 }
 
 // ConfigReport configuration used for status report.
@@ -45,6 +49,79 @@ type EndpointReport struct {
 	Error     string `json:"error,omitempty"`
 }
 
+// IntegrationReport represents the status of the last execution of a v4 integration.
+// Times are formatted as RFC3339 and empty when unknown.
+type IntegrationReport struct {
+	Name         string `json:"name"`
+	ConfigFile   string `json:"config_file,omitempty"`
+	Running      bool   `json:"running"`
+	LastRun      string `json:"last_run,omitempty"`
+	NextRun      string `json:"next_run,omitempty"`
+	LastExitCode *int   `json:"last_exit_code,omitempty"`
+	LastError    string `json:"last_error,omitempty"`
+	StderrTail   string `json:"stderr_tail,omitempty"`
+}
+
+// SamplerReport represents the status of the last sample of a metrics sampler.
+type SamplerReport struct {
+	Name       string `json:"name"`
+	Interval   string `json:"interval"`
+	LastSample string `json:"last_sample,omitempty"`
+	Duration   string `json:"duration,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Stale is set when no sample has been taken for more than twice the interval.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// InventoryReport represents the inventory submission status.
+type InventoryReport struct {
+	LastSend    string `json:"last_send,omitempty"`
+	LastSuccess string `json:"last_success,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ErrorCount  uint32 `json:"error_count"`
+}
+
+// IntegrationsProvide provides the status of the running integrations.
+type IntegrationsProvide func() []IntegrationReport
+
+// SamplersProvide provides the status of the registered samplers.
+type SamplersProvide func() []SamplerReport
+
+// InventoryProvide provides the inventory submission status.
+type InventoryProvide func() InventoryReport
+
+// ReporterOption sets optional status providers of a Reporter.
+type ReporterOption func(r *nrReporter)
+
+// WithIntegrations includes the integrations status in the reports.
+func WithIntegrations(provide IntegrationsProvide) ReporterOption {
+	return func(r *nrReporter) {
+		r.integrations = provide
+	}
+}
+
+// WithSamplers includes the samplers status in the reports.
+func WithSamplers(provide SamplersProvide) ReporterOption {
+	return func(r *nrReporter) {
+		r.samplers = provide
+	}
+}
+
+// WithInventory includes the inventory submission status in the reports.
+func WithInventory(provide InventoryProvide) ReporterOption {
+	return func(r *nrReporter) {
+		r.inventory = provide
+	}
+}
+
+// FormatTime formats times for the status reports, returning an empty string for zero times.
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // ReportEntity agent entity report.
 type ReportEntity struct {
 	GUID string `json:"guid"`
@@ -69,6 +146,10 @@ type nrReporter struct {
 	idProvide id.Provide
 	timeout   time.Duration
 	transport http.RoundTripper
+	// optional providers
+	integrations IntegrationsProvide
+	samplers     SamplersProvide
+	inventory    InventoryProvide
 }
 
 // Report reports agent status.
@@ -131,11 +212,19 @@ func (r *nrReporter) report(onlyErrors bool) (report Report, err error) {
 		}
 	}
 
+	iReports, iErrored := r.reportIntegrations(onlyErrors)
+	sReports, sErrored := r.reportSamplers(onlyErrors)
+	invReport, invErrored := r.reportInventory(onlyErrors)
+	errored = errored || iErrored || sErrored || invErrored
+
 	if !onlyErrors || errored {
 		if report.Checks == nil {
 			report.Checks = &ChecksReport{}
 		}
 		report.Checks.Endpoints = eReports
+		report.Checks.Integrations = iReports
+		report.Checks.Samplers = sReports
+		report.Checks.Inventory = invReport
 		report.Config = &ConfigReport{
 			ReachabilityTimeout: r.timeout.String(),
 		}
@@ -145,6 +234,52 @@ func (r *nrReporter) report(onlyErrors bool) (report Report, err error) {
 	return
 }
 
+// reportIntegrations returns the integrations reports (only the failed ones when onlyErrors is set)
+// and whether any of them failed.
+func (r *nrReporter) reportIntegrations(onlyErrors bool) (reports []IntegrationReport, errored bool) {
+	if r.integrations == nil {
+		return
+	}
+	for _, i := range r.integrations() {
+		failed := i.LastError != ""
+		if !onlyErrors || failed {
+			reports = append(reports, i)
+		}
+		errored = errored || failed
+	}
+	return
+}
+
+// reportSamplers returns the samplers reports (only the failed or stale ones when onlyErrors is
+// set) and whether any of them failed or is stale.
+func (r *nrReporter) reportSamplers(onlyErrors bool) (reports []SamplerReport, errored bool) {
+	if r.samplers == nil {
+		return
+	}
+	for _, s := range r.samplers() {
+		failed := s.Error != "" || s.Stale
+		if !onlyErrors || failed {
+			reports = append(reports, s)
+		}
+		errored = errored || failed
+	}
+	return
+}
+
+// reportInventory returns the inventory report (nil if it didn't fail and onlyErrors is set)
+// and whether the last submission failed.
+func (r *nrReporter) reportInventory(onlyErrors bool) (report *InventoryReport, errored bool) {
+	if r.inventory == nil {
+		return
+	}
+	inv := r.inventory()
+	errored = inv.Error != ""
+	if !onlyErrors || errored {
+		report = &inv
+	}
+	return
+}
+
 func (r *nrReporter) ReportEntity() (re ReportEntity, err error) {
 	return ReportEntity{
 		GUID: r.idProvide().GUID.String(),
@@ -161,9 +296,10 @@ func NewReporter(
 	agentIDProvide id.Provide,
 	license,
 	userAgent string,
+	opts ...ReporterOption,
 ) Reporter {
 
-	return &nrReporter{
+	r := &nrReporter{
 		ctx:       ctx,
 		log:       l,
 		endpoints: backendEndpoints,
@@ -173,4 +309,8 @@ func NewReporter(
 		timeout:   timeout,
 		transport: transport,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
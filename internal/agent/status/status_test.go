@@ -221,3 +221,62 @@ func TestNewReporter_ReportEntity(t *testing.T) {
 		})
 	}
 }
+
+func TestNewReporter_ReportComponents(t *testing.T) {
+	timeout := 10 * time.Millisecond
+	transport := &http.Transport{}
+	emptyIDProvide := func() entity.Identity {
+		return entity.EmptyIdentity
+	}
+	exitCode := 1
+	integrations := []IntegrationReport{
+		{Name: "nri-ok", LastRun: "2021-06-01T10:00:00Z"},
+		{Name: "nri-failing", LastExitCode: &exitCode, LastError: "exit status 1", StderrTail: "boom"},
+	}
+	samplers := []SamplerReport{
+		{Name: "SystemSampler", Interval: "5s"},
+		{Name: "ProcessSampler", Interval: "20s", Error: "cannot read /proc"},
+		{Name: "NetworkSampler", Interval: "10s", LastSample: "2021-06-01T10:00:00Z", Stale: true},
+	}
+
+	t.Run("full report includes all components", func(t *testing.T) {
+		r := NewReporter(context.Background(), log.WithComponent(t.Name()), []string{}, timeout, transport, emptyIDProvide, "user-agent", "agent-key",
+			WithIntegrations(func() []IntegrationReport { return integrations }),
+			WithSamplers(func() []SamplerReport { return samplers }),
+			WithInventory(func() InventoryReport { return InventoryReport{LastSend: "2021-06-01T10:00:00Z"} }),
+		)
+
+		got, err := r.Report()
+		require.NoError(t, err)
+		require.NotNil(t, got.Checks)
+		assert.Equal(t, integrations, got.Checks.Integrations)
+		assert.Equal(t, samplers, got.Checks.Samplers)
+		assert.Equal(t, &InventoryReport{LastSend: "2021-06-01T10:00:00Z"}, got.Checks.Inventory)
+	})
+
+	t.Run("errors report only includes failed components", func(t *testing.T) {
+		r := NewReporter(context.Background(), log.WithComponent(t.Name()), []string{}, timeout, transport, emptyIDProvide, "user-agent", "agent-key",
+			WithIntegrations(func() []IntegrationReport { return integrations }),
+			WithSamplers(func() []SamplerReport { return samplers }),
+			WithInventory(func() InventoryReport { return InventoryReport{} }),
+		)
+
+		got, err := r.ReportErrors()
+		require.NoError(t, err)
+		require.NotNil(t, got.Checks)
+		assert.Equal(t, integrations[1:], got.Checks.Integrations)
+		assert.Equal(t, samplers[1:], got.Checks.Samplers)
+		assert.Nil(t, got.Checks.Inventory)
+	})
+
+	t.Run("errors report is empty when components are healthy", func(t *testing.T) {
+		r := NewReporter(context.Background(), log.WithComponent(t.Name()), []string{}, timeout, transport, emptyIDProvide, "user-agent", "agent-key",
+			WithIntegrations(func() []IntegrationReport { return integrations[:1] }),
+			WithInventory(func() InventoryReport { return InventoryReport{} }),
+		)
+
+		got, err := r.ReportErrors()
+		require.NoError(t, err)
+		assert.Nil(t, got.Checks)
+	})
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
+)
+
+const (
+	controlIntegrationsAPIPath = "/v1/integrations"
+	controlRunAPIPath          = "/v1/integrations/:name/run"
+	controlStopAPIPath         = "/v1/integrations/:name/stop"
+	controlRestartAPIPath      = "/v1/integrations/:name/restart"
+	controlReloadAPIPath       = "/v1/config/reload"
+	controlLogLevelAPIPath     = "/v1/log/level"
+	integrationNameParam       = "name"
+
+	// controlSocketMode only allows the agent user to send control requests.
+	controlSocketMode = 0600
+)
+
+// ErrNotFound is returned, optionally wrapped, by a Controller when the requested item doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrBadRequest is returned, optionally wrapped, by a Controller when the request can't be handled
+// because of its arguments.
+var ErrBadRequest = errors.New("bad request")
+
+// Controller executes the agent control requests received through the control socket.
+type Controller interface {
+	// Integrations returns the status of the running integrations.
+	Integrations() []status.IntegrationReport
+	// RunIntegration executes once, in background, the integrations with the given name.
+	RunIntegration(name string) error
+	// StopIntegration stops the running integrations with the given name.
+	StopIntegration(name string) error
+	// RestartIntegration starts again the integrations with the given name.
+	RestartIntegration(name string) error
+	// ReloadConfig reloads the agent configuration, reporting the changed options.
+	ReloadConfig() (ConfigReloadResponse, error)
+	// SetLogLevel sets the log level for the given duration.
+	SetLogLevel(level string, duration time.Duration) error
+}
+
+// IntegrationsResponse is the response of the integrations list request.
+type IntegrationsResponse struct {
+	Integrations []status.IntegrationReport `json:"integrations"`
+}
+
+// ConfigReloadResponse is the response of the configuration reload request.
+type ConfigReloadResponse struct {
+	// Applied lists the changed options already applied to the running agent.
+	Applied []string `json:"applied"`
+	// RestartRequired lists the changed options only applied after restarting the agent.
+	RestartRequired []string `json:"restart_required"`
+}
+
+// LogLevelRequest is the request to temporarily change the log level.
+type LogLevelRequest struct {
+	Level string `json:"level"`
+	// Duration in Go duration format (e.g. "5m").
+	Duration string `json:"duration"`
+}
+
+// Control enables the agent control API, handled by the passed Controller. It's served on a unix
+// socket at the given path, only accessible by the user running the agent.
+func (s *Server) Control(c Controller, socketPath string) {
+	s.controller = c
+	s.controlSocket = socketPath
+}
+
+// serveControl serves the control API requests on the control socket until the context is cancelled.
+func (s *Server) serveControl(ctx context.Context) error {
+	listener, err := listenControlSocket(s.controlSocket)
+	if err != nil {
+		return err
+	}
+
+	router := httprouter.New()
+	s.registerControlRoutes(router)
+	server := &http.Server{Handler: router}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+		_ = os.Remove(s.controlSocket)
+	}()
+
+	s.logger.WithField("socket", s.controlSocket).Debug("Control API starting listening.")
+	if err = server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	s.logger.Debug("Control API stopped.")
+	return nil
+}
+
+// listenControlSocket listens on a unix socket with the controlSocketMode permissions. The socket
+// is created on a temporary path and moved once its permissions are set, so it's never accessible
+// by other users.
+func listenControlSocket(path string) (net.Listener, error) {
+	// socket left by a previous execution
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot remove control socket: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	_ = os.Remove(tmpPath)
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on control socket: %w", err)
+	}
+	// the socket is removed when the server stops, as it's not on the listening path anymore
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err = os.Chmod(tmpPath, controlSocketMode); err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = listener.Close()
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("cannot set up control socket: %w", err)
+	}
+	return listener, nil
+}
+
+func (s *Server) registerControlRoutes(router *httprouter.Router) {
+	router.GET(controlIntegrationsAPIPath, s.handleListIntegrations)
+	router.POST(controlRunAPIPath, s.handleIntegrationAction(s.controller.RunIntegration))
+	router.POST(controlStopAPIPath, s.handleIntegrationAction(s.controller.StopIntegration))
+	router.POST(controlRestartAPIPath, s.handleIntegrationAction(s.controller.RestartIntegration))
+	router.POST(controlReloadAPIPath, s.handleReload)
+	router.PUT(controlLogLevelAPIPath, s.handleLogLevel)
+}
+
+func (s *Server) handleListIntegrations(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	s.writeJSON(w, http.StatusOK, IntegrationsResponse{Integrations: s.controller.Integrations()})
+}
+
+func (s *Server) handleIntegrationAction(action func(string) error) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+		if err := action(ps.ByName(integrationNameParam)); err != nil {
+			s.writeControlError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	resp, err := s.controller.ReloadConfig()
+	if err != nil {
+		s.writeControlError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeControlError(w, fmt.Errorf("%w: cannot decode log level request: %s", ErrBadRequest, err))
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		s.writeControlError(w, fmt.Errorf("%w: invalid duration %q", ErrBadRequest, req.Duration))
+		return
+	}
+	if err := s.controller.SetLogLevel(req.Level, duration); err != nil {
+		s.writeControlError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeControlError writes the error response with a status code depending on the error type.
+func (s *Server) writeControlError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, ErrNotFound) {
+		code = http.StatusNotFound
+	} else if errors.Is(err, ErrBadRequest) {
+		code = http.StatusBadRequest
+	}
+	s.logger.WithError(err).Debug("Control request failed.")
+	s.writeJSON(w, code, responseError{Error: err.Error()})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.WithError(err).Warn("couldn't encode response")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
+)
+
+const (
+	// controlClientTimeout has to be longer than the endpoints reachability timeout of the status report.
+	controlClientTimeout = time.Minute
+	// controlBaseURL is the base URL of the requests sent through the control socket, whose host is ignored.
+	controlBaseURL = "http://control"
+)
+
+// ControlClient sends requests to the status server and the control socket of a local agent.
+type ControlClient struct {
+	statusURL     string
+	statusClient  *http.Client
+	controlClient *http.Client
+}
+
+// NewControlClient creates a client for the agent status server listening on the given port of
+// localhost and the control API listening on the given unix socket.
+func NewControlClient(statusPort int, controlSocket string) *ControlClient {
+	dialer := net.Dialer{}
+	return &ControlClient{
+		statusURL:    "http://" + net.JoinHostPort("localhost", strconv.Itoa(statusPort)),
+		statusClient: &http.Client{Timeout: controlClientTimeout},
+		controlClient: &http.Client{
+			Timeout: controlClientTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", controlSocket)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the agent full status report.
+func (c *ControlClient) Status() (report status.Report, err error) {
+	err = c.doStatus(http.MethodGet, statusAPIPath, nil, &report)
+	return
+}
+
+// Integrations returns the status of the running integrations.
+func (c *ControlClient) Integrations() ([]status.IntegrationReport, error) {
+	var resp IntegrationsResponse
+	err := c.do(http.MethodGet, controlIntegrationsAPIPath, nil, &resp)
+	return resp.Integrations, err
+}
+
+// RunIntegration requests a one-off execution of the integrations with the given name.
+func (c *ControlClient) RunIntegration(name string) error {
+	return c.do(http.MethodPost, integrationPath(controlRunAPIPath, name), nil, nil)
+}
+
+// StopIntegration requests to stop the integrations with the given name.
+func (c *ControlClient) StopIntegration(name string) error {
+	return c.do(http.MethodPost, integrationPath(controlStopAPIPath, name), nil, nil)
+}
+
+// RestartIntegration requests to restart the integrations with the given name.
+func (c *ControlClient) RestartIntegration(name string) error {
+	return c.do(http.MethodPost, integrationPath(controlRestartAPIPath, name), nil, nil)
+}
+
+// ReloadConfig requests to reload the agent configuration, returning the changed options.
+func (c *ControlClient) ReloadConfig() (resp ConfigReloadResponse, err error) {
+	err = c.do(http.MethodPost, controlReloadAPIPath, nil, &resp)
+	return
+}
+
+// SetLogLevel requests to change the log level for the given duration.
+func (c *ControlClient) SetLogLevel(level string, duration time.Duration) error {
+	return c.do(http.MethodPut, controlLogLevelAPIPath, LogLevelRequest{Level: level, Duration: duration.String()}, nil)
+}
+
+// integrationPath replaces the integration name parameter of the route path.
+func integrationPath(route, name string) string {
+	return strings.Replace(route, ":"+integrationNameParam, url.PathEscape(name), 1)
+}
+
+// do sends the request to the control socket.
+func (c *ControlClient) do(method, path string, body interface{}, out interface{}) error {
+	err := c.send(c.controlClient, controlBaseURL, method, path, body, out)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("cannot connect to the agent control socket, check control_server_enabled is set and you have permissions on it: %w", err)
+	}
+	return err
+}
+
+// doStatus sends the request to the status server.
+func (c *ControlClient) doStatus(method, path string, body interface{}, out interface{}) error {
+	err := c.send(c.statusClient, c.statusURL, method, path, body, out)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("cannot connect to the agent status server, check status_server_enabled is set: %w", err)
+	}
+	return err
+}
+
+// send sends the request, encoding the body and decoding the response into out, when not nil.
+func (c *ControlClient) send(client *http.Client, baseURL, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("cannot encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var rErr responseError
+		if jErr := json.Unmarshal(respBody, &rErr); jErr == nil && rErr.Error != "" {
+			return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, rErr.Error)
+		}
+		return fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("cannot decode response: %w", err)
+		}
+	}
+	return nil
+}
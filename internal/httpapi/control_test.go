@@ -0,0 +1,156 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
+	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/testhelp/testemit"
+	networkHelpers "github.com/newrelic/infrastructure-agent/pkg/helpers/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeController records the requested actions.
+type fakeController struct {
+	actions []string
+}
+
+func (c *fakeController) Integrations() []status.IntegrationReport {
+	return []status.IntegrationReport{{Name: "nri-redis", ConfigFile: "/etc/redis.yml"}}
+}
+
+func (c *fakeController) RunIntegration(name string) error {
+	return c.action("run", name)
+}
+
+func (c *fakeController) StopIntegration(name string) error {
+	return c.action("stop", name)
+}
+
+func (c *fakeController) RestartIntegration(name string) error {
+	return c.action("restart", name)
+}
+
+func (c *fakeController) ReloadConfig() (ConfigReloadResponse, error) {
+	c.actions = append(c.actions, "reload")
+	return ConfigReloadResponse{Applied: []string{"log.level"}, RestartRequired: []string{"license_key"}}, nil
+}
+
+func (c *fakeController) SetLogLevel(level string, duration time.Duration) error {
+	if level == "wrong" {
+		return fmt.Errorf("%w: invalid level", ErrBadRequest)
+	}
+	c.actions = append(c.actions, fmt.Sprintf("log-level %s %s", level, duration))
+	return nil
+}
+
+func (c *fakeController) action(action, name string) error {
+	if name != "nri-redis" {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	c.actions = append(c.actions, action+" "+name)
+	return nil
+}
+
+func serveControl(t *testing.T, c Controller) (*ControlClient, string) {
+	t.Helper()
+	port, err := networkHelpers.TCPPort()
+	require.NoError(t, err)
+	// unix socket paths have a short length limit, so the test temp dir can't be used
+	dir, err := os.MkdirTemp("", "ctl")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "control.sock")
+
+	s, err := NewServer(&noopReporter{}, &testemit.RecordEmitter{})
+	require.NoError(t, err)
+	s.Status.Enable("localhost", port)
+	if c != nil {
+		s.Control(c, socket)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go s.Serve(ctx)
+	s.waitUntilReady()
+	if c != nil {
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond, "control socket not created")
+	}
+
+	return NewControlClient(port, socket), socket
+}
+
+func TestControl(t *testing.T) {
+	c := &fakeController{}
+	client, _ := serveControl(t, c)
+
+	integrations, err := client.Integrations()
+	require.NoError(t, err)
+	assert.Equal(t, []status.IntegrationReport{{Name: "nri-redis", ConfigFile: "/etc/redis.yml"}}, integrations)
+
+	require.NoError(t, client.RunIntegration("nri-redis"))
+	require.NoError(t, client.StopIntegration("nri-redis"))
+	require.NoError(t, client.RestartIntegration("nri-redis"))
+	reloaded, err := client.ReloadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, ConfigReloadResponse{Applied: []string{"log.level"}, RestartRequired: []string{"license_key"}}, reloaded)
+	require.NoError(t, client.SetLogLevel("debug", 10*time.Minute))
+
+	assert.Equal(t, []string{
+		"run nri-redis",
+		"stop nri-redis",
+		"restart nri-redis",
+		"reload",
+		"log-level debug 10m0s",
+	}, c.actions)
+
+	_, err = client.Status()
+	assert.NoError(t, err)
+}
+
+func TestControl_Errors(t *testing.T) {
+	client, _ := serveControl(t, &fakeController{})
+
+	err := client.StopIntegration("unknown")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+	assert.Contains(t, err.Error(), "unknown")
+
+	err = client.SetLogLevel("wrong", time.Minute)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+
+	err = client.SetLogLevel("debug", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid duration")
+}
+
+func TestControl_SocketPermissions(t *testing.T) {
+	_, socket := serveControl(t, &fakeController{})
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(controlSocketMode), info.Mode().Perm())
+}
+
+func TestControl_Disabled(t *testing.T) {
+	client, socket := serveControl(t, nil)
+
+	_, err := client.Integrations()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "control_server_enabled")
+	assert.NoFileExists(t, socket)
+
+	_, err = client.Status()
+	assert.NoError(t, err)
+}
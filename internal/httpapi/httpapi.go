@@ -32,6 +32,8 @@ const (
 	statusAPIPathReady         = "/v1/status/ready"
 	ingestAPIPath              = "/v1/data"
 	ingestAPIPathReady         = "/v1/data/ready"
+	ingestPrometheusAPIPath    = "/v1/data/prometheus"
+	prometheusEntityParam      = "entity"
 	readinessProbeRetryBackoff = 100 * time.Millisecond
 )
 
@@ -54,6 +56,12 @@ type Server struct {
 	statusReadyCh chan struct{}
 	ingestReadyCh chan struct{}
 	timeout       time.Duration
+	// promEntity is the entity name for Prometheus payloads that don't set the entity parameter
+	promEntity string
+	// controller handles the control API requests, if set
+	controller Controller
+	// controlSocket is the path of the unix socket serving the control API
+	controlSocket string
 }
 
 // ComponentConfig stores configuration for a server component.
@@ -91,6 +99,12 @@ func (sc *ComponentConfig) VerifyTLSClient(caCertPath string) {
 	sc.tls.caPath = caCertPath
 }
 
+// PrometheusEntity sets the entity name Prometheus payloads are reported for, unless they set the
+// "entity" query parameter. If empty, Prometheus metrics are attached to the agent entity.
+func (s *Server) PrometheusEntity(name string) {
+	s.promEntity = name
+}
+
 // NewServer creates a new API server.
 // Nice2Have: decouple services into path handlers.
 // Separate HTTP API configs should be deprecated if we want to unify under a single server & port.
@@ -114,10 +128,18 @@ func NewServer(r status.Reporter, em emitter.Emitter) (*Server, error) {
 // Serve serves status API requests and ingest.
 // Nice2Have: context cancellation.
 func (s *Server) Serve(ctx context.Context) {
-	if !s.Status.enabled && !s.Ingest.enabled {
+	if !s.Status.enabled && !s.Ingest.enabled && s.controller == nil {
 		return
 	}
 
+	if s.controller != nil {
+		go func() {
+			if err := s.serveControl(ctx); err != nil {
+				s.logger.WithError(err).Error("error serving agent control")
+			}
+		}()
+	}
+
 	var serversWg sync.WaitGroup
 	var statusErr, ingestErr error
 
@@ -203,6 +225,7 @@ func (s *Server) serveIngest(_ context.Context) error {
 		router := httprouter.New()
 		router.GET(ingestAPIPathReady, s.handleReady)
 		router.POST(ingestAPIPath, s.handleIngest)
+		router.POST(ingestPrometheusAPIPath, s.handleIngestPrometheus)
 
 		server := &http.Server{
 			Handler: router,
@@ -407,3 +430,42 @@ func (s *Server) handleIngest(w http.ResponseWriter, r *http.Request, ps httprou
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// handleIngestPrometheus converts a Prometheus text exposition format payload into an integration
+// protocol v4 payload and emits it.
+func (s *Server) handleIngestPrometheus(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	entityName := s.promEntity
+	if e := r.URL.Query().Get(prometheusEntityParam); e != "" {
+		entityName = e
+	}
+
+	payload, err := prometheusToV4(r.Body, entityName, time.Now())
+	if err != nil {
+		errMsg := "cannot read Prometheus payload"
+		s.logger.WithError(err).Warn(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		jerr := json.NewEncoder(w).Encode(responseError{
+			Error: fmt.Sprintf("%s: %s", errMsg, err.Error()),
+		})
+		if jerr != nil {
+			s.logger.WithError(jerr).Warn("couldn't encode a failed response")
+		}
+		return
+	}
+
+	err = s.emitter.Emit(s.definition, nil, nil, payload)
+	if err != nil {
+		errMsg := "cannot emit Prometheus payload"
+		s.logger.WithError(err).Warn(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err = w.Write([]byte(fmt.Sprintf("%s, err: %s", errMsg, err.Error())))
+		if err != nil {
+			s.logger.WithError(err).Warn("cannot write HTTP response body")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -253,6 +253,47 @@ func (suite *HTTPAPITestSuite) TestServe_IngestData() {
 }
 
 // nolint:funlen,cyclop
+func (suite *HTTPAPITestSuite) TestServe_IngestPrometheus() {
+	port, err := networkHelpers.TCPPort()
+	require.NoError(suite.T(), err)
+
+	em := &testemit.RecordEmitter{}
+	s, err := NewServer(&noopReporter{}, em)
+	require.NoError(suite.T(), err)
+	s.Ingest.Enable("localhost", port)
+	s.PrometheusEntity("default-entity")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Serve(ctx)
+	s.waitUntilReady()
+
+	post := func(query string, body string) int {
+		url := fmt.Sprintf("http://localhost:%d%s%s", port, ingestPrometheusAPIPath, query)
+		resp, err := http.Post(url, "text/plain", bytes.NewReader([]byte(body)))
+		require.NoError(suite.T(), err)
+		require.NoError(suite.T(), resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	// configured entity name
+	assert.Equal(suite.T(), http.StatusNoContent, post("", "jobs_running 3\n"))
+	d, err := em.ReceiveFrom(IntegrationName)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "default-entity", d.DataSet.PluginDataSet.Entity.Name)
+	assert.Len(suite.T(), d.DataSet.PluginDataSet.Metrics, 1)
+
+	// entity name overridden by the request
+	assert.Equal(suite.T(), http.StatusNoContent, post("?entity=batch-job", "jobs_running 3\n"))
+	d, err = em.ReceiveFrom(IntegrationName)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "batch-job", d.DataSet.PluginDataSet.Entity.Name)
+
+	// invalid payload
+	assert.Equal(suite.T(), http.StatusBadRequest, post("", "jobs_running{ 3\n"))
+}
+
 func (suite *HTTPAPITestSuite) TestServe_IngestData_mTLS() {
 	cases := []struct {
 		name           string
@@ -0,0 +1,163 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/pkg/entity"
+	"github.com/newrelic/infrastructure-agent/pkg/integrations/v4/protocol"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	prometheusIntegrationName = "com.newrelic.prometheus-ingest"
+	prometheusEntityType      = "PROMETHEUS_PUSH"
+	// cumulative counters are converted into deltas by the dimensional metrics sender
+	metricTypeCumulativeCount protocol.MetricType = "cumulative-count"
+)
+
+// the protocol histogram and summary values can't be built outside their package,
+// so these types replicate their JSON representation.
+type promHistogramValue struct {
+	SampleCount uint64       `json:"sample_count"`
+	SampleSum   float64      `json:"sample_sum"`
+	Buckets     []promBucket `json:"buckets,omitempty"`
+}
+
+type promBucket struct {
+	CumulativeCount float64 `json:"cumulative_count"`
+	UpperBound      float64 `json:"upper_bound"`
+}
+
+type promSummaryValue struct {
+	SampleCount float64        `json:"sample_count"`
+	SampleSum   float64        `json:"sample_sum"`
+	Quantiles   []promQuantile `json:"quantiles,omitempty"`
+}
+
+type promQuantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// prometheusToV4 converts a Prometheus text exposition format payload into an integration
+// protocol v4 payload. Metrics are attached to the agent entity unless an entity name is provided.
+// Samples without timestamp are reported at the given time.
+func prometheusToV4(r io.Reader, entityName string, now time.Time) ([]byte, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse prometheus payload: %w", err)
+	}
+
+	ds := protocol.Dataset{}
+	if entityName != "" {
+		ds.Entity = entity.Fields{Name: entityName, Type: prometheusEntityType}
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		family := families[name]
+		for _, m := range family.Metric {
+			metric, ok, err := convertPrometheusMetric(name, family.GetType(), m, now)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				ds.Metrics = append(ds.Metrics, metric)
+			}
+		}
+	}
+
+	payload := protocol.DataV4{
+		PluginProtocolVersion: protocol.PluginProtocolVersion{RawProtocolVersion: "4"},
+		Integration:           protocol.IntegrationMetadata{Name: prometheusIntegrationName},
+		DataSets:              []protocol.Dataset{ds},
+	}
+	return json.Marshal(payload)
+}
+
+// convertPrometheusMetric returns the protocol metric for a Prometheus sample, or false if the sample
+// can't be represented (e.g. NaN or infinite values).
+func convertPrometheusMetric(name string, mType dto.MetricType, m *dto.Metric, now time.Time) (protocol.Metric, bool, error) {
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+	if m.TimestampMs != nil {
+		timestamp = m.GetTimestampMs()
+	}
+	metric := protocol.Metric{
+		Name:       name,
+		Timestamp:  &timestamp,
+		Attributes: make(map[string]interface{}, len(m.Label)),
+	}
+	for _, l := range m.Label {
+		metric.Attributes[l.GetName()] = l.GetValue()
+	}
+
+	var value interface{}
+	switch mType {
+	case dto.MetricType_COUNTER:
+		metric.Type = metricTypeCumulativeCount
+		value = m.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		metric.Type = protocol.MetricTypeGauge
+		value = m.GetGauge().GetValue()
+	case dto.MetricType_UNTYPED:
+		metric.Type = protocol.MetricTypeGauge
+		value = m.GetUntyped().GetValue()
+	case dto.MetricType_HISTOGRAM:
+		metric.Type = protocol.MetricTypePrometheusHistogram
+		h := m.GetHistogram()
+		hv := promHistogramValue{SampleCount: h.GetSampleCount(), SampleSum: h.GetSampleSum()}
+		for _, b := range h.Bucket {
+			// the +Inf bucket count is the sample count, and it can't be represented in JSON
+			if math.IsInf(b.GetUpperBound(), 0) {
+				continue
+			}
+			hv.Buckets = append(hv.Buckets, promBucket{
+				CumulativeCount: float64(b.GetCumulativeCount()),
+				UpperBound:      b.GetUpperBound(),
+			})
+		}
+		value = hv
+	case dto.MetricType_SUMMARY:
+		metric.Type = protocol.MetricTypePrometheusSummary
+		s := m.GetSummary()
+		sv := promSummaryValue{SampleCount: float64(s.GetSampleCount()), SampleSum: s.GetSampleSum()}
+		for _, q := range s.Quantile {
+			if !isFinite(q.GetValue()) {
+				continue
+			}
+			sv.Quantiles = append(sv.Quantiles, promQuantile{Quantile: q.GetQuantile(), Value: q.GetValue()})
+		}
+		value = sv
+	default:
+		return metric, false, nil
+	}
+
+	if f, ok := value.(float64); ok && !isFinite(f) {
+		return metric, false, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return metric, false, fmt.Errorf("cannot encode value for metric %s: %w", name, err)
+	}
+	metric.Value = raw
+	return metric, true, nil
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package httpapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/pkg/integrations/v4/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const promPayload = `# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{method="get",code="200"} 1027 1395066363000
+# TYPE queue_size gauge
+queue_size 12.5
+# TYPE temperature gauge
+temperature NaN
+untyped_metric 7
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 3
+request_duration_seconds_bucket{le="0.5"} 8
+request_duration_seconds_bucket{le="+Inf"} 10
+request_duration_seconds_sum 4.2
+request_duration_seconds_count 10
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds{quantile="0.99"} 0.2
+rpc_duration_seconds_sum 17.5
+rpc_duration_seconds_count 200
+`
+
+func TestPrometheusToV4(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	raw, err := prometheusToV4(strings.NewReader(promPayload), "my-entity", now)
+	require.NoError(t, err)
+
+	version, err := protocol.VersionFromPayload(raw, true)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.V4, version)
+
+	var data protocol.DataV4
+	require.NoError(t, json.Unmarshal(raw, &data))
+	require.Len(t, data.DataSets, 1)
+	ds := data.DataSets[0]
+	assert.Equal(t, "my-entity", ds.Entity.Name)
+	assert.NotEmpty(t, ds.Entity.Type)
+
+	metrics := map[string]protocol.Metric{}
+	for _, m := range ds.Metrics {
+		metrics[m.Name] = m
+	}
+	// NaN samples are discarded
+	assert.Len(t, metrics, 5)
+	assert.NotContains(t, metrics, "temperature")
+
+	counter := metrics["http_requests_total"]
+	assert.Equal(t, metricTypeCumulativeCount, counter.Type)
+	assert.Equal(t, int64(1395066363000), *counter.Timestamp)
+	assert.Equal(t, map[string]interface{}{"method": "get", "code": "200"}, counter.Attributes)
+	v, err := counter.NumericValue()
+	require.NoError(t, err)
+	assert.Equal(t, 1027.0, v)
+
+	gauge := metrics["queue_size"]
+	assert.Equal(t, protocol.MetricTypeGauge, gauge.Type)
+	assert.Equal(t, now.UnixNano()/int64(time.Millisecond), *gauge.Timestamp)
+
+	assert.Equal(t, protocol.MetricTypeGauge, metrics["untyped_metric"].Type)
+
+	histogramMetric := metrics["request_duration_seconds"]
+	histogram, err := histogramMetric.GetPrometheusHistogramValue()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), *histogram.SampleCount)
+	assert.Equal(t, 4.2, *histogram.SampleSum)
+	// +Inf bucket is not reported
+	require.Len(t, histogram.Buckets, 2)
+	assert.Equal(t, 0.5, *histogram.Buckets[1].UpperBound)
+	assert.Equal(t, 8.0, *histogram.Buckets[1].CumulativeCount)
+
+	summaryMetric := metrics["rpc_duration_seconds"]
+	summary, err := summaryMetric.GetPrometheusSummaryValue()
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, summary.SampleCount)
+	assert.Equal(t, 17.5, summary.SampleSum)
+	require.Len(t, summary.Quantiles, 2)
+	assert.Equal(t, 0.99, summary.Quantiles[1].Quantile)
+	assert.Equal(t, 0.2, summary.Quantiles[1].Value)
+}
+
+func TestPrometheusToV4_AgentEntity(t *testing.T) {
+	raw, err := prometheusToV4(strings.NewReader("up 1\n"), "", time.Now())
+	require.NoError(t, err)
+
+	var data protocol.DataV4
+	require.NoError(t, json.Unmarshal(raw, &data))
+	assert.True(t, data.DataSets[0].Entity.IsAgent())
+}
+
+func TestPrometheusToV4_InvalidPayload(t *testing.T) {
+	_, err := prometheusToV4(strings.NewReader("metric{label=\"unclosed 1\n"), "", time.Now())
+	assert.Error(t, err)
+}
@@ -69,6 +69,11 @@ func newDefinitionWithoutLookup(ce config2.ConfigEntry, passthroughEnv []string,
 	// Reading this env the integration can know configured interval.
 	ce.Env[intervalEnvVarName] = fmt.Sprintf("%v", interval)
 
+	whenConditions, err := conditions(ce.When)
+	if err != nil {
+		return Definition{}, fmt.Errorf("error parsing 'when' YAML property: %s", err)
+	}
+
 	d := Definition{
 		ExecutorConfig: executor.Config{
 			User:            ce.User,
@@ -82,7 +87,7 @@ func newDefinitionWithoutLookup(ce config2.ConfigEntry, passthroughEnv []string,
 		Name:           ce.InstanceName,
 		Interval:       interval,
 		LogsQueueSize:  ce.LogsQueueSize,
-		WhenConditions: conditions(ce.When),
+		WhenConditions: whenConditions,
 		ConfigTemplate: configTemplate,
 		newTempFile:    newTempFile,
 	}
@@ -91,7 +96,6 @@ func newDefinitionWithoutLookup(ce config2.ConfigEntry, passthroughEnv []string,
 		// Set to empty as currently Inventory source unknown
 		d.InventorySource = ids.EmptyInventorySource
 	} else {
-		var err error
 		d.InventorySource, err = ids.FromString(ce.InventorySource)
 		if err != nil {
 			return Definition{}, errors.New("Error parsing 'inventory_source' YAML property: " + err.Error())
@@ -250,7 +254,7 @@ func getInterval(duration string) time.Duration {
 }
 
 // get condition functions from the YAML 'when:' section
-func conditions(enabling config2.EnableConditions) []when.Condition {
+func conditions(enabling config2.EnableConditions) ([]when.Condition, error) {
 	var conds []when.Condition
 
 	// We do not consider here FeatureFlag as it is managed at the integrations manager
@@ -261,7 +265,79 @@ func conditions(enabling config2.EnableConditions) []when.Condition {
 	if len(enabling.EnvExists) > 0 {
 		conds = append(conds, when.EnvExists(enabling.EnvExists))
 	}
-	return conds
+
+	if enabling.ProcessRunning != "" {
+		match, err := when.Matcher(enabling.ProcessRunning)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'process_running' condition: %s", err)
+		}
+		conds = append(conds, when.ProcessRunning(match))
+	}
+
+	if enabling.PortListening != "" {
+		conds = append(conds, when.PortListening(enabling.PortListening))
+	}
+
+	if enabling.Command != nil {
+		if len(enabling.Command.Exec) == 0 {
+			return nil, errors.New("'command' condition requires a non-empty 'exec' field")
+		}
+		var timeout time.Duration
+		if enabling.Command.Timeout != nil {
+			timeout = *enabling.Command.Timeout
+		}
+		conds = append(conds, when.CommandExitCode(enabling.Command.Exec, enabling.Command.ExitCode, timeout))
+	}
+
+	if enabling.OS != "" {
+		conds = append(conds, when.OS(enabling.OS))
+	}
+
+	if enabling.Distro != "" {
+		match, err := when.Matcher(enabling.Distro)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'distro' condition: %s", err)
+		}
+		conds = append(conds, when.Distro(match))
+	}
+
+	for _, nested := range enabling.All {
+		nestedConds, err := nestedConditions(nested)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, when.And(nestedConds...))
+	}
+
+	if len(enabling.Any) > 0 {
+		var anyConds []when.Condition
+		for _, nested := range enabling.Any {
+			nestedConds, err := nestedConditions(nested)
+			if err != nil {
+				return nil, err
+			}
+			anyConds = append(anyConds, when.And(nestedConds...))
+		}
+		conds = append(conds, when.Or(anyConds...))
+	}
+
+	if enabling.Not != nil {
+		nestedConds, err := nestedConditions(*enabling.Not)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, when.Not(when.And(nestedConds...)))
+	}
+
+	return conds, nil
+}
+
+// nestedConditions returns the condition functions of a nested 'all', 'any' or 'not' section.
+func nestedConditions(enabling config2.EnableConditions) ([]when.Condition, error) {
+	if enabling.Feature != "" {
+		return nil, errors.New("'feature' condition is only allowed at the top level of the 'when' section")
+	}
+	return conditions(enabling)
 }
 
 // ErrLookup is a test helper that returns errors.
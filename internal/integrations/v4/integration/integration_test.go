@@ -248,3 +248,47 @@ func TestDefinition_fromName(t *testing.T) {
 	assert.Equal(t, "/path/to/nri-foo", d.runnable.Command)
 	assert.Equal(t, []string{"arg1", "arg2"}, d.runnable.Args)
 }
+
+func TestNewDefinition_WhenConditions(t *testing.T) {
+	// GIVEN a configuration with nested conditions
+	cfg := `
+name: conditional
+exec: /bin/true
+when:
+  env_exists:
+    COND_TEST_ENV: enabled
+  any:
+    - os: plan9
+    - not:
+        file_exists: /non/existing/file
+`
+	var ce config2.ConfigEntry
+	require.NoError(t, yaml.Unmarshal([]byte(cfg), &ce))
+
+	def, err := NewDefinition(ce, ErrLookup, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, def.WhenConditions, 2)
+
+	// THEN conditions are evaluated each time they are invoked
+	assert.False(t, def.WhenConditions[0]())
+	t.Setenv("COND_TEST_ENV", "enabled")
+	assert.True(t, def.WhenConditions[0]())
+	assert.True(t, def.WhenConditions[1]())
+}
+
+func TestNewDefinition_InvalidWhenConditions(t *testing.T) {
+	cases := map[string]string{
+		"invalid regex":  `process_running: regex "(unclosed"`,
+		"empty command":  `command: {exit_code: 0}`,
+		"nested feature": `any: [{feature: docker_enabled}]`,
+	}
+	for name, when := range cases {
+		t.Run(name, func(t *testing.T) {
+			var ce config2.ConfigEntry
+			require.NoError(t, yaml.Unmarshal([]byte("name: conditional\nexec: /bin/true\nwhen:\n  "+when), &ce))
+
+			_, err := NewDefinition(ce, ErrLookup, nil, nil)
+			assert.Error(t, err)
+		})
+	}
+}
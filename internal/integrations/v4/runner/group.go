@@ -32,6 +32,16 @@ type Group struct {
 	configHandle         configrequest.HandleFn
 	terminateDefinitionQ chan string
 	idLookup             host.IDLookup
+	// ctx is the context passed to Run, used to start the integrations again on restart
+	ctx context.Context
+	// runners started by Run, kept to report their status and to stop them individually
+	runners []groupRunner
+}
+
+// groupRunner pairs a runner with the function cancelling its execution.
+type groupRunner struct {
+	*runner
+	cancel context.CancelFunc
 }
 
 type runnerErrorHandler func(ctx context.Context, errs <-chan error)
@@ -64,14 +74,82 @@ func NewGroup(
 // Run launches all the integrations to run in background. They can be cancelled with the
 // provided context
 func (g *Group) Run(ctx context.Context) (hasStartedAnyOHI bool) {
+	g.ctx = ctx
 	for _, integr := range g.integrations {
-		go NewRunner(integr, g.emitter, g.dSources, g.handleErrorsProvide, g.cmdReqHandle, g.configHandle, g.terminateDefinitionQ, g.idLookup).Run(ctx, nil, nil)
+		g.start(integr)
 		hasStartedAnyOHI = true
 	}
 
 	return
 }
 
+func (g *Group) start(definition integration.Definition) {
+	ctx, cancel := context.WithCancel(g.ctx)
+	r := NewRunner(definition, g.emitter, g.dSources, g.handleErrorsProvide, g.cmdReqHandle, g.configHandle, g.terminateDefinitionQ, g.idLookup)
+	g.runners = append(g.runners, groupRunner{runner: r, cancel: cancel})
+	go r.Run(ctx, nil, nil)
+}
+
+// Status returns the status of the integrations started by Run.
+func (g *Group) Status() []RunStatus {
+	statuses := make([]RunStatus, 0, len(g.runners))
+	for _, r := range g.runners {
+		st := r.Status()
+		if st.Name == "" {
+			// not executed yet
+			st.Name = r.definition.Name
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// Stop stops the running integrations with the passed name, returning false if there is none.
+func (g *Group) Stop(name string) (found bool) {
+	running := g.runners[:0]
+	for _, r := range g.runners {
+		if r.definition.Name == name {
+			r.cancel()
+			found = true
+		} else {
+			running = append(running, r)
+		}
+	}
+	g.runners = running
+	return
+}
+
+// Restart stops the integrations with the passed name, if running, and starts them again. It
+// returns false if the group doesn't have any integration with such name or it hasn't been started.
+func (g *Group) Restart(name string) (found bool) {
+	if g.ctx == nil {
+		return false
+	}
+	g.Stop(name)
+	for _, integr := range g.integrations {
+		if integr.Name == name {
+			g.start(integr)
+			found = true
+		}
+	}
+	return
+}
+
+// RunOnceNamed executes in background, just one time, the integrations with the passed name.
+// It returns false if the group doesn't have any integration with such name.
+func (g *Group) RunOnceNamed(ctx context.Context, name string) (found bool) {
+	for _, integrationDef := range g.integrations {
+		if integrationDef.Name != name {
+			continue
+		}
+		integrationDef.Interval = 0
+		r := NewRunner(integrationDef, g.emitter, g.dSources, g.handleErrorsProvide, g.cmdReqHandle, g.configHandle, g.terminateDefinitionQ, g.idLookup)
+		go r.Run(ctx, nil, nil)
+		found = true
+	}
+	return
+}
+
 // RunOnce will execute the group of integrations just one time.
 func (g *Group) RunOnce(ctx context.Context) {
 
@@ -354,6 +354,127 @@ func TestGroup_Run_IntegrationScriptPrintsErrorsAndReturnCodeIsZero(t *testing.T
 	})
 }
 
+func TestGroup_Status(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// GIVEN a grouprunner with an integration that succeeds and another one that fails
+	te := &testemit.RecordEmitter{}
+	loader := NewLoadFn(config2.YAML{
+		Integrations: []config2.ConfigEntry{
+			{InstanceName: "sayhello", Exec: testhelp.Command(fixtures.IntegrationScript, "hello"), Interval: "15s"},
+			{InstanceName: "failing", Exec: testhelp.Command(fixtures.ErrorCmd), Interval: "15s"},
+		},
+	}, nil)
+	gr, _, err := NewGroup(loader, integration.InstancesLookup{}, nil, te, cmdrequest.NoopHandleFn, configrequest.NoopHandleFn, "", terminatedQueue, host.IDLookup{})
+	require.NoError(t, err)
+
+	// WHEN the Group executes all the integrations
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	before := time.Now()
+	_ = gr.Run(ctx)
+
+	// THEN the status eventually reports the result of their first execution
+	var statuses []RunStatus
+	require.Eventually(t, func() bool {
+		statuses = gr.Status()
+		for _, st := range statuses {
+			if st.LastExitCode == nil || st.NextRun.IsZero() {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond, "integrations not executed yet")
+
+	require.Len(t, statuses, 2)
+	for _, st := range statuses {
+		assert.False(t, st.Running)
+		assert.False(t, st.LastRun.Before(before))
+		assert.WithinDuration(t, st.LastRun.Add(15*time.Second), st.NextRun, time.Second)
+		switch st.Name {
+		case "sayhello":
+			assert.Equal(t, 0, *st.LastExitCode)
+			assert.Empty(t, st.LastError)
+		case "failing":
+			assert.Equal(t, 3, *st.LastExitCode)
+			assert.Contains(t, st.LastError, "exit status 3")
+			assert.Contains(t, st.StderrTail, "very bad error")
+		default:
+			assert.Failf(t, "unexpected integration", st.Name)
+		}
+	}
+}
+
+func TestGroup_StopRestart(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// GIVEN a running group with two integrations
+	te := &testemit.RecordEmitter{}
+	loader := NewLoadFn(config2.YAML{
+		Integrations: []config2.ConfigEntry{
+			{InstanceName: "sayhello", Exec: testhelp.Command(fixtures.IntegrationScript, "hello"), Interval: "15s"},
+			{InstanceName: "saygoodbye", Exec: testhelp.Command(fixtures.IntegrationScript, "bye"), Interval: "15s"},
+		},
+	}, nil)
+	gr, _, err := NewGroup(loader, integration.InstancesLookup{}, nil, te, cmdrequest.NoopHandleFn, configrequest.NoopHandleFn, "", terminatedQueue, host.IDLookup{})
+	require.NoError(t, err)
+
+	// restart isn't possible until the group runs
+	assert.False(t, gr.Restart("sayhello"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_ = gr.Run(ctx)
+	_, err = te.ReceiveFrom("sayhello")
+	require.NoError(t, err)
+	_, err = te.ReceiveFrom("saygoodbye")
+	require.NoError(t, err)
+
+	// WHEN an integration is stopped
+	assert.True(t, gr.Stop("sayhello"))
+	assert.False(t, gr.Stop("unknown"))
+
+	// THEN it's no longer reported
+	statuses := gr.Status()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "saygoodbye", statuses[0].Name)
+
+	// WHEN it's restarted
+	assert.True(t, gr.Restart("sayhello"))
+	assert.False(t, gr.Restart("unknown"))
+
+	// THEN it runs again
+	_, err = te.ReceiveFrom("sayhello")
+	require.NoError(t, err)
+	assert.Len(t, gr.Status(), 2)
+}
+
+func TestGroup_RunOnceNamed(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// GIVEN a group that hasn't been started
+	te := &testemit.RecordEmitter{}
+	loader := NewLoadFn(config2.YAML{
+		Integrations: []config2.ConfigEntry{
+			{InstanceName: "sayhello", Exec: testhelp.Command(fixtures.IntegrationScript, "hello")},
+		},
+	}, nil)
+	gr, _, err := NewGroup(loader, integration.InstancesLookup{}, nil, te, cmdrequest.NoopHandleFn, configrequest.NoopHandleFn, "", terminatedQueue, host.IDLookup{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// WHEN one of its integrations is run once
+	assert.False(t, gr.RunOnceNamed(ctx, "unknown"))
+	assert.True(t, gr.RunOnceNamed(ctx, "sayhello"))
+
+	// THEN it's executed
+	dataset, err := te.ReceiveFrom("sayhello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", dataset.DataSet.Metrics[0]["value"])
+}
+
 func Test_parseLogrusFields(t *testing.T) {
 	tests := map[string]struct {
 		input string
@@ -32,6 +32,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// conditionsRecheckInterval is the maximum time to wait before checking again the 'when'
+// conditions of an integration whose conditions were not met.
+const conditionsRecheckInterval = 15 * time.Second
+
 var (
 	illog         = log.WithComponent("integrations.runner.Runner")
 	heartBeatJSON = []byte("{}")
@@ -64,6 +68,7 @@ type runner struct {
 	cache          cache.Cache
 	terminateQueue chan<- string
 	idLookup       host.IDLookup
+	status         runStatus
 }
 
 // NewRunner creates an integration runner instance.
@@ -104,7 +109,8 @@ func (r *runner) Run(ctx context.Context, pidWCh, exitCodeCh chan<- int) {
 	r.log = illog.WithFields(LogFields(r.definition))
 	defer r.killChildren()
 	for {
-		waitForNextExecution := time.After(r.definition.Interval)
+		started := time.Now()
+		interval := r.definition.Interval
 
 		// only cmd-channel run-requests require exit-code, and they only trigger a single instance
 		//var exitCodeCh chan int
@@ -112,6 +118,7 @@ func (r *runner) Run(ctx context.Context, pidWCh, exitCodeCh chan<- int) {
 		//	exitCodeCh = make(chan int, 1)
 		//}
 
+		conditionsMet := true
 		discovery, info, err := r.applyDiscovery()
 		if err != nil {
 			r.log.
@@ -122,19 +129,26 @@ func (r *runner) Run(ctx context.Context, pidWCh, exitCodeCh chan<- int) {
 				r.execute(ctx, discovery, info, pidWCh, exitCodeCh)
 			} else {
 				r.log.Debug("Integration conditions where not met, skipping execution")
+				conditionsMet = false
+				// conditions are checked again earlier, so the integration starts as soon as they are met
+				if interval == 0 || interval > conditionsRecheckInterval {
+					interval = conditionsRecheckInterval
+				}
 			}
 		}
 
-		if r.definition.SingleRun() {
+		// single run integrations wait for their conditions to be met before finishing
+		if r.definition.SingleRun() && conditionsMet {
 			r.log.Debug("Integration single run finished")
 			return
 		}
+		r.status.scheduled(started.Add(interval))
 
 		select {
 		case <-ctx.Done():
 			r.log.Debug("Integration has been interrupted")
 			return
-		case <-waitForNextExecution:
+		case <-time.After(interval - time.Since(started)):
 		}
 	}
 }
@@ -227,6 +241,9 @@ func (r *runner) execute(ctx context.Context, matches *databind.Values, discover
 	defer txn.End()
 	def := r.definition
 
+	r.status.started(def.Name, time.Now())
+	defer func() { r.status.finished(r.lastStderr.Tail()) }()
+
 	// If timeout configuration is set, wraps current context in a heartbeat-enabled timeout context
 	if def.TimeoutEnabled() {
 		var act contexts.Actuator
@@ -250,6 +267,7 @@ func (r *runner) execute(ctx context.Context, matches *databind.Values, discover
 	if err != nil {
 		txn.NoticeError(err)
 		r.log.WithError(err).Error("can't start integration")
+		r.status.failure(err, "")
 		return
 	}
 
@@ -271,7 +289,7 @@ func (r *runner) execute(ctx context.Context, matches *databind.Values, discover
 
 		go func(txn instrumentation.Transaction) {
 			defer wg.Done()
-			r.handleErrors(ctx, o.Receive.Errors)
+			r.handleErrors(ctx, r.recordErrors(o.Receive.Errors))
 
 		}(txn)
 	}
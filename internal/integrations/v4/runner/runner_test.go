@@ -18,6 +18,7 @@ import (
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/integration"
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/testhelp"
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/testhelp/testemit"
+	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/when"
 	"github.com/newrelic/infrastructure-agent/pkg/integrations/cmdrequest"
 	"github.com/newrelic/infrastructure-agent/pkg/integrations/configrequest"
 	"github.com/newrelic/infrastructure-agent/pkg/integrations/configrequest/protocol"
@@ -70,6 +71,30 @@ func Test_runner_Run(t *testing.T) {
 	assert.Empty(t, dataset.Metadata.Labels)
 }
 
+func Test_runner_Run_singleRunWaitsForConditions(t *testing.T) {
+	// GIVEN a long-running integration whose conditions are not met
+	def, err := integration.NewDefinition(config.ConfigEntry{
+		InstanceName: "foo",
+		Exec:         testhelp.Command(fixtures.IntegrationScript, "bar"),
+		Interval:     "0",
+	}, integration.ErrLookup, nil, nil)
+	require.NoError(t, err)
+	require.True(t, def.SingleRun())
+	def.WhenConditions = []when.Condition{func() bool { return false }}
+
+	e := &testemit.RecordEmitter{}
+	r := NewRunner(def, e, nil, nil, cmdrequest.NoopHandleFn, configrequest.NoopHandleFn, nil, host.IDLookup{})
+
+	// WHEN the runner runs
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	r.Run(ctx, nil, nil)
+
+	// THEN it keeps waiting for the conditions until it's interrupted, without executing the integration
+	assert.Error(t, ctx.Err())
+	assert.NoError(t, e.ExpectTimeout("foo", 10*time.Millisecond))
+}
+
 func Test_runner_Run_noHandleForCfgProtocol(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip()
@@ -0,0 +1,117 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package runner
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/gobackfill"
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+)
+
+// unknownExitCode is reported when an integration failed without providing an exit code
+// (e.g. the executable can't be started).
+const unknownExitCode = -2
+
+// RunStatus holds the state of the last execution of an integration runner.
+type RunStatus struct {
+	Name string
+	// Running is true while an execution is in progress.
+	Running bool
+	// LastRun is the start time of the last execution. Zero if it hasn't been executed yet.
+	LastRun time.Time
+	// NextRun is the expected start time of the next execution. Zero for single-run integrations.
+	NextRun time.Time
+	// LastExitCode is nil until the first execution finishes.
+	LastExitCode *int
+	LastError    string
+	// StderrTail contains the last standard error lines of the last execution.
+	StderrTail string
+}
+
+// runStatus stores the RunStatus of a runner, allowing concurrent access.
+type runStatus struct {
+	mutex  sync.RWMutex
+	status RunStatus
+	failed bool // whether the current execution reported an error
+}
+
+func (rs *runStatus) get() RunStatus {
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+	return rs.status
+}
+
+func (rs *runStatus) started(name string, now time.Time) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.status.Name = name
+	rs.status.Running = true
+	rs.status.LastRun = now
+	rs.failed = false
+}
+
+func (rs *runStatus) scheduled(next time.Time) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.status.NextRun = next
+}
+
+func (rs *runStatus) failure(err error, stderrTail string) {
+	exitCode := unknownExitCode
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = gobackfill.ExitCode(exitErr)
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.failed = true
+	rs.status.LastExitCode = &exitCode
+	rs.status.LastError = helpers.ObfuscateSensitiveDataFromError(err).Error()
+	rs.status.StderrTail = stderrTailStatus(stderrTail)
+}
+
+func (rs *runStatus) finished(stderrTail string) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.status.Running = false
+	if rs.failed {
+		return
+	}
+	exitCode := 0
+	rs.status.LastExitCode = &exitCode
+	rs.status.LastError = ""
+	rs.status.StderrTail = stderrTailStatus(stderrTail)
+}
+
+func stderrTailStatus(tail string) string {
+	if tail == noStderrOutput {
+		return ""
+	}
+	return helpers.ObfuscateSensitiveDataFromString(tail)
+}
+
+// Status returns the state of the last execution of the runner.
+func (r *runner) Status() RunStatus {
+	return r.status.get()
+}
+
+// recordErrors forwards the errors from the passed channel to the returned one, recording them
+// in the runner status. The returned channel has the same capacity as the passed one, and it's
+// closed when the passed one is closed. Errors are forwarded even if the context is cancelled,
+// e.g. because of a timeout, so the handler receives them as if it was reading the passed channel.
+func (r *runner) recordErrors(errs <-chan error) <-chan error {
+	fwd := make(chan error, cap(errs))
+	go func() {
+		defer close(fwd)
+		for err := range errs {
+			r.status.failure(err, r.lastStderr.Tail())
+			fwd <- err
+		}
+	}()
+	return fwd
+}
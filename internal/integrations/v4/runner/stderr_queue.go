@@ -12,6 +12,7 @@ import (
 // allows printing the latest error lines if the integration exited prematurely
 const (
 	defaultStderrQueueLen = 10
+	noStderrOutput        = "(no standard error output)"
 )
 
 type stderrQueue struct {
@@ -47,11 +48,25 @@ func (sq *stderrQueue) Add(line []byte) {
 	sq.nextLine++
 }
 
+// Flush returns the queued lines and empties the queue.
 func (sq *stderrQueue) Flush() string {
 	sq.mutex.Lock()
 	defer sq.mutex.Unlock()
+	tail := sq.tail()
+	sq.nextLine = 0
+	return tail
+}
+
+// Tail returns the queued lines without emptying the queue.
+func (sq *stderrQueue) Tail() string {
+	sq.mutex.Lock()
+	defer sq.mutex.Unlock()
+	return sq.tail()
+}
+
+func (sq *stderrQueue) tail() string {
 	if sq.nextLine == 0 || sq.size == 0 {
-		return "(no standard error output)"
+		return noStderrOutput
 	}
 	var start, lines int
 	joint := bytes.Buffer{}
@@ -70,6 +85,5 @@ func (sq *stderrQueue) Flush() string {
 		joint.Write(sq.queue[start])
 		start = (start + 1) % sq.size
 	}
-	sq.nextLine = 0
 	return joint.String()
 }
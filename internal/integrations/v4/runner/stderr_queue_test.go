@@ -62,3 +62,13 @@ func TestQueueSizeConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestQueueTailDoesNotFlush(t *testing.T) {
+	queue := newStderrQueue(3)
+	queue.Add([]byte("log_line:1"))
+	queue.Add([]byte("log_line:2"))
+
+	assert.Equal(t, "log_line:1\nlog_line:2", queue.Tail())
+	assert.Equal(t, "log_line:1\nlog_line:2", queue.Flush())
+	assert.Equal(t, "(no standard error output)", queue.Tail())
+}
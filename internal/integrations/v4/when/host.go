@@ -0,0 +1,164 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package when
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/os/distro"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	regexPrefix = "regex"
+
+	dialTimeout           = time.Second
+	defaultCommandTimeout = 10 * time.Second
+)
+
+var clog = log.WithComponent("integrations.when")
+
+// functions to be replaced for testing purposes
+var (
+	processNames = func() ([]string, error) {
+		procs, err := process.Processes()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(procs))
+		for _, p := range procs {
+			// processes may finish while being listed
+			if name, err := p.Name(); err == nil {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+	distroName = distro.GetDistro
+)
+
+// Matcher returns a function matching a string either literally or, if the expression is
+// prefixed by `regex`, as a regular expression, following the process matchers format
+// (e.g. `regex "^nginx.*"`).
+func Matcher(expr string) (func(string) bool, error) {
+	if strings.HasPrefix(expr, regexPrefix) {
+		pattern := strings.Trim(strings.TrimSpace(strings.TrimPrefix(expr, regexPrefix)), `"`)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	literal := strings.TrimSpace(strings.Trim(expr, `"`))
+	return func(value string) bool { return value == literal }, nil
+}
+
+// ProcessRunning creates a Condition returning true when any running process name matches
+// the passed matcher.
+func ProcessRunning(match func(string) bool) Condition {
+	return func() bool {
+		names, err := processNames()
+		if err != nil {
+			clog.WithError(err).Debug("Can't list running processes.")
+			return false
+		}
+		for _, name := range names {
+			if match(name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PortListening creates a Condition returning true when a TCP connection can be established
+// with the passed address. If only a port is passed, localhost is assumed.
+func PortListening(address string) Condition {
+	if !strings.Contains(address, ":") {
+		address = net.JoinHostPort("localhost", address)
+	}
+	return func() bool {
+		conn, err := net.DialTimeout("tcp", address, dialTimeout)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}
+}
+
+// CommandExitCode creates a Condition returning true when the passed command finishes before
+// the timeout with the expected exit code.
+func CommandExitCode(command []string, exitCode int, timeout time.Duration) Condition {
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	return func() bool {
+		if len(command) == 0 {
+			return false
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		err := exec.CommandContext(ctx, command[0], command[1:]...).Run()
+		if err == nil {
+			return exitCode == 0
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && ctx.Err() == nil {
+			return exitErr.ExitCode() == exitCode
+		}
+		clog.WithError(err).WithField("command", command).Debug("Can't run condition command.")
+		return false
+	}
+}
+
+// OS creates a Condition returning true when the passed OS name matches the agent's runtime OS
+// (e.g. linux, windows, darwin).
+func OS(name string) Condition {
+	return func() bool {
+		return strings.EqualFold(runtime.GOOS, strings.TrimSpace(name))
+	}
+}
+
+// Distro creates a Condition returning true when the host distribution name matches the
+// passed matcher (e.g. `regex "^Ubuntu 2\d"`).
+func Distro(match func(string) bool) Condition {
+	return func() bool {
+		return match(distroName())
+	}
+}
+
+// And creates a Condition returning true if and only if all the passed conditions are true.
+func And(conditions ...Condition) Condition {
+	return func() bool {
+		return All(conditions...)
+	}
+}
+
+// Or creates a Condition returning true if any of the passed conditions is true.
+func Or(conditions ...Condition) Condition {
+	return func() bool {
+		for _, cond := range conditions {
+			if cond() {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not creates a Condition returning the opposite of the passed condition.
+func Not(condition Condition) Condition {
+	return func() bool {
+		return !condition()
+	}
+}
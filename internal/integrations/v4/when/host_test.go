@@ -0,0 +1,102 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package when
+
+import (
+	"net"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	literal, err := Matcher("nginx")
+	require.NoError(t, err)
+	assert.True(t, literal("nginx"))
+	assert.False(t, literal("nginx-worker"))
+
+	regex, err := Matcher(`regex "^nginx.*"`)
+	require.NoError(t, err)
+	assert.True(t, regex("nginx-worker"))
+	assert.False(t, regex("apache"))
+
+	_, err = Matcher(`regex "(unclosed"`)
+	assert.Error(t, err)
+}
+
+func TestProcessRunning(t *testing.T) {
+	defer func(prev func() ([]string, error)) { processNames = prev }(processNames)
+	processNames = func() ([]string, error) {
+		return []string{"systemd", "nginx", "sshd"}, nil
+	}
+
+	match, err := Matcher(`regex "^ngin"`)
+	require.NoError(t, err)
+	assert.True(t, ProcessRunning(match)())
+
+	match, err = Matcher("mysqld")
+	require.NoError(t, err)
+	assert.False(t, ProcessRunning(match)())
+}
+
+func TestPortListening(t *testing.T) {
+	// GIVEN a listening port
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+
+	// THEN the condition is true, both for addresses and for ports
+	assert.True(t, PortListening(l.Addr().String())())
+	assert.True(t, PortListening(strconv.Itoa(port))())
+
+	// AND it becomes false when the port is closed
+	require.NoError(t, l.Close())
+	assert.False(t, PortListening(strconv.Itoa(port))())
+}
+
+func TestCommandExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on sh")
+	}
+	assert.True(t, CommandExitCode([]string{"sh", "-c", "exit 0"}, 0, 0)())
+	assert.True(t, CommandExitCode([]string{"sh", "-c", "exit 3"}, 3, 0)())
+	assert.False(t, CommandExitCode([]string{"sh", "-c", "exit 1"}, 0, 0)())
+	assert.False(t, CommandExitCode([]string{"non-existing-command-for-conditions"}, 0, 0)())
+}
+
+func TestOS(t *testing.T) {
+	assert.True(t, OS(runtime.GOOS)())
+	assert.False(t, OS("plan9-"+runtime.GOOS)())
+}
+
+func TestDistro(t *testing.T) {
+	defer func(prev func() string) { distroName = prev }(distroName)
+	distroName = func() string { return "Ubuntu 22.04.1 LTS" }
+
+	match, err := Matcher(`regex "^Ubuntu 2\d"`)
+	require.NoError(t, err)
+	assert.True(t, Distro(match)())
+
+	match, err = Matcher(`regex "^CentOS"`)
+	require.NoError(t, err)
+	assert.False(t, Distro(match)())
+}
+
+func TestBooleanCombinations(t *testing.T) {
+	trueFunc := func() bool { return true }
+	falseFunc := func() bool { return false }
+
+	assert.True(t, And()())
+	assert.True(t, And(trueFunc, trueFunc)())
+	assert.False(t, And(trueFunc, falseFunc)())
+
+	assert.False(t, Or()())
+	assert.True(t, Or(falseFunc, trueFunc)())
+	assert.False(t, Or(falseFunc, falseFunc)())
+
+	assert.True(t, Not(falseFunc)())
+	assert.False(t, Not(trueFunc)())
+}
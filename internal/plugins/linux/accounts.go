@@ -0,0 +1,381 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/entity"
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+// Password status of the accounts, from the shadow file.
+const (
+	PasswordStatusSet     = "set"
+	PasswordStatusEmpty   = "empty"
+	PasswordStatusLocked  = "locked"
+	PasswordStatusUnknown = "unknown"
+)
+
+var accountslog = log.WithPlugin("Accounts")
+
+// AccountsPlugin reports the local users, groups and sudoers rules of the host. Unlike the UsersPlugin, which
+// reports the logged in users, it describes the configured accounts.
+type AccountsPlugin struct {
+	agent.PluginCommon
+	frequency time.Duration
+}
+
+type AccountUser struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Uid                int    `json:"uid"`
+	Gid                int    `json:"gid"`
+	Home               string `json:"home"`
+	Shell              string `json:"shell"`
+	Groups             string `json:"groups"`
+	PasswordStatus     string `json:"password_status"`
+	PasswordLastChange *int64 `json:"password_last_change_epoch,omitempty"`
+	PasswordMinDays    *int64 `json:"password_min_days,omitempty"`
+	PasswordMaxDays    *int64 `json:"password_max_days,omitempty"`
+	PasswordWarnDays   *int64 `json:"password_warn_days,omitempty"`
+	InactiveDays       *int64 `json:"inactive_days,omitempty"`
+	ExpireEpoch        *int64 `json:"expire_epoch,omitempty"`
+}
+
+func (u AccountUser) SortKey() string {
+	return u.ID
+}
+
+type AccountGroup struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Gid     int    `json:"gid"`
+	Members string `json:"members"`
+}
+
+func (g AccountGroup) SortKey() string {
+	return g.ID
+}
+
+// SudoersRule is a user specification of the sudoers files, identified by the file and the users it applies to.
+type SudoersRule struct {
+	ID   string `json:"id"`
+	File string `json:"file"`
+	Rule string `json:"rule"`
+}
+
+func (r SudoersRule) SortKey() string {
+	return r.ID
+}
+
+// shadowEntry holds the password aging fields of the shadow file, in days since the epoch or days.
+type shadowEntry struct {
+	password   string
+	lastChange *int64
+	minDays    *int64
+	maxDays    *int64
+	warnDays   *int64
+	inactive   *int64
+	expire     *int64
+}
+
+func NewAccountsPlugin(id ids.PluginID, ctx agent.AgentContext) agent.Plugin {
+	cfg := ctx.Config()
+	return &AccountsPlugin{
+		PluginCommon: agent.PluginCommon{ID: id, Context: ctx},
+		frequency: config.ValidateConfigFrequencySetting(
+			cfg.AccountsRefreshSec,
+			config.FREQ_MINIMUM_INVENTORY_SAMPLE_RATE,
+			config.FREQ_PLUGIN_ACCOUNTS_UPDATES,
+			cfg.DisableAllPlugins,
+		) * time.Second,
+	}
+}
+
+// Run is the main processing loop that drives the logic for the plugin
+func (p *AccountsPlugin) Run() {
+	if p.frequency <= config.FREQ_DISABLE_SAMPLING {
+		accountslog.Debug("Disabled.")
+		return
+	}
+
+	refreshTimer := time.NewTicker(p.frequency)
+	for {
+		dataset, err := readAccounts(helpers.HostEtc())
+		if err != nil {
+			accountslog.WithError(err).Error("reading local accounts")
+		} else {
+			p.EmitInventory(dataset, entity.NewFromNameWithoutID(p.Context.EntityKey()))
+		}
+		<-refreshTimer.C
+	}
+}
+
+// readAccounts reads the users and groups of the passwd and group files of an etc directory, with the password
+// aging of the shadow file when it's readable, and the sudoers rules.
+func readAccounts(etcDir string) (dataset agent.PluginInventoryDataset, err error) {
+	passwd, err := readColonFile(filepath.Join(etcDir, "passwd"), 7)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := readColonFile(filepath.Join(etcDir, "group"), 4)
+	if err != nil {
+		return nil, err
+	}
+	shadowLines, err := readColonFile(filepath.Join(etcDir, "shadow"), 8)
+	if err != nil {
+		accountslog.WithError(err).Debug("Shadow file not readable, password status not reported.")
+	}
+	shadow := map[string]shadowEntry{}
+	for _, fields := range shadowLines {
+		shadow[fields[0]] = parseShadowEntry(fields)
+	}
+
+	groupNames := map[int]string{}
+	memberships := map[string][]string{}
+	for _, fields := range groups {
+		gid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		groupNames[gid] = fields[0]
+		dataset = append(dataset, AccountGroup{
+			ID:      "group/" + fields[0],
+			Name:    fields[0],
+			Gid:     gid,
+			Members: fields[3],
+		})
+		for _, member := range strings.Split(fields[3], ",") {
+			if member != "" {
+				memberships[member] = append(memberships[member], fields[0])
+			}
+		}
+	}
+
+	for _, fields := range passwd {
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		gid, err := strconv.Atoi(fields[3])
+		if err != nil {
+			continue
+		}
+		user := AccountUser{
+			ID:             "user/" + fields[0],
+			Name:           fields[0],
+			Uid:            uid,
+			Gid:            gid,
+			Home:           fields[5],
+			Shell:          fields[6],
+			Groups:         userGroups(groupNames[gid], memberships[fields[0]]),
+			PasswordStatus: PasswordStatusUnknown,
+		}
+		if entry, ok := shadow[fields[0]]; ok {
+			user.PasswordStatus = passwordStatus(entry.password)
+			user.PasswordLastChange = daysToEpoch(entry.lastChange)
+			user.PasswordMinDays = entry.minDays
+			user.PasswordMaxDays = entry.maxDays
+			user.PasswordWarnDays = entry.warnDays
+			user.InactiveDays = entry.inactive
+			user.ExpireEpoch = daysToEpoch(entry.expire)
+		}
+		dataset = append(dataset, user)
+	}
+
+	for _, rule := range readSudoers(etcDir) {
+		dataset = append(dataset, rule)
+	}
+	return dataset, nil
+}
+
+// readColonFile reads the colon separated entries of the passwd, group and shadow files, skipping comments and
+// entries with less than the expected fields.
+func readColonFile(path string, fields int) (entries [][]string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry := strings.Split(line, ":")
+		if len(entry) < fields {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func parseShadowEntry(fields []string) shadowEntry {
+	optionalInt := func(value string) *int64 {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return &n
+	}
+	return shadowEntry{
+		password:   fields[1],
+		lastChange: optionalInt(fields[2]),
+		minDays:    optionalInt(fields[3]),
+		maxDays:    optionalInt(fields[4]),
+		warnDays:   optionalInt(fields[5]),
+		inactive:   optionalInt(fields[6]),
+		expire:     optionalInt(fields[7]),
+	}
+}
+
+// passwordStatus returns whether the hashed password of the shadow file is set, empty, or locked with a "!" or
+// "*" prefix.
+func passwordStatus(password string) string {
+	switch {
+	case password == "":
+		return PasswordStatusEmpty
+	case strings.HasPrefix(password, "!"), strings.HasPrefix(password, "*"):
+		return PasswordStatusLocked
+	default:
+		return PasswordStatusSet
+	}
+}
+
+func daysToEpoch(days *int64) *int64 {
+	if days == nil {
+		return nil
+	}
+	epoch := *days * int64((24 * time.Hour).Seconds())
+	return &epoch
+}
+
+// userGroups returns the primary group followed by the supplementary ones, sorted.
+func userGroups(primary string, supplementary []string) string {
+	sorted := append([]string{}, supplementary...)
+	sort.Strings(sorted)
+	var groups []string
+	if primary != "" {
+		groups = append(groups, primary)
+	}
+	for _, group := range sorted {
+		if group != primary {
+			groups = append(groups, group)
+		}
+	}
+	return strings.Join(groups, ",")
+}
+
+// readSudoers reads the user specifications of the sudoers file and the files of its included directories.
+func readSudoers(etcDir string) (rules []SudoersRule) {
+	seen := map[string]bool{}
+	var readFile func(path string, depth int)
+	readFile = func(path string, depth int) {
+		lines, err := readSudoersLines(path)
+		if err != nil {
+			accountslog.WithError(err).WithField("file", path).Debug("Cannot read sudoers file.")
+			return
+		}
+		file := strings.TrimPrefix(path, filepath.Clean(etcDir))
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			switch {
+			case fields[0] == "#includedir" || fields[0] == "@includedir":
+				// sudo limits the nesting of includes
+				if len(fields) > 1 && depth < 8 {
+					for _, included := range sudoersDir(etcDir, fields[1]) {
+						readFile(included, depth+1)
+					}
+				}
+			case fields[0] == "#include" || fields[0] == "@include":
+				if len(fields) > 1 && depth < 8 {
+					readFile(hostEtcPath(etcDir, fields[1]), depth+1)
+				}
+			case strings.HasPrefix(fields[0], "#"), strings.HasPrefix(fields[0], "Defaults"),
+				strings.HasSuffix(fields[0], "_Alias"):
+				continue
+			default:
+				id := fmt.Sprintf("sudoers%s/%s", file, fields[0])
+				for n := 1; seen[id]; n++ {
+					id = fmt.Sprintf("sudoers%s/%s-%d", file, fields[0], n)
+				}
+				seen[id] = true
+				rules = append(rules, SudoersRule{ID: id, File: file, Rule: strings.Join(fields, " ")})
+			}
+		}
+	}
+	readFile(filepath.Join(etcDir, "sudoers"), 0)
+	return rules
+}
+
+// readSudoersLines returns the non-empty lines of a sudoers file, joining the ones continued with a backslash.
+func readSudoersLines(path string) (lines []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var current string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(line, "\\") {
+			current += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		current += line
+		if strings.TrimSpace(current) != "" {
+			lines = append(lines, strings.TrimSpace(current))
+		}
+		current = ""
+	}
+	return lines, scanner.Err()
+}
+
+// sudoersDir returns the files of an included directory read by sudo, skipping the ones ending in "~" or
+// containing a ".".
+func sudoersDir(etcDir, dir string) (files []string) {
+	dir = hostEtcPath(etcDir, dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, "~") || strings.Contains(name, ".") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	return files
+}
+
+// hostEtcPath maps the absolute /etc paths of the sudoers includes to the host etc directory.
+func hostEtcPath(etcDir, path string) string {
+	if strings.HasPrefix(path, "/etc/") {
+		return filepath.Join(etcDir, strings.TrimPrefix(path, "/etc/"))
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(etcDir, path)
+	}
+	return path
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+)
+
+func writeEtc(t *testing.T, etc string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(etc, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+func int64Ptr(n int64) *int64 {
+	return &n
+}
+
+func TestReadAccounts(t *testing.T) {
+	// GIVEN the passwd, group, shadow and sudoers files of an etc directory
+	etc := t.TempDir()
+	writeEtc(t, etc, map[string]string{
+		"passwd": `root:x:0:0:root:/root:/bin/bash
+# comment
+alice:x:1000:1000:Alice:/home/alice:/bin/zsh
+invalid:x:1001
+`,
+		"group": `root:x:0:
+sudo:x:27:alice
+alice:x:1000:
+docker:x:998:alice,bob
+`,
+		"shadow": `root:!:19000:0:99999:7:::
+alice:$6$salt$hash:19200:1:90:14:30:20000:
+`,
+		"sudoers": `# sudoers file
+Defaults	env_reset
+User_Alias ADMINS = alice
+root	ALL=(ALL:ALL) ALL
+%sudo	ALL=(ALL:ALL) ALL
+#includedir /etc/sudoers.d
+`,
+		"sudoers.d/deploy":     "alice ALL=(root) NOPASSWD: /usr/bin/systemctl restart app, \\\n\t/usr/bin/systemctl status app\n",
+		"sudoers.d/README.txt": "alice ALL=(ALL) ALL\n",
+	})
+
+	// WHEN the accounts are read
+	dataset, err := readAccounts(etc)
+	require.NoError(t, err)
+
+	// THEN the groups, users and sudoers rules are reported
+	assert.Equal(t, agent.PluginInventoryDataset{
+		AccountGroup{ID: "group/root", Name: "root", Gid: 0},
+		AccountGroup{ID: "group/sudo", Name: "sudo", Gid: 27, Members: "alice"},
+		AccountGroup{ID: "group/alice", Name: "alice", Gid: 1000},
+		AccountGroup{ID: "group/docker", Name: "docker", Gid: 998, Members: "alice,bob"},
+		AccountUser{
+			ID: "user/root", Name: "root", Uid: 0, Gid: 0, Home: "/root", Shell: "/bin/bash", Groups: "root",
+			PasswordStatus:     PasswordStatusLocked,
+			PasswordLastChange: int64Ptr(19000 * 86400),
+			PasswordMinDays:    int64Ptr(0),
+			PasswordMaxDays:    int64Ptr(99999),
+			PasswordWarnDays:   int64Ptr(7),
+		},
+		AccountUser{
+			ID: "user/alice", Name: "alice", Uid: 1000, Gid: 1000, Home: "/home/alice", Shell: "/bin/zsh",
+			Groups:             "alice,docker,sudo",
+			PasswordStatus:     PasswordStatusSet,
+			PasswordLastChange: int64Ptr(19200 * 86400),
+			PasswordMinDays:    int64Ptr(1),
+			PasswordMaxDays:    int64Ptr(90),
+			PasswordWarnDays:   int64Ptr(14),
+			InactiveDays:       int64Ptr(30),
+			ExpireEpoch:        int64Ptr(20000 * 86400),
+		},
+		SudoersRule{ID: "sudoers/sudoers/root", File: "/sudoers", Rule: "root ALL=(ALL:ALL) ALL"},
+		SudoersRule{ID: "sudoers/sudoers/%sudo", File: "/sudoers", Rule: "%sudo ALL=(ALL:ALL) ALL"},
+		SudoersRule{
+			ID:   "sudoers/sudoers.d/deploy/alice",
+			File: "/sudoers.d/deploy",
+			Rule: "alice ALL=(root) NOPASSWD: /usr/bin/systemctl restart app, /usr/bin/systemctl status app",
+		},
+	}, dataset)
+}
+
+func TestReadAccounts_ShadowNotReadable(t *testing.T) {
+	etc := t.TempDir()
+	writeEtc(t, etc, map[string]string{
+		"passwd": "daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin\n",
+		"group":  "daemon:x:1:\n",
+	})
+
+	dataset, err := readAccounts(etc)
+	require.NoError(t, err)
+
+	require.Len(t, dataset, 2)
+	assert.Equal(t, PasswordStatusUnknown, dataset[1].(AccountUser).PasswordStatus)
+}
+
+func TestReadAccounts_MissingPasswd(t *testing.T) {
+	_, err := readAccounts(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestPasswordStatus(t *testing.T) {
+	assert.Equal(t, PasswordStatusEmpty, passwordStatus(""))
+	assert.Equal(t, PasswordStatusLocked, passwordStatus("!$6$salt$hash"))
+	assert.Equal(t, PasswordStatusLocked, passwordStatus("*"))
+	assert.Equal(t, PasswordStatusSet, passwordStatus("$y$j9T$salt$hash"))
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+const (
+	ApkDBDir       = "/lib/apk/db"
+	apkInstalledDB = "installed"
+)
+
+var apklog = log.WithPlugin("Apk")
+
+type ApkItem struct {
+	Name         string `json:"id"`
+	Version      string `json:"version"`
+	Architecture string `json:"architecture"`
+	Origin       string `json:"origin,omitempty"`
+	InstallTime  string `json:"installed_epoch"`
+}
+
+func (p ApkItem) SortKey() string {
+	return p.Name
+}
+
+// NewApkPlugin creates a plugin reporting the packages installed with the Alpine package manager, read
+// from its installed database.
+func NewApkPlugin(id ids.PluginID, ctx agent.AgentContext) agent.Plugin {
+	cfg := ctx.Config()
+	dbDir := helpers.HostRoot(ApkDBDir)
+	return &packagesDBPlugin{
+		PluginCommon: agent.PluginCommon{ID: id, Context: ctx},
+		frequency: config.ValidateConfigFrequencySetting(
+			cfg.ApkRefreshSec,
+			config.FREQ_MINIMUM_INVENTORY_SAMPLE_RATE,
+			config.FREQ_PLUGIN_PACKAGE_MGRS_UPDATES,
+			cfg.DisableAllPlugins,
+		) * time.Second,
+		watchDir: dbDir,
+		logger:   apklog,
+		fetch: func() (agent.PluginInventoryDataset, error) {
+			return readApkPackages(filepath.Join(dbDir, apkInstalledDB), helpers.HostRoot())
+		},
+	}
+}
+
+// readApkPackages parses the apk installed database, where each package is a block of "key:value" lines
+// separated by a blank line. apk doesn't record the install time, so it's guessed from the first file of the
+// package, relative to the host root.
+func readApkPackages(dbPath, root string) (packages agent.PluginInventoryDataset, err error) {
+	file, err := os.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var item ApkItem
+	var dir, firstFile string
+	flush := func() {
+		if item.Name != "" {
+			if firstFile != "" {
+				item.InstallTime = changeTime(filepath.Join(root, firstFile))
+			}
+			packages = append(packages, item)
+		}
+		item, dir, firstFile = ApkItem{}, "", ""
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+		value := line[2:]
+		switch line[0] {
+		case 'P':
+			item.Name = value
+		case 'V':
+			item.Version = value
+		case 'A':
+			item.Architecture = value
+		case 'o':
+			item.Origin = value
+		case 'F':
+			dir = value
+		case 'R':
+			if firstFile == "" {
+				firstFile = filepath.Join(dir, value)
+			}
+		}
+	}
+	flush()
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
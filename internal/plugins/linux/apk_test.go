@@ -0,0 +1,68 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apkInstalled = `C:Q1AdrwIW6JvHfYiGVY2WvWYt9p1J4=
+P:musl
+V:1.2.3-r4
+A:x86_64
+S:383152
+I:622592
+T:the musl c library (libc) implementation
+o:musl
+F:lib
+R:ld-musl-x86_64.so.1
+a:0:0:755
+R:libc.musl-x86_64.so.1
+
+C:Q1yB3K6uPjqEh9W3ztQ8Yq8m9rD0U=
+P:alpine-baselayout-data
+V:3.4.0-r0
+A:x86_64
+o:alpine-baselayout
+`
+
+func TestReadApkPackages(t *testing.T) {
+	// GIVEN an apk database and the files of the first package
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "lib"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "lib", "ld-musl-x86_64.so.1"), nil, 0644))
+	db := filepath.Join(root, "installed")
+	require.NoError(t, os.WriteFile(db, []byte(apkInstalled), 0644))
+
+	// WHEN the packages are read
+	packages, err := readApkPackages(db, root)
+	require.NoError(t, err)
+
+	// THEN all the packages are reported, with the install time of the ones whose files are found
+	require.Len(t, packages, 2)
+	musl := packages[0].(ApkItem)
+	assert.Equal(t, "musl", musl.Name)
+	assert.Equal(t, "1.2.3-r4", musl.Version)
+	assert.Equal(t, "x86_64", musl.Architecture)
+	assert.Equal(t, "musl", musl.Origin)
+	assert.NotEmpty(t, musl.InstallTime)
+	assert.Equal(t, ApkItem{
+		Name:         "alpine-baselayout-data",
+		Version:      "3.4.0-r0",
+		Architecture: "x86_64",
+		Origin:       "alpine-baselayout",
+	}, packages[1])
+}
+
+func TestReadApkPackages_MissingDatabase(t *testing.T) {
+	_, err := readApkPackages(filepath.Join(t.TempDir(), "installed"), "/")
+	assert.Error(t, err)
+}
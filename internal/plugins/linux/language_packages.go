@@ -0,0 +1,287 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"bufio"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/entity"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+// Language package managers, reported as the "packages/<manager>" plugins.
+const (
+	LanguagePackagesPython = "python"
+	LanguagePackagesNpm    = "npm"
+	LanguagePackagesGem    = "gem"
+	LanguagePackagesGo     = "go"
+)
+
+// LanguagePackageManagers are the language package managers supported by NewLanguagePackagesPlugin.
+var LanguagePackageManagers = []string{
+	LanguagePackagesPython,
+	LanguagePackagesNpm,
+	LanguagePackagesGem,
+	LanguagePackagesGo,
+}
+
+var langpkglog = log.WithPlugin("LanguagePackages")
+
+var (
+	gemspecNameRegexp    = regexp.MustCompile(`\.name\s*=\s*"([^"]+)"`)
+	gemspecVersionRegexp = regexp.MustCompile(`\.version\s*=\s*"([^"]+)"`)
+)
+
+// LanguagePackageItem is a package found in a location. Its ID, "<location>:<name>", is stable across samples
+// since the same package may be installed in several locations.
+type LanguagePackageItem struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Location string `json:"location"`
+}
+
+func (p LanguagePackageItem) SortKey() string {
+	return p.ID
+}
+
+// languagePackagesFinder returns the packages found in a directory.
+type languagePackagesFinder func(dir string) ([]LanguagePackageItem, error)
+
+type LanguagePackagesPlugin struct {
+	agent.PluginCommon
+	frequency time.Duration
+	hostRoot  string
+	paths     []string
+	find      languagePackagesFinder
+}
+
+// NewLanguagePackagesPlugin creates a plugin reporting the packages installed by the given language package
+// manager, one of LanguagePackageManagers, under the "packages/<manager>" plugin ID.
+func NewLanguagePackagesPlugin(manager string, ctx agent.AgentContext) (*LanguagePackagesPlugin, error) {
+	cfg := ctx.Config()
+	p := &LanguagePackagesPlugin{
+		PluginCommon: agent.PluginCommon{ID: ids.PluginID{Category: "packages", Term: manager}, Context: ctx},
+		frequency: config.ValidateConfigFrequencySetting(
+			cfg.LanguagePackagesRefreshSec,
+			config.FREQ_MINIMUM_INVENTORY_SAMPLE_RATE,
+			config.FREQ_PLUGIN_LANGUAGE_PACKAGES_UPDATES,
+			cfg.DisableAllPlugins,
+		) * time.Second,
+		hostRoot: cfg.OverrideHostRoot,
+	}
+	switch manager {
+	case LanguagePackagesPython:
+		p.paths, p.find = cfg.LanguagePackagesPythonPaths, findPythonPackages
+	case LanguagePackagesNpm:
+		p.paths, p.find = cfg.LanguagePackagesNpmPaths, findNpmPackages
+	case LanguagePackagesGem:
+		p.paths, p.find = cfg.LanguagePackagesGemPaths, findGemPackages
+	case LanguagePackagesGo:
+		p.paths, p.find = cfg.LanguagePackagesGoPaths, findGoModules
+	default:
+		return nil, fmt.Errorf("unsupported language package manager: %s", manager)
+	}
+	return p, nil
+}
+
+// Run is the main processing loop that drives the logic for the plugin
+func (p *LanguagePackagesPlugin) Run() {
+	if p.frequency <= config.FREQ_DISABLE_SAMPLING {
+		langpkglog.WithField("plugin", p.ID.String()).Debug("Disabled.")
+		return
+	}
+
+	refreshTimer := time.NewTicker(p.frequency)
+	for {
+		p.EmitInventory(p.packages(), entity.NewFromNameWithoutID(p.Context.EntityKey()))
+		<-refreshTimer.C
+	}
+}
+
+// packages returns the packages found in the configured paths, identified by their host location and name.
+func (p *LanguagePackagesPlugin) packages() (packages agent.PluginInventoryDataset) {
+	var items []LanguagePackageItem
+	for _, dir := range p.dirs() {
+		found, err := p.find(dir)
+		if err != nil {
+			langpkglog.WithError(err).WithField("dir", dir).Debug("Cannot read language packages.")
+			continue
+		}
+		for _, item := range found {
+			if p.hostRoot != "" {
+				item.Location = strings.TrimPrefix(item.Location, filepath.Clean(p.hostRoot))
+			}
+			item.ID = item.Location + ":" + item.Name
+			items = append(items, item)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	for _, item := range items {
+		packages = append(packages, item)
+	}
+	return packages
+}
+
+// dirs expands the glob patterns of the configured paths, relative to the host root.
+func (p *LanguagePackagesPlugin) dirs() (dirs []string) {
+	for _, pattern := range p.paths {
+		matches, err := filepath.Glob(filepath.Join(p.hostRoot, pattern))
+		if err != nil {
+			langpkglog.WithError(err).WithField("path", pattern).Warn("invalid language packages path")
+			continue
+		}
+		dirs = append(dirs, matches...)
+	}
+	return dirs
+}
+
+// findPythonPackages reads the name and version headers of the METADATA file of the "*.dist-info" directories.
+func findPythonPackages(dir string) (items []LanguagePackageItem, err error) {
+	metadataFiles, err := filepath.Glob(filepath.Join(dir, "*.dist-info", "METADATA"))
+	if err != nil {
+		return nil, err
+	}
+	for _, metadata := range metadataFiles {
+		item, err := parsePythonMetadata(metadata)
+		if err != nil || item.Name == "" {
+			continue
+		}
+		item.Location = dir
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parsePythonMetadata(path string) (item LanguagePackageItem, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return item, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// headers end at the first blank line, followed by the package description
+	for scanner.Scan() && scanner.Text() != "" {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Name":
+			item.Name = strings.TrimSpace(value)
+		case "Version":
+			item.Version = strings.TrimSpace(value)
+		}
+	}
+	return item, scanner.Err()
+}
+
+// findNpmPackages reads the package.json of the packages in a node_modules directory, including the scoped
+// ones under "@scope" directories.
+func findNpmPackages(dir string) (items []LanguagePackageItem, err error) {
+	var manifests []string
+	for _, pattern := range []string{filepath.Join(dir, "*", "package.json"), filepath.Join(dir, "@*", "*", "package.json")} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, matches...)
+	}
+	for _, manifest := range manifests {
+		content, err := os.ReadFile(manifest)
+		if err != nil {
+			continue
+		}
+		var pkg struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(content, &pkg); err != nil || pkg.Name == "" {
+			continue
+		}
+		items = append(items, LanguagePackageItem{Name: pkg.Name, Version: pkg.Version, Location: filepath.Dir(manifest)})
+	}
+	return items, nil
+}
+
+// findGemPackages reads the name and version of the gem specifications of a directory. When they can't be
+// found, they are taken from the "<name>-<version>.gemspec" file name.
+func findGemPackages(dir string) (items []LanguagePackageItem, err error) {
+	specs, err := filepath.Glob(filepath.Join(dir, "*.gemspec"))
+	if err != nil {
+		return nil, err
+	}
+	for _, spec := range specs {
+		var item LanguagePackageItem
+		base := strings.TrimSuffix(filepath.Base(spec), ".gemspec")
+		if i := strings.LastIndex(base, "-"); i > 0 {
+			item.Name, item.Version = base[:i], base[i+1:]
+		}
+		if content, err := os.ReadFile(spec); err == nil {
+			if m := gemspecNameRegexp.FindSubmatch(content); m != nil {
+				item.Name = string(m[1])
+			}
+			if m := gemspecVersionRegexp.FindSubmatch(content); m != nil {
+				item.Version = string(m[1])
+			}
+		}
+		if item.Name == "" {
+			continue
+		}
+		item.Location = dir
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// findGoModules reads the build info embedded in the Go executables of a directory, reporting their main module
+// and the modules they depend on.
+func findGoModules(dir string) (items []LanguagePackageItem, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if fi, err := entry.Info(); err != nil || fi.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		executable := filepath.Join(dir, entry.Name())
+		info, err := buildinfo.ReadFile(executable)
+		if err != nil {
+			// not a Go executable
+			continue
+		}
+		if info.Main.Path != "" {
+			items = append(items, LanguagePackageItem{Name: info.Main.Path, Version: info.Main.Version, Location: executable})
+		}
+		for _, dep := range info.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			items = append(items, LanguagePackageItem{Name: dep.Path, Version: dep.Version, Location: executable})
+		}
+	}
+	return items, nil
+}
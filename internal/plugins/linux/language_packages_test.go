@@ -0,0 +1,131 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func newLanguagePackagesPlugin(t *testing.T, manager string, cfg *config.Config) *LanguagePackagesPlugin {
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(cfg)
+	p, err := NewLanguagePackagesPlugin(manager, ctx)
+	require.NoError(t, err)
+	return p
+}
+
+func TestFindPythonPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "requests-2.28.1.dist-info", "METADATA"),
+		"Metadata-Version: 2.1\nName: requests\nVersion: 2.28.1\nSummary: Python HTTP for Humans.\n\nName: not a header\n")
+	writeFile(t, filepath.Join(dir, "broken-1.0.dist-info", "RECORD"), "")
+
+	items, err := findPythonPackages(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []LanguagePackageItem{{Name: "requests", Version: "2.28.1", Location: dir}}, items)
+}
+
+func TestFindNpmPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "npm", "package.json"), `{"name": "npm", "version": "8.19.2"}`)
+	writeFile(t, filepath.Join(dir, "@angular", "cli", "package.json"), `{"name": "@angular/cli", "version": "14.2.6"}`)
+	writeFile(t, filepath.Join(dir, "invalid", "package.json"), `{`)
+
+	items, err := findNpmPackages(dir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []LanguagePackageItem{
+		{Name: "npm", Version: "8.19.2", Location: filepath.Join(dir, "npm")},
+		{Name: "@angular/cli", Version: "14.2.6", Location: filepath.Join(dir, "@angular", "cli")},
+	}, items)
+}
+
+func TestFindGemPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rake-13.0.6.gemspec"), `# -*- encoding: utf-8 -*-
+Gem::Specification.new do |s|
+  s.name = "rake".freeze
+  s.version = "13.0.6"
+end
+`)
+	writeFile(t, filepath.Join(dir, "net-http-persistent-4.0.1.gemspec"), "")
+
+	items, err := findGemPackages(dir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []LanguagePackageItem{
+		{Name: "rake", Version: "13.0.6", Location: dir},
+		{Name: "net-http-persistent", Version: "4.0.1", Location: dir},
+	}, items)
+}
+
+func TestFindGoModules(t *testing.T) {
+	// GIVEN the directory of the test executable, built with Go
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	// WHEN the Go modules are read
+	items, err := findGoModules(filepath.Dir(executable))
+	require.NoError(t, err)
+
+	// THEN the modules it depends on are reported
+	var testify *LanguagePackageItem
+	for i := range items {
+		if items[i].Name == "github.com/stretchr/testify" {
+			testify = &items[i]
+		}
+	}
+	require.NotNil(t, testify)
+	assert.NotEmpty(t, testify.Version)
+	assert.Equal(t, executable, testify.Location)
+}
+
+func TestLanguagePackagesPlugin_Packages(t *testing.T) {
+	// GIVEN the same npm package in two global node_modules directories under the host root
+	hostRoot := t.TempDir()
+	writeFile(t, filepath.Join(hostRoot, "usr", "lib", "node_modules", "npm", "package.json"), `{"name": "npm", "version": "8.19.2"}`)
+	writeFile(t, filepath.Join(hostRoot, "usr", "local", "lib", "node_modules", "npm", "package.json"), `{"name": "npm", "version": "6.14.17"}`)
+	p := newLanguagePackagesPlugin(t, LanguagePackagesNpm, &config.Config{
+		OverrideHostRoot:         hostRoot,
+		LanguagePackagesNpmPaths: []string{"/usr/lib/node_modules", "/usr/*/lib/node_modules"},
+	})
+
+	// WHEN the packages are read
+	packages := p.packages()
+
+	// THEN both are reported, identified by their host location and name
+	assert.Equal(t, agent.PluginInventoryDataset{
+		LanguagePackageItem{ID: "/usr/lib/node_modules/npm:npm", Name: "npm", Version: "8.19.2", Location: "/usr/lib/node_modules/npm"},
+		LanguagePackageItem{ID: "/usr/local/lib/node_modules/npm:npm", Name: "npm", Version: "6.14.17", Location: "/usr/local/lib/node_modules/npm"},
+	}, packages)
+
+	// AND the id of a package doesn't change when another location is removed
+	require.NoError(t, os.RemoveAll(filepath.Join(hostRoot, "usr", "lib", "node_modules", "npm")))
+	assert.Equal(t, agent.PluginInventoryDataset{packages[1]}, p.packages())
+}
+
+func TestNewLanguagePackagesPlugin(t *testing.T) {
+	p := newLanguagePackagesPlugin(t, LanguagePackagesGo, &config.Config{})
+	assert.Equal(t, "packages/go", p.ID.String())
+
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{})
+	_, err := NewLanguagePackagesPlugin("cargo", ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,317 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/entity"
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+const (
+	tcpListenState = "0A"
+	udpUnconnState = "07"
+)
+
+// defaultEphemeralPorts is the ip_local_port_range default, used when it can't be read.
+var defaultEphemeralPorts = portRange{first: 32768, last: 60999}
+
+var portslog = log.WithPlugin("ListeningPorts")
+
+type ListeningPortsPlugin struct {
+	agent.PluginCommon
+	frequency time.Duration
+	// ephemeralUDP are the ids of the UDP sockets bound to an ephemeral port in the previous sample
+	ephemeralUDP map[string]bool
+}
+
+// ListeningPort is a TCP socket in the listen state or an unconnected UDP socket, with the process owning it.
+type ListeningPort struct {
+	ID       string `json:"id"`
+	Protocol string `json:"protocol"`
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Process  string `json:"process,omitempty"`
+	Service  string `json:"service,omitempty"`
+}
+
+func (p ListeningPort) SortKey() string {
+	return p.ID
+}
+
+// listeningSocket is a listening socket read from the /proc/net tables.
+type listeningSocket struct {
+	protocol string
+	address  string
+	port     int
+	uid      string
+	inode    string
+}
+
+// portRange is an inclusive range of ports.
+type portRange struct {
+	first, last int
+}
+
+func (r portRange) contains(port int) bool {
+	return port >= r.first && port <= r.last
+}
+
+func NewListeningPortsPlugin(id ids.PluginID, ctx agent.AgentContext) agent.Plugin {
+	cfg := ctx.Config()
+	return &ListeningPortsPlugin{
+		PluginCommon: agent.PluginCommon{ID: id, Context: ctx},
+		frequency: config.ValidateConfigFrequencySetting(
+			cfg.ListeningPortsRefreshSec,
+			config.FREQ_MINIMUM_INVENTORY_SAMPLE_RATE,
+			config.FREQ_PLUGIN_LISTENING_PORTS_UPDATES,
+			cfg.DisableAllPlugins,
+		) * time.Second,
+	}
+}
+
+// Run is the main processing loop that drives the logic for the plugin
+func (p *ListeningPortsPlugin) Run() {
+	if p.frequency <= config.FREQ_DISABLE_SAMPLING {
+		portslog.Debug("Disabled.")
+		return
+	}
+
+	refreshTimer := time.NewTicker(p.frequency)
+	for {
+		dataset, err := p.listeningPorts()
+		if err != nil {
+			portslog.WithError(err).Error("fetching listening ports")
+		} else {
+			p.EmitInventory(dataset, entity.NewFromNameWithoutID(p.Context.EntityKey()))
+		}
+		<-refreshTimer.C
+	}
+}
+
+// listeningPorts reads the listening sockets of the host network namespace, through the init process, and
+// attaches the process owning them and its service, if known. The UDP sockets bound to an ephemeral port, as
+// the ones of DNS clients, are only reported once they persist across two samples.
+func (p *ListeningPortsPlugin) listeningPorts() (dataset agent.PluginInventoryDataset, err error) {
+	sockets, err := readListeningSockets(helpers.HostProc("1", "net"))
+	if err != nil {
+		return nil, err
+	}
+	pids := socketPids(helpers.HostProc())
+	users := usernames(helpers.HostEtc("passwd"))
+	ephemeralPorts := readPortRange(helpers.HostProc("sys", "net", "ipv4", "ip_local_port_range"))
+
+	seen := map[string]bool{}
+	ephemeralUDP := map[string]bool{}
+	for _, socket := range sockets {
+		port := ListeningPort{
+			ID:       socket.protocol + "/" + net.JoinHostPort(socket.address, strconv.Itoa(socket.port)),
+			Protocol: socket.protocol,
+			Address:  socket.address,
+			Port:     socket.port,
+			User:     socket.uid,
+		}
+		// sockets bound with SO_REUSEPORT share the address, only the first one is reported
+		if seen[port.ID] {
+			continue
+		}
+		seen[port.ID] = true
+
+		if strings.HasPrefix(socket.protocol, "udp") && ephemeralPorts.contains(socket.port) {
+			ephemeralUDP[port.ID] = true
+			if !p.ephemeralUDP[port.ID] {
+				continue
+			}
+		}
+
+		if name, ok := users[socket.uid]; ok {
+			port.User = name
+		}
+		if pid, ok := pids[socket.inode]; ok {
+			port.Process = processName(pid)
+			if service, ok := p.Context.GetServiceForPid(pid); ok {
+				port.Service = service
+			}
+		}
+		dataset = append(dataset, port)
+	}
+	p.ephemeralUDP = ephemeralUDP
+	return dataset, nil
+}
+
+// readListeningSockets parses the tcp, tcp6, udp and udp6 tables of a /proc/net directory. Missing tables, as
+// the IPv6 ones when IPv6 is disabled, are ignored.
+func readListeningSockets(procNet string) (sockets []listeningSocket, err error) {
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		lines, err := acquire.ReadLines(filepath.Join(procNet, protocol))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		listenState := tcpListenState
+		if strings.HasPrefix(protocol, "udp") {
+			listenState = udpUnconnState
+		}
+		for _, line := range lines {
+			socket, ok := parseSocketLine(protocol, listenState, line)
+			if ok {
+				sockets = append(sockets, socket)
+			}
+		}
+	}
+	return sockets, nil
+}
+
+// parseSocketLine parses a socket of a /proc/net table, returning false for the header, malformed lines, the
+// sockets not in the listen state and the UDP sockets with a remote address:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	0: 0100007F:0277 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 16383 ...
+func parseSocketLine(protocol, listenState, line string) (socket listeningSocket, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 10 || fields[3] != listenState {
+		return socket, false
+	}
+	if strings.HasPrefix(protocol, "udp") && strings.Trim(fields[2], "0:") != "" {
+		return socket, false
+	}
+	host, port, ok := strings.Cut(fields[1], ":")
+	if !ok {
+		return socket, false
+	}
+	address, err := parseHexAddress(host)
+	if err != nil {
+		return socket, false
+	}
+	portNumber, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return socket, false
+	}
+	return listeningSocket{
+		protocol: protocol,
+		address:  address,
+		port:     int(portNumber),
+		uid:      fields[7],
+		inode:    fields[9],
+	}, true
+}
+
+// parseHexAddress decodes the addresses of the /proc/net tables, written as 32-bit words in host byte order.
+func parseHexAddress(hexAddress string) (string, error) {
+	raw, err := hex.DecodeString(hexAddress)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) != net.IPv4len && len(raw) != net.IPv6len {
+		return "", fmt.Errorf("invalid address length: %s", hexAddress)
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	return ip.String(), nil
+}
+
+// socketPids maps the socket inodes to the pid of the first process, by pid, with a file descriptor for them.
+// The processes whose file descriptors can't be read are skipped.
+func socketPids(procDir string) map[string]int {
+	inodes := map[string]int{}
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return inodes
+	}
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+
+	for _, pid := range pids {
+		fdDir := filepath.Join(procDir, strconv.Itoa(pid), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+			if _, ok := inodes[inode]; !ok {
+				inodes[inode] = pid
+			}
+		}
+	}
+	return inodes
+}
+
+func processName(pid int) string {
+	comm, err := os.ReadFile(helpers.HostProc(strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+// usernames maps the uids of the host passwd file to the name of their first user.
+func usernames(passwdPath string) map[string]string {
+	names := map[string]string{}
+	entries, err := readColonFile(passwdPath, 7)
+	if err != nil {
+		portslog.WithError(err).Debug("Cannot read the users, reporting their uids.")
+		return names
+	}
+	for _, entry := range entries {
+		if _, ok := names[entry[2]]; !ok {
+			names[entry[2]] = entry[0]
+		}
+	}
+	return names
+}
+
+// readPortRange reads the "<first> <last>" ports of ip_local_port_range, or the default range if it can't be
+// read.
+func readPortRange(path string) portRange {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return defaultEphemeralPorts
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 2 {
+		return defaultEphemeralPorts
+	}
+	first, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return defaultEphemeralPorts
+	}
+	last, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return defaultEphemeralPorts
+	}
+	return portRange{first: first, last: last}
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+const (
+	procNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0277 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 16383 1 0000000000000000 100 0 0 10 0
+   1: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21312 1 0000000000000000 100 0 0 10 0
+   2: 0F02000A:0016 0202000A:D2A4 01 00000000:00000000 02:00094F1B 00000000     0        0 39143 4 0000000000000000 20 4 29 10 -1
+`
+	procNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21323 1 0000000000000000 100 0 0 10 0
+   1: 00000000000000000000000001000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 55012 1 0000000000000000 100 0 0 10 0
+`
+	procNetUDP = `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  277: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 18735 2 0000000000000000 0
+  300: 0F02000A:9E4C 08080808:0035 01 00000000:00000000 00:00000000 00000000     0        0 61001 2 0000000000000000 0
+  301: 00000000:0202 0A000001:0035 07 00000000:00000000 00:00000000 00000000     0        0 61002 2 0000000000000000 0
+  302: 00000000:AFC8 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 61003 2 0000000000000000 0
+`
+	passwd = `root:x:0:0:root:/root:/bin/bash
+systemd-resolve:x:101:103:systemd Resolver,,,:/run/systemd:/usr/sbin/nologin
+`
+)
+
+func writeProcNet(t *testing.T, dir string, tables map[string]string) {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for name, content := range tables {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestReadListeningSockets(t *testing.T) {
+	// GIVEN the tcp, tcp6 and udp tables, without udp6
+	procNet := t.TempDir()
+	writeProcNet(t, procNet, map[string]string{"tcp": procNetTCP, "tcp6": procNetTCP6, "udp": procNetUDP})
+
+	// WHEN the listening sockets are read
+	sockets, err := readListeningSockets(procNet)
+	require.NoError(t, err)
+
+	// THEN the listening TCP sockets and the UDP sockets without remote address are returned
+	assert.Equal(t, []listeningSocket{
+		{protocol: "tcp", address: "127.0.0.1", port: 631, uid: "0", inode: "16383"},
+		{protocol: "tcp", address: "0.0.0.0", port: 22, uid: "0", inode: "21312"},
+		{protocol: "tcp6", address: "::", port: 22, uid: "0", inode: "21323"},
+		{protocol: "tcp6", address: "::1", port: 8080, uid: "1000", inode: "55012"},
+		{protocol: "udp", address: "127.0.0.53", port: 53, uid: "101", inode: "18735"},
+		{protocol: "udp", address: "0.0.0.0", port: 45000, uid: "0", inode: "61003"},
+	}, sockets)
+}
+
+func TestSocketPids(t *testing.T) {
+	// GIVEN two processes sharing a socket and a process without readable file descriptors
+	proc := t.TempDir()
+	for pid, fds := range map[string]map[string]string{
+		"812":  {"0": "/dev/null", "3": "socket:[21312]"},
+		"1604": {"3": "socket:[21312]", "4": "socket:[55012]", "5": "pipe:[1234]"},
+	} {
+		fdDir := filepath.Join(proc, pid, "fd")
+		require.NoError(t, os.MkdirAll(fdDir, 0755))
+		for fd, target := range fds {
+			require.NoError(t, os.Symlink(target, filepath.Join(fdDir, fd)))
+		}
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(proc, "2001"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(proc, "sys"), 0755))
+
+	// WHEN the sockets are mapped to their processes
+	pids := socketPids(proc)
+
+	// THEN the shared socket belongs to the lowest pid
+	assert.Equal(t, map[string]int{"21312": 812, "55012": 1604}, pids)
+}
+
+func TestListeningPorts(t *testing.T) {
+	// GIVEN a host proc with listening sockets, one of them owned by a service process, and the host users
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+	writeProcNet(t, filepath.Join(proc, "1", "net"), map[string]string{"tcp": procNetTCP, "udp": procNetUDP})
+	writeProcNet(t, filepath.Join(proc, "sys", "net", "ipv4"), map[string]string{"ip_local_port_range": "32768\t60999\n"})
+	etc := t.TempDir()
+	t.Setenv("HOST_ETC", etc)
+	require.NoError(t, os.WriteFile(filepath.Join(etc, "passwd"), []byte(passwd), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(proc, "812", "fd"), 0755))
+	require.NoError(t, os.Symlink("socket:[21312]", filepath.Join(proc, "812", "fd", "3")))
+	require.NoError(t, os.WriteFile(filepath.Join(proc, "812", "comm"), []byte("sshd\n"), 0644))
+
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{})
+	ctx.On("GetServiceForPid", 812).Return("ssh.service", true)
+	p := NewListeningPortsPlugin(ids.PluginID{Category: "system", Term: "listening_ports"}, ctx).(*ListeningPortsPlugin)
+
+	// WHEN the listening ports are read
+	dataset, err := p.listeningPorts()
+	require.NoError(t, err)
+
+	// THEN the ports are reported with the process and service owning them, when known
+	// AND the UDP socket bound to an ephemeral port is not reported yet
+	expected := agent.PluginInventoryDataset{
+		ListeningPort{ID: "tcp/127.0.0.1:631", Protocol: "tcp", Address: "127.0.0.1", Port: 631, User: "root"},
+		ListeningPort{ID: "tcp/0.0.0.0:22", Protocol: "tcp", Address: "0.0.0.0", Port: 22, User: "root",
+			Process: "sshd", Service: "ssh.service"},
+		ListeningPort{ID: "udp/127.0.0.53:53", Protocol: "udp", Address: "127.0.0.53", Port: 53, User: "systemd-resolve"},
+	}
+	assert.Equal(t, expected, dataset)
+
+	// WHEN the listening ports are read again
+	dataset, err = p.listeningPorts()
+	require.NoError(t, err)
+
+	// THEN the UDP socket bound to an ephemeral port is reported once it persists
+	expected = append(expected,
+		ListeningPort{ID: "udp/0.0.0.0:45000", Protocol: "udp", Address: "0.0.0.0", Port: 45000, User: "root"})
+	assert.Equal(t, expected, dataset)
+}
+
+func TestReadPortRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip_local_port_range")
+	assert.Equal(t, defaultEphemeralPorts, readPortRange(path))
+
+	require.NoError(t, os.WriteFile(path, []byte("1024\t65535\n"), 0644))
+	assert.Equal(t, portRange{first: 1024, last: 65535}, readPortRange(path))
+}
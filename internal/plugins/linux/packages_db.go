@@ -0,0 +1,112 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/entity"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+)
+
+// packagesDBPlugin is the common implementation of the package manager plugins reading the packages database
+// from files instead of running the package manager. The packages are reported on startup and, at most once
+// per frequency, after any file in the watched directory changes.
+type packagesDBPlugin struct {
+	agent.PluginCommon
+	frequency time.Duration
+	watchDir  string
+	logger    log.Entry
+	fetch     func() (agent.PluginInventoryDataset, error)
+}
+
+// Run is the main processing loop that drives the logic for the plugin
+func (p *packagesDBPlugin) Run() {
+	if p.frequency <= config.FREQ_DISABLE_SAMPLING {
+		p.logger.Debug("Disabled.")
+		return
+	}
+
+	if _, err := os.Stat(p.watchDir); err != nil {
+		p.logger.WithError(err).Debug("Packages database not found.")
+		p.Unregister()
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		p.logger.WithError(err).Error("can't instantiate packages database watcher")
+		p.Unregister()
+		return
+	}
+	defer watcher.Close()
+
+	if err = watcher.Add(p.watchDir); err != nil {
+		p.logger.WithError(err).Error("can't setup packages database watcher")
+		p.Unregister()
+		return
+	}
+
+	counter := 1
+	ticker := time.NewTicker(1)
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				p.logger.Debug("Packages database watcher closed.")
+				return
+			}
+			if event.Op&fsnotify.Chmod == event.Op {
+				continue
+			}
+			counter = counter + 1
+			if counter > 1 {
+				p.logger.WithFields(logrus.Fields{
+					"frequency": p.frequency,
+					"counter":   counter,
+				}).Debug("Packages plugin oversampling.")
+			}
+		case <-ticker.C:
+			ticker.Stop()
+			ticker = time.NewTicker(p.frequency)
+			if counter > 0 {
+				data, err := p.fetch()
+				if err != nil {
+					p.logger.WithError(err).Error("fetching packages data")
+				} else {
+					p.EmitInventory(data, entity.NewFromNameWithoutID(p.Context.EntityKey()))
+				}
+				counter = 0
+			}
+		}
+	}
+}
+
+// changeTime returns the epoch of the last status change of a file, as a best guess of the install time
+// for the package managers not recording it. Empty if the file can't be found.
+func changeTime(path string) string {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return formatEpoch(time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec)))
+}
+
+func formatEpoch(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
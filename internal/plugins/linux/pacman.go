@@ -0,0 +1,117 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+var pacmanlog = log.WithPlugin("Pacman")
+
+type PacmanItem struct {
+	Name         string `json:"id"`
+	Version      string `json:"version"`
+	Architecture string `json:"architecture"`
+	InstallTime  string `json:"installed_epoch"`
+	Reason       string `json:"install_reason"`
+}
+
+func (p PacmanItem) SortKey() string {
+	return p.Name
+}
+
+// NewPacmanPlugin creates a plugin reporting the packages installed with the Arch Linux package manager, read
+// from its local database.
+func NewPacmanPlugin(id ids.PluginID, ctx agent.AgentContext) agent.Plugin {
+	cfg := ctx.Config()
+	localDB := helpers.HostVar("lib", "pacman", "local")
+	return &packagesDBPlugin{
+		PluginCommon: agent.PluginCommon{ID: id, Context: ctx},
+		frequency: config.ValidateConfigFrequencySetting(
+			cfg.PacmanRefreshSec,
+			config.FREQ_MINIMUM_INVENTORY_SAMPLE_RATE,
+			config.FREQ_PLUGIN_PACKAGE_MGRS_UPDATES,
+			cfg.DisableAllPlugins,
+		) * time.Second,
+		watchDir: localDB,
+		logger:   pacmanlog,
+		fetch: func() (agent.PluginInventoryDataset, error) {
+			return readPacmanPackages(localDB)
+		},
+	}
+}
+
+// readPacmanPackages reads the "desc" file of each package directory of the pacman local database.
+func readPacmanPackages(localDB string) (packages agent.PluginInventoryDataset, err error) {
+	descs, err := filepath.Glob(filepath.Join(localDB, "*", "desc"))
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range descs {
+		item, err := parsePacmanDesc(desc)
+		if err != nil {
+			pacmanlog.WithError(err).WithField("file", desc).Debug("Cannot read package description.")
+			continue
+		}
+		if item.Name != "" {
+			packages = append(packages, item)
+		}
+	}
+	return packages, nil
+}
+
+// parsePacmanDesc parses a package description, made of sections with a "%NAME%" header followed by one value
+// per line, and separated by a blank line.
+func parsePacmanDesc(path string) (item PacmanItem, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return item, err
+	}
+	defer file.Close()
+
+	var section string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			section = ""
+		case strings.HasPrefix(line, "%") && strings.HasSuffix(line, "%"):
+			section = line
+		default:
+			switch section {
+			case "%NAME%":
+				item.Name = line
+			case "%VERSION%":
+				item.Version = line
+			case "%ARCH%":
+				item.Architecture = line
+			case "%INSTALLDATE%":
+				item.InstallTime = line
+			case "%REASON%":
+				// 0: explicitly installed, 1: installed as a dependency
+				if line == "1" {
+					item.Reason = "dependency"
+				}
+			}
+			section = ""
+		}
+	}
+	if item.Name != "" && item.Reason == "" {
+		item.Reason = "explicit"
+	}
+	return item, scanner.Err()
+}
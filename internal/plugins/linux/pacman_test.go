@@ -0,0 +1,72 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePacmanDesc(t *testing.T, localDB, dir, content string) {
+	require.NoError(t, os.Mkdir(filepath.Join(localDB, dir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(localDB, dir, "desc"), []byte(content), 0644))
+}
+
+func TestReadPacmanPackages(t *testing.T) {
+	// GIVEN a pacman local database with two packages
+	localDB := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(localDB, "ALPM_DB_VERSION"), []byte("9\n"), 0644))
+	writePacmanDesc(t, localDB, "bash-5.1.016-1", `%NAME%
+bash
+
+%VERSION%
+5.1.016-1
+
+%DESC%
+The GNU Bourne Again shell
+
+%ARCH%
+x86_64
+
+%BUILDDATE%
+1641427214
+
+%INSTALLDATE%
+1642000000
+
+%VALIDATION%
+pgp
+`)
+	writePacmanDesc(t, localDB, "readline-8.1.002-1", `%NAME%
+readline
+
+%VERSION%
+8.1.002-1
+
+%ARCH%
+x86_64
+
+%INSTALLDATE%
+1641999999
+
+%REASON%
+1
+`)
+
+	// WHEN the packages are read
+	packages, err := readPacmanPackages(localDB)
+	require.NoError(t, err)
+
+	// THEN the name, version, architecture, install date and reason of the packages are reported
+	assert.Equal(t, []PacmanItem{
+		{Name: "bash", Version: "5.1.016-1", Architecture: "x86_64", InstallTime: "1642000000", Reason: "explicit"},
+		{Name: "readline", Version: "8.1.002-1", Architecture: "x86_64", InstallTime: "1641999999", Reason: "dependency"},
+	}, []PacmanItem{packages[0].(PacmanItem), packages[1].(PacmanItem)})
+}
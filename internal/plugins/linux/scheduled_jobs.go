@@ -0,0 +1,315 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/entity"
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+var schedlog = log.WithPlugin("ScheduledJobs")
+
+// reCronEnv matches the environment variable assignments of the crontabs.
+var reCronEnv = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*=`)
+
+// reTimerSpec matches the settings of the TimersCalendar and TimersMonotonic properties, e.g.
+// "{ OnCalendar=*-*-* 06:00:00 ; next_elapse=Sat 2022-10-15 06:00:00 UTC }".
+var reTimerSpec = regexp.MustCompile(`\{\s*(On\w+=[^;]*?)\s*;`)
+
+// ScheduledJobsPlugin reports the cron jobs of the system and user crontabs, and the systemd timers.
+type ScheduledJobsPlugin struct {
+	agent.PluginCommon
+	frequency time.Duration
+	timers    func() (string, error)
+}
+
+// CronJob is an entry of a crontab, identified by its file, schedule and command.
+type CronJob struct {
+	ID       string `json:"id"`
+	File     string `json:"file"`
+	User     string `json:"user"`
+	Schedule string `json:"schedule"`
+	Command  string `json:"command"`
+}
+
+func (j CronJob) SortKey() string {
+	return j.ID
+}
+
+// SystemdTimer is an installed or loaded systemd timer, with the unit it activates and its next and last
+// trigger times.
+type SystemdTimer struct {
+	ID          string `json:"id"`
+	Unit        string `json:"unit"`
+	Schedule    string `json:"schedule"`
+	State       string `json:"state"`
+	NextTrigger string `json:"next_trigger,omitempty"`
+	LastTrigger string `json:"last_trigger,omitempty"`
+}
+
+func (t SystemdTimer) SortKey() string {
+	return t.ID
+}
+
+func NewScheduledJobsPlugin(id ids.PluginID, ctx agent.AgentContext) agent.Plugin {
+	cfg := ctx.Config()
+	return &ScheduledJobsPlugin{
+		PluginCommon: agent.PluginCommon{ID: id, Context: ctx},
+		frequency: config.ValidateConfigFrequencySetting(
+			cfg.ScheduledJobsRefreshSec,
+			config.FREQ_MINIMUM_INVENTORY_SAMPLE_RATE,
+			config.FREQ_PLUGIN_SCHEDULED_JOBS_UPDATES,
+			cfg.DisableAllPlugins,
+		) * time.Second,
+		timers: showSystemdTimers,
+	}
+}
+
+// Run is the main processing loop that drives the logic for the plugin
+func (p *ScheduledJobsPlugin) Run() {
+	if p.frequency <= config.FREQ_DISABLE_SAMPLING {
+		schedlog.Debug("Disabled.")
+		return
+	}
+
+	refreshTimer := time.NewTicker(p.frequency)
+	for {
+		p.EmitInventory(p.scheduledJobs(), entity.NewFromNameWithoutID(p.Context.EntityKey()))
+		<-refreshTimer.C
+	}
+}
+
+func (p *ScheduledJobsPlugin) scheduledJobs() (dataset agent.PluginInventoryDataset) {
+	for _, job := range readCronJobs(helpers.HostEtc(), helpers.HostVar("spool", "cron")) {
+		dataset = append(dataset, job)
+	}
+
+	if p.timers == nil {
+		return dataset
+	}
+	output, err := p.timers()
+	if err != nil {
+		schedlog.WithError(err).Debug("Unable to list the systemd timers.")
+		return dataset
+	}
+	for _, timer := range parseSystemdTimers(output) {
+		dataset = append(dataset, timer)
+	}
+	return dataset
+}
+
+// readCronJobs reads the system crontabs, /etc/crontab and the files in /etc/cron.d, which have a user field,
+// and the user crontabs in the spool directory, named after their user. Debian based distros keep them under
+// a crontabs subdirectory.
+func readCronJobs(etcDir, spoolDir string) (jobs []CronJob) {
+	seen := map[string]bool{}
+	add := func(file string, fileJobs []CronJob) {
+		for _, job := range fileJobs {
+			job.File = file
+			id := fmt.Sprintf("cron%s/%s %s", file, job.Schedule, job.Command)
+			job.ID = id
+			for n := 1; seen[job.ID]; n++ {
+				job.ID = fmt.Sprintf("%s-%d", id, n)
+			}
+			seen[job.ID] = true
+			jobs = append(jobs, job)
+		}
+	}
+	readCrontab := func(path, user string) []CronJob {
+		fileJobs, err := parseCrontab(path, user)
+		if err != nil {
+			schedlog.WithError(err).WithField("file", path).Debug("Cannot read crontab.")
+		}
+		return fileJobs
+	}
+
+	add("/etc/crontab", readCrontab(filepath.Join(etcDir, "crontab"), ""))
+	for _, file := range cronFiles(filepath.Join(etcDir, "cron.d")) {
+		add(filepath.Join("/etc/cron.d", file), readCrontab(filepath.Join(etcDir, "cron.d", file), ""))
+	}
+	for _, dir := range []string{spoolDir, filepath.Join(spoolDir, "crontabs")} {
+		for _, user := range cronFiles(dir) {
+			rel, _ := filepath.Rel(spoolDir, filepath.Join(dir, user))
+			add(filepath.Join("/var/spool/cron", rel), readCrontab(filepath.Join(dir, user), user))
+		}
+	}
+	return jobs
+}
+
+// cronFiles returns the regular files of a directory, skipping the hidden and backup ones ignored by cron.
+func cronFiles(dir string) (files []string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		files = append(files, name)
+	}
+	return files
+}
+
+// parseCrontab parses the jobs of a crontab. When user is empty, it's a system crontab whose jobs have a user
+// field after the schedule:
+//
+//	17 *	* * *	root    cd / && run-parts --report /etc/cron.hourly
+//	@reboot		root	/usr/local/bin/startup.sh
+func parseCrontab(path, user string) (jobs []CronJob, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || reCronEnv.MatchString(line) {
+			continue
+		}
+		fields := strings.Fields(line)
+		scheduleFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		}
+		commandStart := scheduleFields
+		if user == "" {
+			commandStart++
+		}
+		if len(fields) <= commandStart {
+			schedlog.WithField("line", line).Debug("Invalid crontab line.")
+			continue
+		}
+		job := CronJob{
+			User:     user,
+			Schedule: strings.Join(fields[:scheduleFields], " "),
+			Command:  strings.Join(fields[commandStart:], " "),
+		}
+		if user == "" {
+			job.User = fields[scheduleFields]
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, scanner.Err()
+}
+
+// showSystemdTimers runs "systemctl show" for the installed and the loaded timers, since the "*.timer" pattern
+// only matches the loaded units.
+func showSystemdTimers() (string, error) {
+	if !systemdPresent() {
+		return "", nil
+	}
+	installed, err := helpers.RunCommand("/usr/bin/env", "", "systemctl", "list-unit-files", "--type=timer",
+		"--no-legend", "--no-pager")
+	if err != nil {
+		return "", err
+	}
+	loaded, err := helpers.RunCommand("/usr/bin/env", "", "systemctl", "list-units", "--all", "--type=timer",
+		"--plain", "--no-legend", "--no-pager")
+	if err != nil {
+		return "", err
+	}
+	units := timerUnits(installed + "\n" + loaded)
+	if len(units) == 0 {
+		return "", nil
+	}
+	args := append([]string{"systemctl", "show", "--no-pager",
+		"--property=Id,Unit,ActiveState,TimersCalendar,TimersMonotonic,NextElapseUSecRealtime,LastTriggerUSec"}, units...)
+	return helpers.RunCommand("/usr/bin/env", "", args...)
+}
+
+// timerUnits returns the sorted timers of the first column of the "systemctl list-unit-files" and "systemctl
+// list-units" outputs, skipping the templates, which can't be shown without an instance.
+func timerUnits(output string) (units []string) {
+	seen := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		// list-units marks the failed and not found units with a bullet
+		if len(fields) > 1 && fields[0] == "●" {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		unit := fields[0]
+		if !strings.HasSuffix(unit, ".timer") || strings.HasSuffix(unit, "@.timer") || seen[unit] {
+			continue
+		}
+		seen[unit] = true
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	return units
+}
+
+// parseSystemdTimers parses the output of "systemctl show" for the timers, with a block of "Property=value" lines
+// per timer, separated by a blank line. Timers may have several TimersCalendar and TimersMonotonic lines.
+func parseSystemdTimers(output string) (timers []SystemdTimer) {
+	var timer SystemdTimer
+	var schedule []string
+	flush := func() {
+		if timer.ID != "" {
+			timer.Schedule = strings.Join(schedule, ", ")
+			timers = append(timers, timer)
+		}
+		timer, schedule = SystemdTimer{}, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Id":
+			timer.ID = value
+		case "Unit":
+			timer.Unit = value
+		case "ActiveState":
+			timer.State = value
+		case "TimersCalendar", "TimersMonotonic":
+			for _, m := range reTimerSpec.FindAllStringSubmatch(value, -1) {
+				schedule = append(schedule, m[1])
+			}
+		case "NextElapseUSecRealtime":
+			timer.NextTrigger = timerTimestamp(value)
+		case "LastTriggerUSec":
+			timer.LastTrigger = timerTimestamp(value)
+		}
+	}
+	flush()
+	return timers
+}
+
+// timerTimestamp returns the timestamps of the timers, or empty when they never triggered or won't trigger.
+func timerTimestamp(value string) string {
+	if value == "n/a" || value == "0" {
+		return ""
+	}
+	return value
+}
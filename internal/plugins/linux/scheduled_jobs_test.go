@@ -0,0 +1,146 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+const systemctlShowTimers = `Unit=logrotate.service
+TimersCalendar={ OnCalendar=*-*-* 00:00:00 ; next_elapse=Sat 2022-10-15 00:00:00 UTC }
+NextElapseUSecRealtime=Sat 2022-10-15 00:00:00 UTC
+LastTriggerUSec=Fri 2022-10-14 00:00:03 UTC
+Id=logrotate.timer
+ActiveState=active
+
+Unit=systemd-tmpfiles-clean.service
+TimersMonotonic={ OnBootUSec=15min ; next_elapse=15min }
+TimersMonotonic={ OnUnitActiveUSec=1d ; next_elapse=1d 15min }
+NextElapseUSecRealtime=
+LastTriggerUSec=n/a
+Id=systemd-tmpfiles-clean.timer
+ActiveState=inactive
+`
+
+func TestParseCrontab(t *testing.T) {
+	dir := t.TempDir()
+	crontab := filepath.Join(dir, "crontab")
+	require.NoError(t, os.WriteFile(crontab, []byte(`# /etc/crontab: system-wide crontab
+SHELL=/bin/sh
+PATH=/usr/local/sbin:/usr/local/bin:/sbin:/bin:/usr/sbin:/usr/bin
+
+17 *	* * *	root    cd / && run-parts --report /etc/cron.hourly
+@reboot		root	/usr/local/bin/startup.sh
+* * * * *	root
+`), 0644))
+
+	jobs, err := parseCrontab(crontab, "")
+	require.NoError(t, err)
+	assert.Equal(t, []CronJob{
+		{User: "root", Schedule: "17 * * * *", Command: "cd / && run-parts --report /etc/cron.hourly"},
+		{User: "root", Schedule: "@reboot", Command: "/usr/local/bin/startup.sh"},
+	}, jobs)
+}
+
+func TestReadCronJobs(t *testing.T) {
+	// GIVEN a system crontab, a cron.d file with a backup copy, and a user crontab in the Debian spool dir
+	etc := t.TempDir()
+	spool := t.TempDir()
+	writeEtc(t, etc, map[string]string{
+		"crontab":           "0 3 * * * root /usr/sbin/backup\n",
+		"cron.d/certbot":    "0 */12 * * * root certbot -q renew\n0 */12 * * * root certbot -q renew\n",
+		"cron.d/certbot~":   "0 0 * * * root certbot renew\n",
+		"cron.d/.placehold": "",
+	})
+	writeEtc(t, spool, map[string]string{"crontabs/alice": "*/5 * * * * /home/alice/sync.sh\n"})
+
+	// WHEN the cron jobs are read
+	jobs := readCronJobs(etc, spool)
+
+	// THEN the jobs are reported with their host file, and duplicated jobs suffixed
+	assert.Equal(t, []CronJob{
+		{ID: "cron/etc/crontab/0 3 * * * /usr/sbin/backup", File: "/etc/crontab", User: "root",
+			Schedule: "0 3 * * *", Command: "/usr/sbin/backup"},
+		{ID: "cron/etc/cron.d/certbot/0 */12 * * * certbot -q renew", File: "/etc/cron.d/certbot", User: "root",
+			Schedule: "0 */12 * * *", Command: "certbot -q renew"},
+		{ID: "cron/etc/cron.d/certbot/0 */12 * * * certbot -q renew-1", File: "/etc/cron.d/certbot", User: "root",
+			Schedule: "0 */12 * * *", Command: "certbot -q renew"},
+		{ID: "cron/var/spool/cron/crontabs/alice/*/5 * * * * /home/alice/sync.sh", File: "/var/spool/cron/crontabs/alice",
+			User: "alice", Schedule: "*/5 * * * *", Command: "/home/alice/sync.sh"},
+	}, jobs)
+}
+
+func TestParseSystemdTimers(t *testing.T) {
+	assert.Equal(t, []SystemdTimer{
+		{
+			ID:          "logrotate.timer",
+			Unit:        "logrotate.service",
+			Schedule:    "OnCalendar=*-*-* 00:00:00",
+			State:       "active",
+			NextTrigger: "Sat 2022-10-15 00:00:00 UTC",
+			LastTrigger: "Fri 2022-10-14 00:00:03 UTC",
+		},
+		{
+			ID:       "systemd-tmpfiles-clean.timer",
+			Unit:     "systemd-tmpfiles-clean.service",
+			Schedule: "OnBootUSec=15min, OnUnitActiveUSec=1d",
+			State:    "inactive",
+		},
+	}, parseSystemdTimers(systemctlShowTimers))
+}
+
+func TestTimerUnits(t *testing.T) {
+	// GIVEN the installed timers, one of them a template and one not loaded, and the loaded ones
+	installed := `apt-daily.timer                enabled enabled
+fstrim.timer                   enabled enabled
+getty-timer@.timer             static  -
+`
+	loaded := `apt-daily.timer    loaded active waiting Daily apt download activities
+run-r0a1b2c3.timer loaded active waiting /usr/bin/true
+● broken.timer     not-found inactive dead broken.timer
+`
+
+	// WHEN the timer units are listed
+	units := timerUnits(installed + "\n" + loaded)
+
+	// THEN the installed and loaded timers are returned once, without the templates
+	assert.Equal(t, []string{"apt-daily.timer", "broken.timer", "fstrim.timer", "run-r0a1b2c3.timer"}, units)
+}
+
+func TestScheduledJobs(t *testing.T) {
+	// GIVEN a host with a system crontab and systemd timers
+	etc := t.TempDir()
+	t.Setenv("HOST_ETC", etc)
+	t.Setenv("HOST_VAR", t.TempDir())
+	writeEtc(t, etc, map[string]string{"crontab": "@daily root /usr/sbin/backup\n"})
+
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{})
+	p := NewScheduledJobsPlugin(ids.PluginID{Category: "services", Term: "scheduled_jobs"}, ctx).(*ScheduledJobsPlugin)
+	p.timers = func() (string, error) {
+		return "Id=fstrim.timer\nUnit=fstrim.service\nActiveState=active\n", nil
+	}
+
+	// WHEN the scheduled jobs are read
+	dataset := p.scheduledJobs()
+
+	// THEN both the cron jobs and the timers are reported
+	assert.Equal(t, agent.PluginInventoryDataset{
+		CronJob{ID: "cron/etc/crontab/@daily /usr/sbin/backup", File: "/etc/crontab", User: "root",
+			Schedule: "@daily", Command: "/usr/sbin/backup"},
+		SystemdTimer{ID: "fstrim.timer", Unit: "fstrim.service", State: "active"},
+	}, dataset)
+}
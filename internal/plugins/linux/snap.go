@@ -0,0 +1,131 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+// SnapMountDir is where snapd mounts the snaps on Debian based distros. Other distros use the
+// snap directory under the snapd state directory.
+const SnapMountDir = "/snap"
+
+var snaplog = log.WithPlugin("Snap")
+
+type SnapItem struct {
+	Name        string `json:"id"`
+	Version     string `json:"version"`
+	Revision    string `json:"revision"`
+	Type        string `json:"type"`
+	Channel     string `json:"channel,omitempty"`
+	Active      bool   `json:"active"`
+	InstallTime string `json:"installed_epoch"`
+}
+
+func (p SnapItem) SortKey() string {
+	return p.Name
+}
+
+// snapdState holds the fields of the snapd state file describing the installed snaps.
+type snapdState struct {
+	Data struct {
+		Snaps map[string]struct {
+			Type     string `json:"type"`
+			Active   bool   `json:"active"`
+			Current  string `json:"current"`
+			Channel  string `json:"channel"`
+			Tracking string `json:"tracking-channel"`
+		} `json:"snaps"`
+	} `json:"data"`
+}
+
+// snapMeta holds the fields of the snap.yaml metadata file of a snap revision.
+type snapMeta struct {
+	Version string `yaml:"version"`
+}
+
+// NewSnapPlugin creates a plugin reporting the snaps installed through snapd, read from its state file.
+func NewSnapPlugin(id ids.PluginID, ctx agent.AgentContext) agent.Plugin {
+	cfg := ctx.Config()
+	snapdDir := helpers.HostVar("lib", "snapd")
+	mountDirs := []string{helpers.HostRoot(SnapMountDir), filepath.Join(snapdDir, "snap")}
+	return &packagesDBPlugin{
+		PluginCommon: agent.PluginCommon{ID: id, Context: ctx},
+		frequency: config.ValidateConfigFrequencySetting(
+			cfg.SnapRefreshSec,
+			config.FREQ_MINIMUM_INVENTORY_SAMPLE_RATE,
+			config.FREQ_PLUGIN_PACKAGE_MGRS_UPDATES,
+			cfg.DisableAllPlugins,
+		) * time.Second,
+		watchDir: snapdDir,
+		logger:   snaplog,
+		fetch: func() (agent.PluginInventoryDataset, error) {
+			return readSnaps(snapdDir, mountDirs)
+		},
+	}
+}
+
+// readSnaps reads the installed snaps from the snapd state file. The state doesn't hold the versions, which are
+// read from the metadata of the current revision under the first mount directory having it. The install time
+// is the modification time of the downloaded snap file.
+func readSnaps(snapdDir string, mountDirs []string) (packages agent.PluginInventoryDataset, err error) {
+	content, err := os.ReadFile(filepath.Join(snapdDir, "state.json"))
+	if err != nil {
+		return nil, err
+	}
+	var state snapdState
+	if err = json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("cannot parse snapd state: %w", err)
+	}
+
+	for name, snap := range state.Data.Snaps {
+		item := SnapItem{
+			Name:     name,
+			Revision: snap.Current,
+			Type:     snap.Type,
+			Channel:  snap.Tracking,
+			Active:   snap.Active,
+		}
+		if item.Channel == "" {
+			item.Channel = snap.Channel
+		}
+		for _, mountDir := range mountDirs {
+			if version, err := snapVersion(filepath.Join(mountDir, name, snap.Current, "meta", "snap.yaml")); err == nil {
+				item.Version = version
+				break
+			}
+		}
+		if fi, err := os.Stat(filepath.Join(snapdDir, "snaps", name+"_"+snap.Current+".snap")); err == nil {
+			item.InstallTime = formatEpoch(fi.ModTime())
+		}
+		packages = append(packages, item)
+	}
+	return packages, nil
+}
+
+func snapVersion(metaPath string) (string, error) {
+	content, err := os.ReadFile(metaPath)
+	if err != nil {
+		return "", err
+	}
+	var meta snapMeta
+	if err = yaml.Unmarshal(content, &meta); err != nil {
+		return "", err
+	}
+	return meta.Version, nil
+}
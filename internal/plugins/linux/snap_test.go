@@ -0,0 +1,99 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
+)
+
+const snapdStateJSON = `{
+	"data": {
+		"snaps": {
+			"core20": {
+				"type": "base",
+				"sequence": [{"name": "core20", "snap-id": "DLqre5XGLbDqg9jPtiAhRRjDuPVa5X1q", "revision": "1611"}],
+				"active": true,
+				"current": "1611",
+				"channel": "stable"
+			}
+		}
+	},
+	"changes": {}
+}`
+
+func TestReadSnaps(t *testing.T) {
+	// GIVEN the snapd state, the downloaded snap and its metadata in the second mount dir
+	snapdDir := t.TempDir()
+	mountDir := filepath.Join(snapdDir, "snap")
+	require.NoError(t, os.WriteFile(filepath.Join(snapdDir, "state.json"), []byte(snapdStateJSON), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(snapdDir, "snaps"), 0755))
+	snapFile := filepath.Join(snapdDir, "snaps", "core20_1611.snap")
+	require.NoError(t, os.WriteFile(snapFile, nil, 0600))
+	installed := time.Unix(1650000000, 0)
+	require.NoError(t, os.Chtimes(snapFile, installed, installed))
+	require.NoError(t, os.MkdirAll(filepath.Join(mountDir, "core20", "1611", "meta"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(mountDir, "core20", "1611", "meta", "snap.yaml"),
+		[]byte("name: core20\nversion: '20220318'\ntype: base\n"), 0644))
+
+	// WHEN the snaps are read
+	packages, err := readSnaps(snapdDir, []string{filepath.Join(snapdDir, "missing"), mountDir})
+	require.NoError(t, err)
+
+	// THEN the snap is reported with the version of its current revision and its install time
+	require.Len(t, packages, 1)
+	assert.Equal(t, SnapItem{
+		Name:        "core20",
+		Version:     "20220318",
+		Revision:    "1611",
+		Type:        "base",
+		Channel:     "stable",
+		Active:      true,
+		InstallTime: "1650000000",
+	}, packages[0])
+}
+
+func TestReadSnaps_InvalidState(t *testing.T) {
+	snapdDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(snapdDir, "state.json"), []byte("{"), 0600))
+
+	_, err := readSnaps(snapdDir, nil)
+	assert.Error(t, err)
+}
+
+func TestSnapPlugin_HostRoot(t *testing.T) {
+	// GIVEN the snapd state under the host var dir and the snap metadata under the host root /snap dir
+	hostRoot := t.TempDir()
+	t.Setenv("HOST_ROOT", hostRoot)
+	t.Setenv("HOST_VAR", filepath.Join(hostRoot, "var"))
+	snapdDir := filepath.Join(hostRoot, "var", "lib", "snapd")
+	require.NoError(t, os.MkdirAll(snapdDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(snapdDir, "state.json"), []byte(snapdStateJSON), 0600))
+	metaDir := filepath.Join(hostRoot, "snap", "core20", "1611", "meta")
+	require.NoError(t, os.MkdirAll(metaDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(metaDir, "snap.yaml"), []byte("version: '20220318'\n"), 0644))
+
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{})
+	p := NewSnapPlugin(ids.PluginID{Category: "packages", Term: "snap"}, ctx).(*packagesDBPlugin)
+
+	// WHEN the snaps are read
+	packages, err := p.fetch()
+	require.NoError(t, err)
+
+	// THEN the version is read from the host root
+	require.Len(t, packages, 1)
+	assert.Equal(t, "20220318", packages[0].(SnapItem).Version)
+}
@@ -5,95 +5,196 @@ package socketapi
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net"
-	"strings"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/integration"
 	"github.com/newrelic/infrastructure-agent/pkg/integrations/v4/emitter"
 	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/sirupsen/logrus"
 )
 
-const IntegrationName = "socket-api"
+const (
+	IntegrationName = "socket-api"
+
+	// DefaultMaxLineSize is the maximum size of a payload line unless configured otherwise.
+	DefaultMaxLineSize = 4 * 1024 * 1024
+	initialBufferSize  = 64 * 1024
+	acceptRetryBackoff = 100 * time.Millisecond
+)
+
+// Config stores the socket API server configuration.
+type Config struct {
+	Host string
+	Port int
+	// UnixSocket is the path of a unix domain socket to listen on instead of the TCP host and port.
+	UnixSocket string
+	// MaxLineSize is the maximum size in bytes of a payload line. Connections sending longer lines are closed.
+	MaxLineSize int
+	// RateLimit is the maximum number of payloads per second accepted on each connection. Zero means no limit.
+	RateLimit int
+}
 
 // Server runtime for socket API server.
 type Server struct {
-	port    int
-	logger  log.Entry
-	emitter emitter.Emitter
-	readyCh chan struct{}
+	network     string
+	address     string
+	maxLineSize int
+	rateLimit   int
+	logger      log.Entry
+	emitter     emitter.Emitter
+	readyCh     chan struct{}
+	connsWg     sync.WaitGroup
 }
 
 // NewServer creates a new socket API server.
-func NewServer(emitter emitter.Emitter, port int) *Server {
-	logger := log.WithComponent("Server")
-	return &Server{
-		port:    port,
-		logger:  logger,
-		emitter: emitter,
-		readyCh: make(chan struct{}),
+func NewServer(emitter emitter.Emitter, cfg Config) *Server {
+	s := &Server{
+		network:     "tcp",
+		address:     net.JoinHostPort(cfg.Host, fmt.Sprint(cfg.Port)),
+		maxLineSize: cfg.MaxLineSize,
+		rateLimit:   cfg.RateLimit,
+		logger:      log.WithComponent("SocketAPI"),
+		emitter:     emitter,
+		readyCh:     make(chan struct{}),
+	}
+	if cfg.UnixSocket != "" {
+		s.network = "unix"
+		s.address = cfg.UnixSocket
 	}
+	if s.maxLineSize <= 0 {
+		s.maxLineSize = DefaultMaxLineSize
+	}
+	s.logger = s.logger.WithFields(logrus.Fields{"network": s.network, "address": s.address})
+	return s
 }
 
-// Serve serves socket API requests.
+// Serve serves socket API requests, handling each connection concurrently, until the context
+// is cancelled. Then it closes the listener and all the open connections.
 func (s *Server) Serve(ctx context.Context) {
 	def, err := integration.NewAPIDefinition(IntegrationName)
 	if err != nil {
+		close(s.readyCh)
 		s.logger.WithError(err).Error("cannot create integration definition")
 		return
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	listener, err := s.listen()
+	close(s.readyCh)
 	if err != nil {
-		s.logger.WithField("port", s.port).WithError(err).Error("trying to listen")
+		s.logger.WithError(err).Error("trying to listen")
 		return
 	}
-	defer listener.Close()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
+	s.logger.Debug("Socket API listening.")
 
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil {
+			s.logger.WithError(err).Debug("cannot close listener")
 		}
+	}()
 
-		// this is a PoC! just handling 1 connection fir this purpose is enough
-		close(s.readyCh)
+	for {
 		conn, err := listener.Accept()
 		if err != nil {
-			s.logger.WithField("port", s.port).WithError(err).Error("cannot accept connection")
-		}
-		defer func() {
-			if err = conn.Close(); err != nil {
-				s.logger.WithError(err).Error("cannot close connection")
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				break
 			}
+			s.logger.WithError(err).Warn("cannot accept connection")
+			time.Sleep(acceptRetryBackoff)
+			continue
+		}
+
+		s.connsWg.Add(1)
+		go func() {
+			defer s.connsWg.Done()
+			s.handle(ctx, conn, def)
 		}()
+	}
 
-		r := bufio.NewReader(conn)
-		for {
-			line, err := r.ReadString('\n')
-			if err == io.EOF {
-				break
-			}
+	s.connsWg.Wait()
+	s.logger.Debug("Socket API stopped.")
+}
 
-			if err != nil {
-				s.logger.WithError(err).Warn("cannot read connection")
-				break
+func (s *Server) listen() (net.Listener, error) {
+	if s.network == "unix" {
+		// remove the socket file left by a previous run, if any
+		if st, err := os.Stat(s.address); err == nil && st.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(s.address); err != nil {
+				return nil, fmt.Errorf("cannot remove stale socket file: %w", err)
 			}
+		}
+	}
+	return net.Listen(s.network, s.address)
+}
 
-			line = strings.TrimSuffix(line, "\n")
+// handle reads newline separated payloads from the connection and emits them.
+func (s *Server) handle(ctx context.Context, conn net.Conn, def integration.Definition) {
+	clog := s.logger.WithField("remote", conn.RemoteAddr().String())
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			clog.WithError(err).Debug("cannot close connection")
+		}
+	}()
 
-			err = s.emitter.Emit(def, nil, nil, []byte(line))
-			if err != nil {
-				s.logger.WithError(err).Error("cannot emit payload")
+	var minInterval time.Duration
+	if s.rateLimit > 0 {
+		minInterval = time.Second / time.Duration(s.rateLimit)
+	}
+	var next time.Time
+
+	// the buffer capacity also limits the line size, so it can't be greater than the maximum
+	bufSize := initialBufferSize
+	if bufSize > s.maxLineSize {
+		bufSize = s.maxLineSize
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, bufSize), s.maxLineSize)
+	for scanner.Scan() {
+		if minInterval > 0 {
+			if wait := time.Until(next); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
 			}
+			next = time.Now().Add(minInterval)
+		}
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		// the scanner reuses its buffer, while the emitter may process the payload asynchronously
+		payload := make([]byte, len(line))
+		copy(payload, line)
+		if err := s.emitter.Emit(def, nil, nil, payload); err != nil {
+			clog.WithError(err).Error("cannot emit payload")
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			clog.WithField("max_line_size", s.maxLineSize).Warn("payload exceeds the maximum line size, closing connection")
+		} else if !errors.Is(err, net.ErrClosed) {
+			clog.WithError(err).Warn("cannot read connection")
 		}
 	}
 }
 
 // WaitUntilReady blocks the call until server is ready to accept connections.
 func (s *Server) WaitUntilReady() {
-	_, _ = <-s.readyCh
+	<-s.readyCh
 }
@@ -6,36 +6,22 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/integration"
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/testhelp/testemit"
+	"github.com/newrelic/infrastructure-agent/pkg/databind/pkg/data"
 	network_helpers "github.com/newrelic/infrastructure-agent/pkg/helpers/network"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestPayloadFwServer_Serve(t *testing.T) {
-	t.Skipf("because time race, as WaitUntilReady is not right")
-
-	port, err := network_helpers.TCPPort()
-	require.NoError(t, err)
-
-	e := &testemit.RecordEmitter{}
-	pf := NewServer(e, port)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	go pf.Serve(ctx)
-
-	payloadWritten := make(chan struct{})
-	go func() {
-		pf.WaitUntilReady()
-		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
-		require.NoError(t, err)
-		_, err = conn.Write([]byte(strings.Replace(`{
+var payload = strings.Replace(`{
   "protocol_version": "4",
   "integration": {
     "name": "com.newrelic.foo",
@@ -51,23 +37,212 @@ func TestPayloadFwServer_Serve(t *testing.T) {
       }
     }
   ]
-}`, "\n", "", -1) + "\n"))
-		assert.NoError(t, err)
-		close(payloadWritten)
-	}()
+}`, "\n", "", -1) + "\n"
+
+// rawEmitter records the raw emitted payloads.
+type rawEmitter struct {
+	payloads chan string
+}
+
+func newRawEmitter() *rawEmitter {
+	return &rawEmitter{payloads: make(chan string, 100)}
+}
+
+func (e *rawEmitter) Emit(_ integration.Definition, _ data.Map, _ []data.EntityRewrite, json []byte) error {
+	e.payloads <- string(json)
+	return nil
+}
 
+func (e *rawEmitter) receive(t *testing.T) string {
+	t.Helper()
 	select {
-	case <-time.NewTimer(500 * time.Millisecond).C:
-		t.Fail()
-		return
-	case <-payloadWritten:
+	case p := <-e.payloads:
+		return p
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for payload")
+		return ""
 	}
+}
+
+func serve(t *testing.T, em interface {
+	Emit(integration.Definition, data.Map, []data.EntityRewrite, []byte) error
+}, cfg Config) (*Server, context.CancelFunc, chan struct{}) {
+	t.Helper()
+	s := NewServer(em, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.Serve(ctx)
+		close(stopped)
+	}()
+	s.WaitUntilReady()
+	return s, cancel, stopped
+}
+
+func TestPayloadFwServer_Serve(t *testing.T) {
+	port, err := network_helpers.TCPPort()
+	require.NoError(t, err)
+
+	e := &testemit.RecordEmitter{}
+	_, cancel, _ := serve(t, e, Config{Host: "localhost", Port: port})
+	defer cancel()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(payload))
+	require.NoError(t, err)
 
 	d, err := e.ReceiveFrom(IntegrationName)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, d)
 }
 
+func TestServer_ConcurrentConnections(t *testing.T) {
+	port, err := network_helpers.TCPPort()
+	require.NoError(t, err)
+
+	e := newRawEmitter()
+	_, cancel, _ := serve(t, e, Config{Host: "localhost", Port: port})
+	defer cancel()
+
+	// GIVEN two connections open at the same time
+	first, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	defer second.Close()
+
+	// WHEN the second one sends data while the first one remains open
+	_, err = second.Write([]byte("from-second\n"))
+	require.NoError(t, err)
+	// THEN it is handled without waiting for the first one to be closed
+	assert.Equal(t, "from-second", e.receive(t))
+
+	_, err = first.Write([]byte("from-first\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "from-first", e.receive(t))
+}
+
+func TestServer_UnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix domain sockets are not supported")
+	}
+	path := filepath.Join(t.TempDir(), "agent.sock")
+
+	e := newRawEmitter()
+	_, cancel, stopped := serve(t, e, Config{UnixSocket: path})
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("via-socket\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "via-socket", e.receive(t))
+
+	cancel()
+	<-stopped
+}
+
+func TestServer_MaxLineSize(t *testing.T) {
+	port, err := network_helpers.TCPPort()
+	require.NoError(t, err)
+
+	e := newRawEmitter()
+	_, cancel, _ := serve(t, e, Config{Host: "localhost", Port: port, MaxLineSize: 16})
+	defer cancel()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("short\n" + strings.Repeat("x", 32) + "\nafter\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "short", e.receive(t))
+
+	// the connection is closed after an exceeding line, so no more payloads are emitted
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	select {
+	case p := <-e.payloads:
+		t.Fatalf("unexpected payload %q", p)
+	default:
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	port, err := network_helpers.TCPPort()
+	require.NoError(t, err)
+
+	e := newRawEmitter()
+	_, cancel, _ := serve(t, e, Config{Host: "localhost", Port: port, RateLimit: 10})
+	defer cancel()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	start := time.Now()
+	_, err = conn.Write([]byte("1\n2\n3\n4\n"))
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		e.receive(t)
+	}
+	// 4 payloads at 10 per second require at least 300ms
+	assert.True(t, time.Since(start) >= 300*time.Millisecond)
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	port, err := network_helpers.TCPPort()
+	require.NoError(t, err)
+
+	e := newRawEmitter()
+	_, cancel, stopped := serve(t, e, Config{Host: "localhost", Port: port})
+
+	var conns []net.Conn
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		require.NoError(t, err)
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+	_, err = conns[2].Write([]byte("connected\n"))
+	require.NoError(t, err)
+	e.receive(t)
+
+	// WHEN the context is cancelled
+	cancel()
+
+	// THEN the server stops and closes all the open connections
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn net.Conn) {
+			defer wg.Done()
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			_, err := conn.Read(make([]byte, 1))
+			assert.Error(t, err)
+			assert.False(t, isTimeout(err), "connection was not closed by the server")
+		}(conn)
+	}
+	wg.Wait()
+
+	_, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	assert.Error(t, err)
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
 var il = integration.InstancesLookup{
 	Legacy: func(_ integration.DefinitionCommandConfig) (integration.Definition, error) {
 		return integration.Definition{Name: "bar"}, nil
@@ -0,0 +1,250 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+// Package spool provides an on-disk FIFO queue for the payloads that couldn't be submitted to the
+// backend, so they can be submitted again later, also after an agent restart.
+package spool
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+)
+
+const (
+	fileExt = ".spool"
+	tmpExt  = ".tmp"
+)
+
+var slog = log.WithComponent("Spool")
+
+// ErrTooLarge is returned when a payload doesn't fit in the spool.
+var ErrTooLarge = errors.New("payload is larger than the spool size")
+
+// Entry is a spooled payload, returned by Peek. It identifies the payload to be removed by Pop or Drop.
+type Entry struct {
+	Payload []byte
+	// Created is the time the payload was spooled.
+	Created time.Time
+	name    string
+}
+
+type entry struct {
+	name    string
+	size    int64
+	created time.Time
+}
+
+// Spool stores payloads on disk, each one in its own file named after its creation time, so they are
+// read in the same order they were pushed, also across restarts. It's bounded by size and age: expired
+// payloads, and the oldest ones when there is no room for a new payload, are dropped.
+// It's safe for concurrent use.
+type Spool struct {
+	lock         sync.Mutex
+	dir          string
+	maxSize      int64
+	maxAge       time.Duration
+	entries      []entry // sorted from the oldest to the newest
+	size         int64
+	droppedBytes int64
+	seq          uint64
+	now          func() time.Time
+}
+
+// New creates a spool in the given folder, loading the payloads spooled by previous executions.
+func New(dir string, maxSize int64, maxAge time.Duration) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create spool folder: %w", err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read spool folder: %w", err)
+	}
+
+	s := &Spool{
+		dir:     dir,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+		now:     time.Now,
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		name := file.Name()
+		// leftovers from an interrupted write
+		if strings.HasSuffix(name, tmpExt) {
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		created, ok := fileTime(name)
+		if !ok {
+			continue
+		}
+		s.entries = append(s.entries, entry{name: name, size: file.Size(), created: created})
+		s.size += file.Size()
+	}
+	sort.Slice(s.entries, func(i, j int) bool {
+		return s.entries[i].name < s.entries[j].name
+	})
+
+	return s, nil
+}
+
+// Push stores a payload at the end of the spool, dropping the oldest payloads if there is no room for it.
+func (s *Spool) Push(payload []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	size := int64(len(payload))
+	if size > s.maxSize {
+		s.droppedBytes += size
+		return fmt.Errorf("%w (%d > %d)", ErrTooLarge, size, s.maxSize)
+	}
+
+	s.dropExpired()
+	for len(s.entries) > 0 && s.size+size > s.maxSize {
+		s.drop(0)
+	}
+
+	now := s.now()
+	s.seq++
+	// zero-padded so the lexicographical order of the files is the creation order
+	name := fmt.Sprintf("%020d-%010d%s", now.UnixNano(), s.seq, fileExt)
+	tmpPath := filepath.Join(s.dir, name+tmpExt)
+	if err := ioutil.WriteFile(tmpPath, payload, 0600); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("cannot write spooled payload: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, name)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("cannot write spooled payload: %w", err)
+	}
+
+	s.entries = append(s.entries, entry{name: name, size: size, created: now})
+	s.size += size
+	return nil
+}
+
+// Peek returns the oldest spooled payload, dropping the expired ones. It returns false when the spool
+// is empty. The payload is kept in the spool until it's passed to Pop or Drop. When it can't be read,
+// the returned entry has no payload but it can still be dropped.
+func (s *Spool) Peek() (Entry, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.dropExpired()
+	if len(s.entries) == 0 {
+		return Entry{}, false, nil
+	}
+	oldest := s.entries[0]
+	e := Entry{Created: oldest.created, name: oldest.name}
+	payload, err := ioutil.ReadFile(filepath.Join(s.dir, oldest.name))
+	if err != nil {
+		return e, true, fmt.Errorf("cannot read spooled payload: %w", err)
+	}
+	e.Payload = payload
+	return e, true, nil
+}
+
+// Pop removes the spooled payload returned by Peek, once it has been submitted. Payloads already
+// removed to make room for new ones are ignored.
+func (s *Spool) Pop(e Entry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if i, ok := s.find(e); ok {
+		s.remove(i)
+	}
+}
+
+// Drop removes the spooled payload returned by Peek, accounting it as dropped. Used for payloads that
+// can't be submitted.
+func (s *Spool) Drop(e Entry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if i, ok := s.find(e); ok {
+		s.drop(i)
+	}
+}
+
+// Depth returns the number of spooled payloads.
+func (s *Spool) Depth() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.entries)
+}
+
+// Bytes returns the disk space used by the spooled payloads.
+func (s *Spool) Bytes() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.size
+}
+
+// DroppedBytes returns the size of the payloads dropped since the spool was created.
+func (s *Spool) DroppedBytes() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.droppedBytes
+}
+
+func (s *Spool) dropExpired() {
+	expiredBefore := s.now().Add(-s.maxAge)
+	for len(s.entries) > 0 && s.entries[0].created.Before(expiredBefore) {
+		s.drop(0)
+	}
+}
+
+// find returns the index of the spooled payload of the entry.
+func (s *Spool) find(e Entry) (int, bool) {
+	i := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].name >= e.name
+	})
+	return i, e.name != "" && i < len(s.entries) && s.entries[i].name == e.name
+}
+
+// drop removes the i-th payload, accounting it as dropped.
+func (s *Spool) drop(i int) {
+	s.droppedBytes += s.entries[i].size
+	s.remove(i)
+}
+
+// remove deletes the i-th payload.
+func (s *Spool) remove(i int) {
+	removed := s.entries[i]
+	if err := os.Remove(filepath.Join(s.dir, removed.name)); err != nil && !os.IsNotExist(err) {
+		slog.WithError(err).WithField("file", removed.name).Warn("Cannot remove spooled payload.")
+	}
+	s.size -= removed.size
+	s.entries = append(s.entries[:i], s.entries[i+1:]...)
+}
+
+// fileTime returns the creation time encoded in a spooled payload file name.
+func fileTime(name string) (time.Time, bool) {
+	if !strings.HasSuffix(name, fileExt) {
+		return time.Time{}, false
+	}
+	parts := strings.SplitN(strings.TrimSuffix(name, fileExt), "-", 2)
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package spool
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool_Order(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir, 1024, time.Hour)
+	require.NoError(t, err)
+
+	// GIVEN some spooled payloads
+	require.NoError(t, s.Push([]byte("1")))
+	require.NoError(t, s.Push([]byte("2")))
+	require.NoError(t, s.Push([]byte("3")))
+	assert.Equal(t, 3, s.Depth())
+
+	// WHEN the first one is submitted
+	e, ok, err := s.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []byte("1"), e.Payload)
+	s.Pop(e)
+
+	// THEN the remaining ones are kept in order after a restart
+	restarted, err := New(dir, 1024, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restarted.Depth())
+	assert.Equal(t, int64(2), restarted.Bytes())
+	for _, expected := range []string{"2", "3"} {
+		e, ok, err = restarted.Peek()
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, []byte(expected), e.Payload)
+		restarted.Pop(e)
+	}
+	_, ok, err = restarted.Peek()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpool_FilePermissions(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir, 1024, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push([]byte("payload")))
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, os.FileMode(0600), files[0].Mode().Perm())
+}
+
+func TestSpool_PopEvictedEntry(t *testing.T) {
+	// GIVEN a spool with room for two payloads
+	s, err := New(t.TempDir(), 10, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, s.Push([]byte("first")))
+	require.NoError(t, s.Push([]byte("secnd")))
+
+	// WHEN the oldest payload is evicted by a push while it's being submitted
+	e, ok, err := s.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, s.Push([]byte("third")))
+	s.Pop(e)
+
+	// THEN the payloads spooled after it are kept
+	assert.Equal(t, 2, s.Depth())
+	for _, expected := range []string{"secnd", "third"} {
+		e, ok, err = s.Peek()
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, []byte(expected), e.Payload)
+		s.Pop(e)
+	}
+	assert.Equal(t, 0, s.Depth())
+}
+
+func TestSpool_MaxSize(t *testing.T) {
+	// GIVEN a spool with room for two payloads
+	s, err := New(t.TempDir(), 10, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, s.Push([]byte("first")))
+	require.NoError(t, s.Push([]byte("secnd")))
+
+	// WHEN a third payload is spooled
+	require.NoError(t, s.Push([]byte("third")))
+
+	// THEN the oldest one is dropped
+	assert.Equal(t, 2, s.Depth())
+	assert.Equal(t, int64(5), s.DroppedBytes())
+	e, ok, err := s.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []byte("secnd"), e.Payload)
+
+	// AND payloads larger than the spool are discarded
+	assert.ErrorIs(t, s.Push([]byte("too large payload")), ErrTooLarge)
+	assert.Equal(t, 2, s.Depth())
+	assert.Equal(t, int64(22), s.DroppedBytes())
+}
+
+func TestSpool_MaxAge(t *testing.T) {
+	s, err := New(t.TempDir(), 1024, time.Hour)
+	require.NoError(t, err)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	// GIVEN a payload spooled two hours ago
+	require.NoError(t, s.Push([]byte("old")))
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, s.Push([]byte("new")))
+
+	// WHEN the spool is read
+	e, ok, err := s.Peek()
+
+	// THEN the expired payload is dropped
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []byte("new"), e.Payload)
+	assert.Equal(t, now, e.Created)
+	assert.Equal(t, 1, s.Depth())
+	assert.Equal(t, int64(3), s.DroppedBytes())
+}
@@ -10,6 +10,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/newrelic/infrastructure-agent/pkg/backend/spool"
 )
 
 const (
@@ -70,6 +72,10 @@ type Config struct {
 	// MaxEntitiesPerBatch limits the total of metrics to queue
 	// If zero, DefaultMaxEntitiesPerBatch is used (1000 entities).
 	MaxEntitiesPerBatch int
+	// Spool stores the requests that couldn't be submitted before the
+	// HarvestTimeout, to send them again once a request is accepted. If nil,
+	// the data of those requests is dropped.
+	Spool *spool.Spool
 }
 
 // ConfigAPIKey sets the Config's APIKey which is required and refers to your
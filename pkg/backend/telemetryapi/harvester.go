@@ -37,6 +37,7 @@ type Harvester struct {
 	requestsQueue     chan request
 	metricBatch       metricBatchHandler
 	contextCancel     context.CancelFunc
+	replaying         int32 // set while the spooled requests are being replayed
 }
 
 const (
@@ -128,7 +129,10 @@ func (h *Harvester) Start() {
 					wlog.Debug("Shutting down worker.")
 					return
 				case req := <-h.requestsQueue:
-					harvestRequest(req, &h.config)
+					// spooled requests are only replayed once the backend accepts data again
+					if harvestRequest(req, &h.config) {
+						h.replaySpoolAfterSend()
+					}
 				}
 			}
 		}(i)
@@ -392,7 +396,9 @@ func (h *Harvester) swapOutSpans(ctx context.Context) []request {
 	return reqs
 }
 
-func harvestRequest(req request, cfg *Config) {
+// harvestRequest posts the request, retrying it until it's accepted, rejected or its context is done. It
+// returns whether the request was accepted by the backend.
+func harvestRequest(req request, cfg *Config) bool {
 	var attempts int
 	for {
 		cfg.logDebug(map[string]interface{}{
@@ -426,7 +432,7 @@ func harvestRequest(req request, cfg *Config) {
 		}
 		retry, backoff := resp.needsRetry(cfg, attempts)
 		if !retry {
-			return
+			return resp.err == nil
 		}
 
 		tmr := time.NewTimer(backoff)
@@ -435,7 +441,8 @@ func harvestRequest(req request, cfg *Config) {
 			break
 		case <-req.Request.Context().Done():
 			tmr.Stop()
-			return
+			spoolRequest(req, cfg)
+			return false
 		}
 		attempts++
 
@@ -461,7 +468,7 @@ func (h *Harvester) HarvestNow(ct context.Context) {
 	reqs = append(reqs, h.swapOutSpans(ctx)...)
 	reqs = append(reqs, h.swapOutBatchMetrics(ctx)...)
 
-	for _, req := range reqs {
+	for i, req := range reqs {
 		h.requestsQueue <- req
 		if err := ctx.Err(); err != nil {
 			// NOTE: It is possible that the context was
@@ -474,6 +481,9 @@ func (h *Harvester) HarvestNow(ct context.Context) {
 				"message":       "dropping data",
 				"context-error": err.Error(),
 			})
+			for _, notSent := range reqs[i+1:] {
+				spoolRequest(notSent, &h.config)
+			}
 			return
 		}
 	}
@@ -0,0 +1,140 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetryapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/newrelic/infrastructure-agent/pkg/backend/spool"
+)
+
+// spooledRequest is the on-disk representation of a request that couldn't be submitted. The body is
+// kept as it was sent, so the original timestamps of its data are preserved.
+type spooledRequest struct {
+	URL string `json:"url"`
+	// Header doesn't include the API key, which is set again when the request is replayed.
+	Header         http.Header `json:"header"`
+	CompressedBody []byte      `json:"compressedBody"`
+}
+
+// ConfigSpool sets the Config's Spool, which stores the requests that couldn't be submitted to send them
+// again once a request is accepted.
+func ConfigSpool(s *spool.Spool) func(*Config) {
+	return func(cfg *Config) {
+		cfg.Spool = s
+	}
+}
+
+// spoolRequest stores a request that couldn't be submitted, if the spool is enabled.
+func spoolRequest(req request, cfg *Config) {
+	if cfg.Spool == nil {
+		return
+	}
+	header := req.Request.Header.Clone()
+	header.Del("Api-Key")
+	payload, err := json.Marshal(spooledRequest{
+		URL:            req.Request.URL.String(),
+		Header:         header,
+		CompressedBody: req.compressedBody,
+	})
+	if err == nil {
+		err = cfg.Spool.Push(payload)
+	}
+	if err != nil {
+		cfg.logError(map[string]interface{}{
+			"err":     err.Error(),
+			"message": "cannot spool request, dropping data",
+		})
+		return
+	}
+	cfg.logDebug(map[string]interface{}{
+		"event":          "request spooled",
+		"url":            req.Request.URL.String(),
+		"spooled-length": len(req.compressedBody),
+	})
+}
+
+// replaySpoolAfterSend replays the spooled requests after a request has been accepted, bounded by the
+// harvest timeout.
+func (h *Harvester) replaySpoolAfterSend() {
+	if h.config.Spool == nil || h.config.Spool.Depth() == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(h.config.Context, h.config.HarvestTimeout)
+	defer cancel()
+	h.replaySpool(ctx)
+}
+
+// replaySpool submits the spooled requests, from the oldest to the newest, until the spool is empty, a
+// submission fails or the context is done. Only a replay runs at a time.
+func (h *Harvester) replaySpool(ctx context.Context) {
+	if h.config.Spool == nil || !atomic.CompareAndSwapInt32(&h.replaying, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&h.replaying, 0)
+
+	for ctx.Err() == nil {
+		entry, ok, err := h.config.Spool.Peek()
+		if err == nil && !ok {
+			return
+		}
+		var req *http.Request
+		if err == nil {
+			req, err = newSpooledHTTPRequest(ctx, entry.Payload, h.config.APIKey)
+		}
+		if err != nil {
+			h.config.logError(map[string]interface{}{
+				"err":     err.Error(),
+				"message": "dropping unreadable spooled request",
+			})
+			h.config.Spool.Drop(entry)
+			continue
+		}
+
+		resp := postData(req, h.config.Client)
+		if retry, _ := resp.needsRetry(&h.config, 0); retry {
+			// the backend is still unreachable, the request is retried after the next accepted one
+			h.config.logDebug(map[string]interface{}{
+				"event":  "spooled request replay failed",
+				"status": resp.statusCode,
+			})
+			return
+		}
+		if resp.err != nil {
+			h.config.logError(map[string]interface{}{
+				"err":     resp.err.Error(),
+				"message": "spooled request not accepted, dropping data",
+			})
+			h.config.Spool.Drop(entry)
+			continue
+		}
+		h.config.Spool.Pop(entry)
+		h.config.logDebug(map[string]interface{}{
+			"event":            "spooled request replayed",
+			"url":              req.URL.String(),
+			"spooled-requests": h.config.Spool.Depth(),
+		})
+	}
+}
+
+func newSpooledHTTPRequest(ctx context.Context, payload []byte, apiKey string) (*http.Request, error) {
+	var sr spooledRequest
+	if err := json.Unmarshal(payload, &sr); err != nil {
+		return nil, fmt.Errorf("cannot decode spooled request: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", sr.URL, bytes.NewReader(sr.CompressedBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	for k, v := range sr.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Api-Key", apiKey)
+	return req, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetryapi
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/pkg/backend/spool"
+	"github.com/newrelic/infrastructure-agent/pkg/backend/telemetryapi/internal"
+)
+
+func TestHarvester_SpoolReplay(t *testing.T) {
+	var lock sync.Mutex
+	status := http.StatusServiceUnavailable
+	var posted []*http.Request
+	var postedBodies []string
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if status == http.StatusAccepted {
+			compressed, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			body, err := internal.Uncompress(compressed)
+			require.NoError(t, err)
+			posted = append(posted, req)
+			postedBodies = append(postedBodies, string(body))
+		}
+		return emptyResponse(status), nil
+	})
+
+	s, err := spool.New(t.TempDir(), 1024*1024, time.Hour)
+	require.NoError(t, err)
+	h, err := NewHarvester(configTesting, ConfigSpool(s), func(cfg *Config) {
+		cfg.HarvestTimeout = 100 * time.Millisecond
+		cfg.Client.Transport = rt
+	})
+	require.NoError(t, err)
+
+	// GIVEN metrics that couldn't be submitted before the harvest timeout
+	require.NoError(t, h.RecordInfraMetrics(Attributes{nrEntityID: "123"}, []Metric{
+		Gauge{Name: "g", Value: 1, Timestamp: time.Unix(1417136460, 0)},
+	}))
+	h.HarvestNow(context.Background())
+	require.Eventually(t, func() bool { return s.Depth() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// WHEN the backend accepts data again
+	lock.Lock()
+	status = http.StatusAccepted
+	lock.Unlock()
+	require.NoError(t, h.RecordInfraMetrics(Attributes{nrEntityID: "456"}, []Metric{
+		Gauge{Name: "g", Value: 2, Timestamp: time.Unix(1417136520, 0)},
+	}))
+	h.HarvestNow(context.Background())
+
+	// THEN the spooled request is submitted with its original data and headers, after the new data
+	require.Eventually(t, func() bool { return s.Depth() == 0 }, 5*time.Second, 10*time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, posted, 2)
+	assert.Equal(t, "456", posted[0].Header.Get("X-NRI-Entity-Ids"))
+	assert.Equal(t, "api-key", posted[1].Header.Get("Api-Key"))
+	assert.Equal(t, "123", posted[1].Header.Get("X-NRI-Entity-Ids"))
+	assert.Equal(t, "gzip", posted[1].Header.Get("Content-Encoding"))
+	assert.JSONEq(t,
+		`[{"common":{"attributes":{"nr.entity.id":"123"}},"metrics":[{"name":"g","type":"gauge","value":1,"timestamp":1417136460000}]}]`,
+		postedBodies[1])
+}
+
+func TestHarvester_SpoolReplay_NotOnFailedSend(t *testing.T) {
+	var spooledPosts int32
+	s, err := spool.New(t.TempDir(), 1024*1024, time.Hour)
+	require.NoError(t, err)
+	h, err := NewHarvester(configTesting, ConfigSpool(s), func(cfg *Config) {
+		cfg.HarvestTimeout = 100 * time.Millisecond
+		cfg.Client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "localhost" {
+				atomic.AddInt32(&spooledPosts, 1)
+			}
+			return emptyResponse(http.StatusServiceUnavailable), nil
+		})
+	})
+	require.NoError(t, err)
+
+	// GIVEN a spooled request
+	req, err := createRequest(context.Background(), []byte(`[]`), "api-key", "http://localhost/metric", "agent")
+	require.NoError(t, err)
+	spoolRequest(req, &h.config)
+
+	// WHEN new data can't be submitted
+	require.NoError(t, h.RecordInfraMetrics(Attributes{nrEntityID: "123"}, []Metric{
+		Gauge{Name: "g", Value: 1, Timestamp: time.Unix(1417136460, 0)},
+	}))
+	h.HarvestNow(context.Background())
+
+	// THEN the spooled request isn't replayed, only the new data is spooled
+	require.Eventually(t, func() bool { return s.Depth() == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Zero(t, atomic.LoadInt32(&spooledPosts))
+}
+
+func TestHarvester_SpoolReplay_NotAccepted(t *testing.T) {
+	s, err := spool.New(t.TempDir(), 1024*1024, time.Hour)
+	require.NoError(t, err)
+	h, err := NewHarvester(configTesting, ConfigSpool(s), func(cfg *Config) {
+		cfg.Client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// only the spooled request is rejected
+			if req.URL.Host == "localhost" {
+				return emptyResponse(http.StatusBadRequest), nil
+			}
+			return emptyResponse(http.StatusAccepted), nil
+		})
+	})
+	require.NoError(t, err)
+
+	// GIVEN a spooled request
+	req, err := createRequest(context.Background(), []byte(`[]`), "api-key", "http://localhost/metric", "agent")
+	require.NoError(t, err)
+	spoolRequest(req, &h.config)
+	require.Equal(t, 1, s.Depth())
+
+	// WHEN it's rejected by the backend after new data is accepted
+	require.NoError(t, h.RecordInfraMetrics(Attributes{nrEntityID: "123"}, []Metric{
+		Gauge{Name: "g", Value: 1, Timestamp: time.Unix(1417136460, 0)},
+	}))
+	h.HarvestNow(context.Background())
+
+	// THEN it's dropped, as it would never be accepted
+	require.Eventually(t, func() bool { return s.Depth() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.NotZero(t, s.DroppedBytes())
+}
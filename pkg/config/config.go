@@ -79,6 +79,12 @@ var clog = log.WithComponent("Configuration")
 // IncludeMetricsMap configuration type to Map include_matching_metrics setting env var
 type IncludeMetricsMap map[string][]string
 
+// Attributes the processes can be grouped by in aggregation mode, besides the command name.
+const (
+	ProcessAggregationByUser      = "user"
+	ProcessAggregationByContainer = "container"
+)
+
 // LogFilters configuration specifies which log entries should be included/excluded.
 type LogFilters map[string][]interface{}
 
@@ -424,6 +430,25 @@ type Config struct {
 	// Public: No
 	DMSubmissionPeriod int `yaml:"dm_submission_period" envconfig:"dm_submission_period" public:"false"`
 
+	// DMSpoolEnabled stores on disk, under the agent_dir folder, the dimensional metrics submissions that failed,
+	// so integration metrics aren't lost on long outages or agent restarts. Spooled submissions are sent again once
+	// a new submission is accepted, keeping the original timestamps of their metrics.
+	// Default: False
+	// Public: Yes
+	DMSpoolEnabled bool `yaml:"dm_spool_enabled" envconfig:"dm_spool_enabled"`
+
+	// DMSpoolMaxSizeMB limits the disk space used by the dimensional metrics spool. When the limit is reached, the
+	// oldest submissions are dropped to make room for the new ones.
+	// Default: 100
+	// Public: Yes
+	DMSpoolMaxSizeMB int `yaml:"dm_spool_max_size_mb" envconfig:"dm_spool_max_size_mb"`
+
+	// DMSpoolMaxAge defines how long a submission is kept in the dimensional metrics spool before being dropped
+	// as stale. Valid time units are: "s" (seconds), "m" (minutes), "h" (hour).
+	// Default: 24h
+	// Public: Yes
+	DMSpoolMaxAge string `yaml:"dm_spool_max_age" envconfig:"dm_spool_max_age"`
+
 	// CustomSupportedFileSystems List of filesystems types the agent supports. This value should be a subset of the
 	// default list, items that are not in the default list will be discarded.
 	// Default: Empty
@@ -488,6 +513,61 @@ type Config struct {
 	// Public: Yes
 	DpkgRefreshSec int64 `yaml:"dpkg_interval_sec" envconfig:"dpkg_interval_sec"`
 
+	// ApkRefreshSec Sampling period / interval in seconds for Apk plugin. Set as value -1 for disabling it.
+	// 30 is the minimum value. Only activated in root or privileged modes and on Alpine Linux.
+	// Default: 30
+	// Public: Yes
+	ApkRefreshSec int64 `yaml:"apk_interval_sec" envconfig:"apk_interval_sec"`
+
+	// PacmanRefreshSec Sampling period / interval in seconds for Pacman plugin. Set as value -1 for disabling it.
+	// 30 is the minimum value. Only activated in root or privileged modes and on Arch based distros.
+	// Default: 30
+	// Public: Yes
+	PacmanRefreshSec int64 `yaml:"pacman_interval_sec" envconfig:"pacman_interval_sec"`
+
+	// SnapRefreshSec Sampling period / interval in seconds for Snap plugin. Set as value -1 for disabling it.
+	// 30 is the minimum value. Only activated in root or privileged modes and when snapd is installed.
+	// Default: 30
+	// Public: Yes
+	SnapRefreshSec int64 `yaml:"snap_interval_sec" envconfig:"snap_interval_sec"`
+
+	// EnableLanguagePackages enables the inventory of the packages installed by the language package managers,
+	// reported under the packages/python, packages/npm, packages/gem and packages/go plugins. Only supported on
+	// Linux.
+	// Default: False
+	// Public: Yes
+	EnableLanguagePackages bool `yaml:"enable_language_packages" envconfig:"enable_language_packages"`
+
+	// LanguagePackagesRefreshSec Sampling period / interval in seconds for the language packages plugins. Set as
+	// value -1 for disabling them. 30 is the minimum value.
+	// Default: 300
+	// Public: Yes
+	LanguagePackagesRefreshSec int64 `yaml:"language_packages_interval_sec" envconfig:"language_packages_interval_sec"`
+
+	// LanguagePackagesPythonPaths List of Python site-packages directories searched for "*.dist-info" package
+	// metadata. Glob patterns are supported.
+	// Default: /usr/lib/python3/dist-packages, /usr/lib/python3*/site-packages, /usr/local/lib/python3*/dist-packages, /usr/local/lib/python3*/site-packages
+	// Public: Yes
+	LanguagePackagesPythonPaths []string `yaml:"language_packages_python_paths" envconfig:"language_packages_python_paths"`
+
+	// LanguagePackagesNpmPaths List of global node_modules directories searched for npm packages. Glob patterns
+	// are supported.
+	// Default: /usr/lib/node_modules, /usr/local/lib/node_modules
+	// Public: Yes
+	LanguagePackagesNpmPaths []string `yaml:"language_packages_npm_paths" envconfig:"language_packages_npm_paths"`
+
+	// LanguagePackagesGemPaths List of directories searched for Ruby gem specifications. Glob patterns are
+	// supported.
+	// Default: /usr/lib/ruby/gems/*/specifications, /usr/local/lib/ruby/gems/*/specifications, /var/lib/gems/*/specifications
+	// Public: Yes
+	LanguagePackagesGemPaths []string `yaml:"language_packages_gem_paths" envconfig:"language_packages_gem_paths"`
+
+	// LanguagePackagesGoPaths List of directories whose executables are inspected for the Go modules they were
+	// built with. Glob patterns are supported.
+	// Default: /usr/local/bin
+	// Public: Yes
+	LanguagePackagesGoPaths []string `yaml:"language_packages_go_paths" envconfig:"language_packages_go_paths"`
+
 	// DaemontoolsRefreshSec Sampling period / interval in seconds for Daemontools plugin. Set as value -1 for
 	// disabling it. 10 is the minimum value
 	// Default: 15
@@ -577,6 +657,27 @@ type Config struct {
 	// Public: Yes
 	SshdConfigRefreshSec int64 `yaml:"sshd_config_refresh_sec" envconfig:"sshd_config_refresh_sec"`
 
+	// ListeningPortsRefreshSec Sampling period / interval in seconds for the ListeningPorts plugin, reporting the
+	// TCP and UDP ports the host listens on. Set as value -1 for disabling it. 30 is the minimum value. Only
+	// activated in root or privileged modes on Linux.
+	// Default: 60
+	// Public: Yes
+	ListeningPortsRefreshSec int64 `yaml:"listening_ports_interval_sec" envconfig:"listening_ports_interval_sec"`
+
+	// AccountsRefreshSec Sampling period / interval in seconds for the Accounts plugin, reporting the local users
+	// and groups, their password aging when the shadow file is readable, and the sudoers rules. Set as value -1
+	// for disabling it. 30 is the minimum value. Only supported on Linux.
+	// Default: 60
+	// Public: Yes
+	AccountsRefreshSec int64 `yaml:"accounts_interval_sec" envconfig:"accounts_interval_sec"`
+
+	// ScheduledJobsRefreshSec Sampling period / interval in seconds for the ScheduledJobs plugin, reporting the
+	// jobs of the system and user crontabs, and the systemd timers. Set as value -1 for disabling it. 30 is the
+	// minimum value. Only supported on Linux. The user crontabs are only reported in root or privileged modes.
+	// Default: 60
+	// Public: Yes
+	ScheduledJobsRefreshSec int64 `yaml:"scheduled_jobs_interval_sec" envconfig:"scheduled_jobs_interval_sec"`
+
 	// WindowsServicesRefreshSec Sampling period / interval in seconds for WindowsServices plugin. Set as value -1
 	// for disabling it. 10 is the minimum value.
 	// Default: 30
@@ -667,6 +768,25 @@ type Config struct {
 	// Public: No
 	BatchQueueDepth int `yaml:"batch_queue_depth" envconfig:"batch_queue_depth" public:"false"` // See event_sender.go
 
+	// EventSpoolEnabled stores on disk, under the agent_dir folder, the event batches that couldn't be sent to
+	// metrics digest, so they aren't lost while the backend is unreachable. Spooled batches are sent again, in the
+	// same order they were stored, once the connectivity returns.
+	// Default: False
+	// Public: Yes
+	EventSpoolEnabled bool `yaml:"event_spool_enabled" envconfig:"event_spool_enabled"`
+
+	// EventSpoolMaxSizeMB limits the disk space used by the events spool. When the limit is reached, the oldest
+	// batches are dropped to make room for the new ones.
+	// Default: 100
+	// Public: Yes
+	EventSpoolMaxSizeMB int `yaml:"event_spool_max_size_mb" envconfig:"event_spool_max_size_mb"`
+
+	// EventSpoolMaxAge defines how long a batch is kept in the events spool before being dropped. Valid time units
+	// are: "s" (seconds), "m" (minutes), "h" (hour).
+	// Default: 24h
+	// Public: Yes
+	EventSpoolMaxAge string `yaml:"event_spool_max_age" envconfig:"event_spool_max_age"`
+
 	// InventoryQueueLen sets the inventory processing queue size. Zero value makes inventory processing synchronous (blocking call).
 	// Default: 0
 	// Public: Yes
@@ -839,6 +959,13 @@ type Config struct {
 	// Public: Yes
 	HTTPServerPort int `yaml:"http_server_port" envconfig:"http_server_port"`
 
+	// HTTPServerPrometheusEntity Entity name for the Prometheus text format payloads received by the http
+	// server on /v1/data/prometheus. Payloads can override it with the "entity" query parameter. When empty,
+	// the metrics are attached to the host entity.
+	// Default: Empty
+	// Public: Yes
+	HTTPServerPrometheusEntity string `yaml:"http_server_prometheus_entity" envconfig:"http_server_prometheus_entity"`
+
 	// HTTPServerCert Path to a PEM-encoded certificate to listen for integration payloads over HTTPs.
 	HTTPServerCert string `yaml:"http_server_cert" envconfig:"http_server_cert"`
 
@@ -859,6 +986,30 @@ type Config struct {
 	// Public: Yes
 	TCPServerPort int `yaml:"tcp_server_port" envconfig:"tcp_server_port"`
 
+	// TCPServerHost Set the address the tcp server listens on. When empty, it listens on all interfaces. Use
+	// localhost to only accept local connections.
+	// Default: Empty
+	// Public: Yes
+	TCPServerHost string `yaml:"tcp_server_host" envconfig:"tcp_server_host"`
+
+	// TCPServerUnixSocket Path of a unix domain socket for the tcp server to listen on, instead of the
+	// tcp_server_host and tcp_server_port.
+	// Default: Empty
+	// Public: Yes
+	TCPServerUnixSocket string `yaml:"tcp_server_unix_socket" envconfig:"tcp_server_unix_socket"`
+
+	// TCPServerMaxLineSize Maximum size in bytes of a payload received by the tcp server. Connections
+	// sending longer payloads are closed.
+	// Default: 4194304
+	// Public: Yes
+	TCPServerMaxLineSize int `yaml:"tcp_server_max_line_size" envconfig:"tcp_server_max_line_size"`
+
+	// TCPServerRateLimit Maximum number of payloads per second accepted on each tcp server connection.
+	// Payloads exceeding the limit are delayed. Zero means no limit.
+	// Default: 0
+	// Public: Yes
+	TCPServerRateLimit int `yaml:"tcp_server_rate_limit" envconfig:"tcp_server_rate_limit"`
+
 	// StatusServerEnabled will listen into TCP port (status_server_port) to serve status requests.
 	// Default: False
 	// Public: Yes
@@ -869,11 +1020,30 @@ type Config struct {
 	// Public: Yes
 	StatusServerPort int `yaml:"status_server_port" envconfig:"status_server_port"`
 
+	// ControlServerEnabled serves the agent control API, used by the newrelic-infra-ctl commands to manage the
+	// integrations, reload the configuration and change the log level, on the unix socket set by
+	// control_server_socket. Only the user running the agent can access the socket.
+	// Default: False
+	// Public: Yes
+	ControlServerEnabled bool `yaml:"control_server_enabled" envconfig:"control_server_enabled"`
+
+	// ControlServerSocket Set the path of the control API unix socket.
+	// Default: /var/run/newrelic-infra/control.sock
+	// Public: Yes
+	ControlServerSocket string `yaml:"control_server_socket" envconfig:"control_server_socket"`
+
 	// StatusServerPort Set the port for status server.
 	// Default: IdentityURL, CommandChannelURL, MetricsIngestURL, InventoryIngestURL
 	// Public: Yes
 	StatusEndpoints []string `yaml:"status_endpoints" envconfig:"status_endpoints"`
 
+	// WatchConfigFile reloads the agent configuration when its file changes. Options that can't be
+	// applied on a running agent are logged as requiring a restart. Reloading can also be requested
+	// by sending a SIGHUP signal to the agent or through the newrelic-infra-ctl reload command.
+	// Default: False
+	// Public: Yes
+	WatchConfigFile bool `yaml:"watch_config_file" envconfig:"watch_config_file"`
+
 	// AppDataDir This option is only for Windows. It defines the path to store data in a different path than the
 	// program files directory.
 	// - %AppDir%/data: used for storing the delta data.
@@ -1083,6 +1253,44 @@ type Config struct {
 	// Public: Yes
 	DetailedNFS bool `yaml:"detailed_nfs" envconfig:"detailed_nfs"`
 
+	// MetricsCgroupSampleRate Sample rate of cgroup Samples in seconds. Minimum value is 5. If value is -1 then
+	// the sampler is disabled. Only supported on Linux, for both cgroup v1 and v2 hierarchies.
+	// Default: -1
+	// Public: Yes
+	MetricsCgroupSampleRate int `yaml:"metrics_cgroup_sample_rate" envconfig:"metrics_cgroup_sample_rate"`
+
+	// CgroupIncludePaths List of cgroup path patterns to sample, relative to the hierarchy root (e.g.
+	// "/system.slice/*"). A pattern ending in "/**" matches a cgroup and all its descendants. When empty, all
+	// the cgroups are sampled.
+	// Default: Empty
+	// Public: Yes
+	CgroupIncludePaths []string `yaml:"cgroup_include_paths" envconfig:"cgroup_include_paths"`
+
+	// CgroupExcludePaths List of cgroup path patterns not to sample, with the same format as cgroup_include_paths.
+	// Exclusions take precedence over inclusions.
+	// Default: Empty
+	// Public: Yes
+	CgroupExcludePaths []string `yaml:"cgroup_exclude_paths" envconfig:"cgroup_exclude_paths"`
+
+	// MetricsNetstatSampleRate Sample rate of netstat Samples in seconds, reporting the kernel TCP and UDP protocol
+	// statistics. Minimum value is 10. If value is -1 then the sampler is disabled. Only supported on Linux.
+	// Default: -1
+	// Public: Yes
+	MetricsNetstatSampleRate int `yaml:"metrics_netstat_sample_rate" envconfig:"metrics_netstat_sample_rate"`
+
+	// MetricsSensorSampleRate Sample rate of Sensor Samples in seconds, reporting the temperature, fan, voltage
+	// and power sensors from hwmon and the thermal zones. Minimum value is 5. If value is -1 then the sampler is
+	// disabled. Only supported on Linux.
+	// Default: -1
+	// Public: Yes
+	MetricsSensorSampleRate int `yaml:"metrics_sensor_sample_rate" envconfig:"metrics_sensor_sample_rate"`
+
+	// EnableCPUCoreMetrics enables the CPUCoreSample, reported for each CPU core at the metrics_system_sample_rate,
+	// and the context switches and interrupts rates of the SystemSample. Only supported on Linux.
+	// Default: False
+	// Public: Yes
+	EnableCPUCoreMetrics bool `yaml:"enable_cpu_core_metrics" envconfig:"enable_cpu_core_metrics"`
+
 	// Internals
 
 	// concurrency support
@@ -1097,6 +1305,20 @@ type Config struct {
 	// Public: Yes
 	EnableProcessMetrics *bool `yaml:"enable_process_metrics" envconfig:"enable_process_metrics"`
 
+	// ProcessAggregation When enabled, the processes are reported as a ProcessGroupSample per command name, with the
+	// number of processes, the sum of their CPU, memory and IO metrics, and the minimum and maximum CPU and memory,
+	// instead of a ProcessSample per process. The process metrics matchers are applied to the groups, while the
+	// top-N selection is ignored.
+	// Default: False
+	// Public: Yes
+	ProcessAggregation bool `yaml:"process_aggregation" envconfig:"process_aggregation"`
+
+	// ProcessAggregationBy Additional attributes the processes are grouped by in aggregation mode, besides the
+	// command name. Valid values: user, container.
+	// Default: Empty
+	// Public: Yes
+	ProcessAggregationBy []string `yaml:"process_aggregation_by" envconfig:"process_aggregation_by"`
+
 	// IncludeMetricsMatchers Configuration of the metrics matchers that determine which metric data should the agent
 	// send to the New Relic backend.
 	// If no configuration is defined, the previous behaviour is maintained, i.e., every metric data captured is sent.
@@ -1107,6 +1329,28 @@ type Config struct {
 	// Public: Yes
 	IncludeMetricsMatchers IncludeMetricsMap `yaml:"include_matching_metrics" envconfig:"include_matching_metrics"`
 
+	// ExcludeMetricsMatchers Configuration of the metrics matchers that determine which metric data the agent must
+	// not send, with the same format as include_matching_metrics. Data matching any of them is dropped, even when
+	// matched by include_matching_metrics.
+	// Like the include matchers, it ONLY APPLIES to metric data related to processes.
+	// Default: none
+	// Public: Yes
+	ExcludeMetricsMatchers IncludeMetricsMap `yaml:"exclude_matching_metrics" envconfig:"exclude_matching_metrics"`
+
+	// ProcessTopNByCPU When greater than 0, the N processes with the highest CPU usage are sent along with the ones
+	// matched by include_matching_metrics, flagged with the topProcess attribute. The exclude_matching_metrics
+	// matchers still apply to them.
+	// Default: 0
+	// Public: Yes
+	ProcessTopNByCPU int `yaml:"process_top_n_by_cpu" envconfig:"process_top_n_by_cpu"`
+
+	// ProcessTopNByMemory When greater than 0, the N processes with the highest resident memory are sent along with
+	// the ones matched by include_matching_metrics, flagged with the topProcess attribute. The
+	// exclude_matching_metrics matchers still apply to them.
+	// Default: 0
+	// Public: Yes
+	ProcessTopNByMemory int `yaml:"process_top_n_by_memory" envconfig:"process_top_n_by_memory"`
+
 	// AgentMetricsEndpoint Set the endpoint (host:port) for the HTTP server the agent will use to server OpenMetrics
 	// if empty the server will be not spawned
 	// Default: empty
@@ -1136,6 +1380,9 @@ type Config struct {
 	// "pool: []string" list of ntp servers (Default: [])
 	// "interval: int" interval in minutes to check ntp servers  (Default: 15)
 	// "timeout: int" ntp request timeout value in seconds (Default: 10)
+	// "local: boolean" read the clock synchronization status, stratum and offset from the local chrony daemon or
+	// the kernel, falling back to the pool servers, if any, when not available or not synchronized. Only
+	// supported on Linux (Default: false)
 	// Default: none
 	// Public: Yes
 	NtpMetrics NtpConfig `yaml:"ntp_metrics" envconfig:"ntp_metrics"`
@@ -1249,6 +1496,7 @@ type NtpConfig struct {
 	Enabled  bool     `yaml:"enabled" envconfig:"enabled"`
 	Interval uint     `yaml:"interval" envconfig:"interval"`
 	Timeout  uint     `yaml:"timeout" envconfig:"timeout"`
+	Local    bool     `yaml:"local" envconfig:"local"`
 }
 
 func NewNtpConfig() NtpConfig {
@@ -1558,6 +1806,21 @@ func (c *Config) PublicFields() (map[string]string, error) {
 	return result, nil
 }
 
+// ResolveConfigFile returns the configuration file read by LoadConfig for the provided one: itself when
+// it exists, otherwise the first existing default configuration file. Empty if none exists.
+func ResolveConfigFile(configFile string) string {
+	var filesToCheck []string
+	if configFile != "" {
+		filesToCheck = append(filesToCheck, configFile)
+	}
+	for _, f := range append(filesToCheck, defaultConfigFiles...) {
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return ""
+}
+
 func LoadConfig(configFile string) (cfg *Config, err error) {
 	var filesToCheck []string
 	if configFile != "" {
@@ -1640,7 +1903,9 @@ func NewConfig() *Config {
 		HTTPServerHost:                defaultHTTPServerHost,
 		HTTPServerPort:                defaultHTTPServerPort,
 		TCPServerPort:                 defaultTCPServerPort,
-		StatusServerPort:              defaultStatusServerPort,
+		TCPServerMaxLineSize:          defaultTCPServerMaxLineSize,
+		StatusServerPort:              DefaultStatusServerPort,
+		ControlServerSocket:           DefaultControlServerSocket,
 		DockerApiVersion:              DefaultDockerApiVersion,
 		FingerprintUpdateFreqSec:      defaultFingerprintUpdateFreqSec,
 		CloudMetadataExpiryInSec:      defaultCloudMetadataExpiryInSec,
@@ -1675,12 +1940,23 @@ func NewConfig() *Config {
 		FilesConfigOn:               defaultFilesConfigOn,
 		PayloadCompressionLevel:     defaultPayloadCompressionLevel,
 		EnableWinUpdatePlugin:       defaultWinUpdatePlugin,
+		LanguagePackagesPythonPaths: defaultLanguagePackagesPythonPaths,
+		LanguagePackagesNpmPaths:    defaultLanguagePackagesNpmPaths,
+		LanguagePackagesGemPaths:    defaultLanguagePackagesGemPaths,
+		LanguagePackagesGoPaths:     defaultLanguagePackagesGoPaths,
 		LogToStdout:                 defaultLogToStdout,
 		IpData:                      defaultIpData,
 		ContainerMetadataCacheLimit: DefaultContainerCacheMetadataLimit,
 		PartitionsTTL:               defaultPartitionsTTL,
 		StartupConnectionTimeout:    defaultStartupConnectionTimeout,
+		EventSpoolMaxSizeMB:         defaultEventSpoolMaxSizeMB,
+		EventSpoolMaxAge:            defaultEventSpoolMaxAge,
+		DMSpoolMaxSizeMB:            defaultDMSpoolMaxSizeMB,
+		DMSpoolMaxAge:               defaultDMSpoolMaxAge,
 		MetricsNFSSampleRate:        DefaultMetricsNFSSampleRate,
+		MetricsCgroupSampleRate:     DefaultMetricsCgroupSampleRate,
+		MetricsNetstatSampleRate:    DefaultMetricsNetstatSampleRate,
+		MetricsSensorSampleRate:     DefaultMetricsSensorSampleRate,
 		SmartVerboseModeEntryLimit:  DefaultSmartVerboseModeEntryLimit,
 		DefaultIntegrationsTempDir:  defaultIntegrationsTempDir,
 		IncludeMetricsMatchers:      defaultMetricsMatcherConfig,
@@ -2057,11 +2333,26 @@ func NormalizeConfig(cfg *Config, cfgMetadata config_loader.YAMLMetadata) (err e
 	}
 	nlog.WithField("MetricsNetworkSampleRate", cfg.MetricsNetworkSampleRate).Debug("Metrics Network Sample Rate.")
 
+	if cfg.MetricsNetstatSampleRate < FREQ_INTERVAL_FLOOR_NETWORK_METRICS && cfg.MetricsNetstatSampleRate > FREQ_DISABLE_SAMPLING {
+		cfg.MetricsNetstatSampleRate = FREQ_INTERVAL_FLOOR_NETWORK_METRICS
+	}
+	nlog.WithField("MetricsNetstatSampleRate", cfg.MetricsNetstatSampleRate).Debug("Metrics Netstat Sample Rate.")
+
 	if cfg.MetricsProcessSampleRate < FREQ_INTERVAL_FLOOR_PROCESS_METRICS && cfg.MetricsProcessSampleRate > FREQ_DISABLE_SAMPLING {
 		cfg.MetricsProcessSampleRate = FREQ_INTERVAL_FLOOR_PROCESS_METRICS
 	}
 	nlog.WithField("MetricsProcessSampleRate", cfg.MetricsProcessSampleRate).Debug("Metrics Process Sample Rate.")
 
+	if cfg.MetricsCgroupSampleRate < FREQ_INTERVAL_FLOOR_STORAGE_METRICS && cfg.MetricsCgroupSampleRate > FREQ_DISABLE_SAMPLING {
+		cfg.MetricsCgroupSampleRate = FREQ_INTERVAL_FLOOR_STORAGE_METRICS
+	}
+	nlog.WithField("MetricsCgroupSampleRate", cfg.MetricsCgroupSampleRate).Debug("Metrics Cgroup Sample Rate.")
+
+	if cfg.MetricsSensorSampleRate < FREQ_INTERVAL_FLOOR_SYSTEM_METRICS && cfg.MetricsSensorSampleRate > FREQ_DISABLE_SAMPLING {
+		cfg.MetricsSensorSampleRate = FREQ_INTERVAL_FLOOR_SYSTEM_METRICS
+	}
+	nlog.WithField("MetricsSensorSampleRate", cfg.MetricsSensorSampleRate).Debug("Metrics Sensor Sample Rate.")
+
 	nlog.WithField("FilesConfigOn", cfg.FilesConfigOn).Debug("Configuration file monitoring.")
 
 	if cfg.NetworkInterfaceFilters == nil || len(cfg.NetworkInterfaceFilters) == 0 {
@@ -2091,6 +2382,40 @@ func NormalizeConfig(cfg *Config, cfgMetadata config_loader.YAMLMetadata) (err e
 		cfg.StartupConnectionTimeout = defaultStartupConnectionTimeout
 	}
 
+	if d, err := time.ParseDuration(cfg.EventSpoolMaxAge); err != nil || d <= 0 {
+		nlog.WithFields(logrus.Fields{
+			"provided": cfg.EventSpoolMaxAge,
+			"default":  defaultEventSpoolMaxAge,
+		}).Warn("wrong format for 'event_spool_max_age' property. Assuming default")
+		cfg.EventSpoolMaxAge = defaultEventSpoolMaxAge
+	}
+
+	if cfg.EventSpoolMaxSizeMB <= 0 {
+		cfg.EventSpoolMaxSizeMB = defaultEventSpoolMaxSizeMB
+	}
+
+	if d, err := time.ParseDuration(cfg.DMSpoolMaxAge); err != nil || d <= 0 {
+		nlog.WithFields(logrus.Fields{
+			"provided": cfg.DMSpoolMaxAge,
+			"default":  defaultDMSpoolMaxAge,
+		}).Warn("wrong format for 'dm_spool_max_age' property. Assuming default")
+		cfg.DMSpoolMaxAge = defaultDMSpoolMaxAge
+	}
+
+	if cfg.DMSpoolMaxSizeMB <= 0 {
+		cfg.DMSpoolMaxSizeMB = defaultDMSpoolMaxSizeMB
+	}
+
+	var groupBy []string
+	for _, attribute := range cfg.ProcessAggregationBy {
+		if attribute != ProcessAggregationByUser && attribute != ProcessAggregationByContainer {
+			nlog.WithField("provided", attribute).Warn("invalid value for 'process_aggregation_by' property. Ignoring it")
+			continue
+		}
+		groupBy = append(groupBy, attribute)
+	}
+	cfg.ProcessAggregationBy = groupBy
+
 	if cfg.MaxMetricsBatchSizeBytes > DefaultMaxMetricsBatchSizeBytes || cfg.MaxMetricsBatchSizeBytes <= 0 {
 		cfg.MaxMetricsBatchSizeBytes = DefaultMaxMetricsBatchSizeBytes
 	}
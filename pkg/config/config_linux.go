@@ -49,6 +49,20 @@ func init() {
 
 	// this is the default dir the infra sdk uses to store "temporary" data
 	defaultIntegrationsTempDir = filepath.Join("/tmp", "nr-integrations")
+
+	defaultLanguagePackagesPythonPaths = []string{
+		"/usr/lib/python3/dist-packages",
+		"/usr/lib/python3*/site-packages",
+		"/usr/local/lib/python3*/dist-packages",
+		"/usr/local/lib/python3*/site-packages",
+	}
+	defaultLanguagePackagesNpmPaths = []string{"/usr/lib/node_modules", "/usr/local/lib/node_modules"}
+	defaultLanguagePackagesGemPaths = []string{
+		"/usr/lib/ruby/gems/*/specifications",
+		"/usr/local/lib/ruby/gems/*/specifications",
+		"/var/lib/gems/*/specifications",
+	}
+	defaultLanguagePackagesGoPaths = []string{"/usr/local/bin"}
 }
 
 func configOverride(cfg *Config) {
@@ -66,6 +80,7 @@ func hostOverride(cfg *Config) {
 		_ = os.Setenv("HOST_SYS", filepath.Join(prefix, "/sys"))
 		_ = os.Setenv("HOST_ETC", filepath.Join(prefix, "/etc"))
 		_ = os.Setenv("HOST_VAR", filepath.Join(prefix, "/var"))
+		_ = os.Setenv("HOST_ROOT", prefix)
 	}
 	if cfg.OverrideHostProc != "" {
 		cfg.OverrideHostProc = prefix + cfg.OverrideHostProc
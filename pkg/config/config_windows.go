@@ -56,6 +56,7 @@ func init() {
 	defaultAgentDir = filepath.Join(sysDrive, installationSubdir)
 	defaultConfigDir = defaultAgentDir
 	defaultLogFile = filepath.Join(defaultAgentDir, "newrelic-infra.log")
+	DefaultControlServerSocket = filepath.Join(defaultAgentDir, "control.sock")
 	defaultPluginInstanceDir = filepath.Join(defaultAgentDir, "integrations.d")
 
 	defaultConfigFiles = []string{filepath.Join(defaultAgentDir, "newrelic-infra.yml")}
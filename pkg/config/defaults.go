@@ -38,11 +38,16 @@ var (
 	// public
 	DefaultContainerCacheMetadataLimit = 60
 	DefaultDockerApiVersion            = "1.24" // minimum supported API by Docker 18.09.0
+	DefaultStatusServerPort            = 8003
+	DefaultControlServerSocket         = "/var/run/newrelic-infra/control.sock"
 	DefaultHeartBeatFrequencySecs      = 60
 	DefaultDMPeriodSecs                = 5           // default telemetry SDK value
 	DefaultMaxMetricsBatchSizeBytes    = 1000 * 1000 // Size limit from Vortex collector service (1MB)
 	DefaultMaxMetricBatchEntitiesCount = 300         // Amount limit from Vortex collector service header (8k ~ 300 entities)
 	DefaultMaxMetricBatchEntitiesQueue = 1000        // Limit the amount of queued entities to be processed by Vortex collector service
+	DefaultMetricsCgroupSampleRate     = FREQ_DISABLE_SAMPLING
+	DefaultMetricsNetstatSampleRate    = FREQ_DISABLE_SAMPLING
+	DefaultMetricsSensorSampleRate     = FREQ_DISABLE_SAMPLING
 	DefaultMetricsNFSSampleRate        = 20
 	DefaultOfflineTimeToReset          = "24h"
 	DefaultStorageSamplerRateSecs      = 20
@@ -59,16 +64,20 @@ var (
 	defaultCompactThreshold              = 20 * 1024 * 1024 // (in bytes) compact repo when it hits 20MB
 	defaultIgnoreReclaimable             = false
 	defaultDebugLogSec                   = 600
+	defaultDMSpoolMaxAge                 = "24h"
+	defaultDMSpoolMaxSizeMB              = 100
 	defaultDisableInventorySplit         = false
 	defaultDisableWinSharedWMI           = false
 	defaultDisableZeroRSSFilter          = false
 	defaultDnsHostnameResolution         = true
+	defaultEventSpoolMaxAge              = "24h"
+	defaultEventSpoolMaxSizeMB           = 100
 	defaultFilesConfigOn                 = false
 	defaultMaxProcs                      = 1
 	defaultHTTPServerHost                = "localhost"
 	defaultHTTPServerPort                = 8001
 	defaultTCPServerPort                 = 8002
-	defaultStatusServerPort              = 8003
+	defaultTCPServerMaxLineSize          = 4 * 1024 * 1024
 	defaultIpData                        = true
 	defaultTruncTextValues               = true
 	defaultLogToStdout                   = true
@@ -127,6 +136,11 @@ var (
 	defaultFluentBitParsers        string
 	defaultFluentBitNRLib          string
 	defaultIntegrationsTempDir     string
+
+	defaultLanguagePackagesPythonPaths []string
+	defaultLanguagePackagesNpmPaths    []string
+	defaultLanguagePackagesGemPaths    []string
+	defaultLanguagePackagesGoPaths     []string
 )
 
 func getDefaultFacterHomeDir() (string, error) {
@@ -0,0 +1,178 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// logAttribute is the YAML attribute of the log configuration, whose options are compared one by one.
+const logAttribute = "log"
+
+// liveAttributes are the YAML attributes whose changes can be applied without restarting the agent.
+var liveAttributes = map[string]struct{}{
+	"verbose":                     {},
+	"log_format":                  {},
+	"log.level":                   {},
+	"log.format":                  {},
+	"log.include_filters":         {},
+	"log.exclude_filters":         {},
+	"custom_attributes":           {},
+	"metrics_system_sample_rate":  {},
+	"metrics_storage_sample_rate": {},
+	"metrics_network_sample_rate": {},
+	"metrics_process_sample_rate": {},
+	"metrics_nfs_sample_rate":     {},
+	"metrics_cgroup_sample_rate":  {},
+	"metrics_netstat_sample_rate": {},
+	"metrics_sensor_sample_rate":  {},
+	"cgroup_include_paths":        {},
+	"cgroup_exclude_paths":        {},
+	"enable_process_metrics":      {},
+	"include_matching_metrics":    {},
+	"exclude_matching_metrics":    {},
+	"process_top_n_by_cpu":        {},
+	"process_top_n_by_memory":     {},
+	"process_aggregation":         {},
+	"process_aggregation_by":      {},
+	"plugin_dir":                  {},
+}
+
+// derivedFields are the internal fields, by Go name, calculated from a live attribute when the config is loaded.
+var derivedFields = map[string][]string{
+	"plugin_dir": {"PluginInstanceDirs"},
+}
+
+// Changes holds, by YAML attribute, the configuration options that differ between two configurations.
+// Log options are named with the "log." prefix.
+type Changes struct {
+	// Live changes can be applied to a running agent.
+	Live []string
+	// Restart changes require an agent restart to be applied.
+	Restart []string
+}
+
+// IsEmpty returns true when there are no changes.
+func (c Changes) IsEmpty() bool {
+	return len(c.Live) == 0 && len(c.Restart) == 0
+}
+
+// Has returns true when any of the provided attributes has a live change.
+func (c Changes) Has(attributes ...string) bool {
+	for _, l := range c.Live {
+		for _, a := range attributes {
+			if l == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Diff returns the options that differ in the updated configuration. Internal options without
+// YAML attribute are not compared, as they are either fixed or calculated from other options.
+func Diff(current, updated *Config) (changes Changes) {
+	diffStruct("", reflect.ValueOf(current).Elem(), reflect.ValueOf(updated).Elem(), &changes)
+	return
+}
+
+// WithLive returns a copy of the configuration with the options of the updated one that can be changed
+// on a running agent, and the differences between both. The configuration isn't modified, as it may be
+// read concurrently: components pick up the new values once the copy replaces it.
+func (c *Config) WithLive(updated *Config) (*Config, Changes) {
+	changes := Diff(c, updated)
+
+	live := c.copyOptions()
+	current := reflect.ValueOf(live).Elem()
+	newer := reflect.ValueOf(updated).Elem()
+	for _, attribute := range changes.Live {
+		if strings.HasPrefix(attribute, logAttribute+".") {
+			setByYamlAttribute(current.FieldByName("Log"), newer.FieldByName("Log"), strings.TrimPrefix(attribute, logAttribute+"."))
+			continue
+		}
+		setByYamlAttribute(current, newer, attribute)
+		for _, name := range derivedFields[attribute] {
+			current.FieldByName(name).Set(newer.FieldByName(name))
+		}
+	}
+	return live, changes
+}
+
+// copyOptions returns a shallow copy of the exported options of the configuration.
+func (c *Config) copyOptions() *Config {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cfg := &Config{}
+	src := reflect.ValueOf(c).Elem()
+	dst := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if src.Type().Field(i).PkgPath == "" {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return cfg
+}
+
+func diffStruct(prefix string, current, updated reflect.Value, changes *Changes) {
+	t := current.Type()
+	for i := 0; i < t.NumField(); i++ {
+		attribute := yamlAttribute(t.Field(i))
+		if attribute == "" || t.Field(i).PkgPath != "" {
+			continue
+		}
+		cur, upd := current.Field(i).Interface(), updated.Field(i).Interface()
+		if reflect.DeepEqual(cur, upd) {
+			continue
+		}
+		if prefix != "" {
+			attribute = prefix + "." + attribute
+		} else if attribute == logAttribute {
+			diffStruct(logAttribute, current.Field(i), updated.Field(i), changes)
+			continue
+		}
+
+		if isLive(attribute, cur, upd) {
+			changes.Live = append(changes.Live, attribute)
+		} else {
+			changes.Restart = append(changes.Restart, attribute)
+		}
+	}
+}
+
+// isLive returns whether the change of the attribute can be applied to a running agent. Enabling or
+// disabling samplers, and entering or leaving the smart log mode, require a restart.
+func isLive(attribute string, current, updated interface{}) bool {
+	if _, ok := liveAttributes[attribute]; !ok {
+		return false
+	}
+	switch attribute {
+	case "log.level":
+		return current != LogLevelSmart && updated != LogLevelSmart
+	case "verbose":
+		return current != SmartVerboseLogging && updated != SmartVerboseLogging
+	}
+	if strings.HasSuffix(attribute, "_sample_rate") {
+		return current.(int) > FREQ_DISABLE_SAMPLING && updated.(int) > FREQ_DISABLE_SAMPLING
+	}
+	return true
+}
+
+func yamlAttribute(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func setByYamlAttribute(current, updated reflect.Value, attribute string) {
+	t := current.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if yamlAttribute(t.Field(i)) == attribute {
+			current.Field(i).Set(updated.Field(i))
+			return
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff_NoChanges(t *testing.T) {
+	assert.True(t, Diff(NewConfig(), NewConfig()).IsEmpty())
+}
+
+func TestDiff(t *testing.T) {
+	current := NewConfig()
+	current.MetricsSystemSampleRate = 5
+	current.MetricsNetworkSampleRate = 10
+	current.CustomAttributes = CustomAttributeMap{"env": "staging"}
+	current.Log.Level = LogLevelInfo
+	current.Log.File = "/var/log/agent.log"
+
+	updated := NewConfig()
+	updated.MetricsSystemSampleRate = 15
+	updated.MetricsNetworkSampleRate = FREQ_DISABLE_SAMPLING
+	updated.CustomAttributes = CustomAttributeMap{"env": "production"}
+	updated.Log.Level = LogLevelDebug
+	updated.Log.File = "/tmp/agent.log"
+	updated.License = "new-license"
+	// internal fields aren't compared
+	updated.PluginConfigFiles = []string{"other.yml"}
+
+	changes := Diff(current, updated)
+
+	assert.ElementsMatch(t, []string{"custom_attributes", "metrics_system_sample_rate", "log.level"}, changes.Live)
+	assert.ElementsMatch(t, []string{"license_key", "metrics_network_sample_rate", "log.file"}, changes.Restart)
+	assert.True(t, changes.Has("log.level", "log.format"))
+	assert.False(t, changes.Has("log.file"))
+}
+
+func TestDiff_SmartLogLevelRequiresRestart(t *testing.T) {
+	current := NewConfig()
+	current.Log.Level = LogLevelInfo
+	updated := NewConfig()
+	updated.Log.Level = LogLevelSmart
+
+	assert.Equal(t, Changes{Restart: []string{"log.level"}}, Diff(current, updated))
+}
+
+func TestWithLive(t *testing.T) {
+	current := NewConfig()
+	current.License = "license"
+	current.MetricsProcessSampleRate = 20
+	current.PluginDir = "/etc/integrations.d"
+	current.PluginInstanceDirs = []string{"/etc/integrations.d"}
+	current.Log.Format = LogFormatText
+	current.Log.File = "/var/log/agent.log"
+
+	updated := NewConfig()
+	updated.License = "new-license"
+	updated.MetricsProcessSampleRate = 60
+	updated.PluginDir = "/opt/integrations.d"
+	updated.PluginInstanceDirs = []string{"/opt/integrations.d"}
+	updated.Log.Format = LogFormatJSON
+	updated.Log.File = "/tmp/agent.log"
+
+	live, changes := current.WithLive(updated)
+
+	assert.ElementsMatch(t, []string{"metrics_process_sample_rate", "plugin_dir", "log.format"}, changes.Live)
+	assert.ElementsMatch(t, []string{"license_key", "log.file"}, changes.Restart)
+	// live options are updated
+	assert.Equal(t, 60, live.MetricsProcessSampleRate)
+	assert.Equal(t, "/opt/integrations.d", live.PluginDir)
+	assert.Equal(t, []string{"/opt/integrations.d"}, live.PluginInstanceDirs)
+	assert.Equal(t, LogFormatJSON, live.Log.Format)
+	// restart options are kept
+	assert.Equal(t, "license", live.License)
+	assert.Equal(t, "/var/log/agent.log", live.Log.File)
+	// the running config isn't modified
+	assert.Equal(t, 20, current.MetricsProcessSampleRate)
+	assert.Equal(t, "/etc/integrations.d", current.PluginDir)
+	assert.Equal(t, LogFormatText, current.Log.Format)
+}
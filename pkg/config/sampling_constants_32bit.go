@@ -44,6 +44,11 @@ const (
 	FREQ_PLUGIN_NETWORK_INTERFACE_UPDATES = 60 // seconds
 	FREQ_PLUGIN_CLOUD_SECURITY_UPDATES    = 60 // seconds
 
+	FREQ_PLUGIN_LANGUAGE_PACKAGES_UPDATES = 300 // seconds -- language packages plugins, walking their directories
+	FREQ_PLUGIN_LISTENING_PORTS_UPDATES   = 60  // seconds
+	FREQ_PLUGIN_ACCOUNTS_UPDATES          = 60  // seconds
+	FREQ_PLUGIN_SCHEDULED_JOBS_UPDATES    = 60  // seconds
+
 	// WINDOWS PLUGINS
 	FREQ_PLUGIN_WINDOWS_SERVICES = 30 // seconds, 0 == off, 30 == minimum otherwise: inventory: running services
 	FREQ_PLUGIN_WINDOWS_UPDATES  = 60 // seconds
@@ -6,7 +6,7 @@ package secrets
 import (
 	"fmt"
 	"github.com/newrelic/infrastructure-agent/pkg/databind/pkg/data"
-	. "net/http"
+	gohttp "net/http"
 	"net/http/httptest"
 	"testing"
 )
@@ -91,7 +91,7 @@ func TestCyperArkAPIResponeCodes(t *testing.T) {
 }
 
 func newHttpTestServer(response string, rc int) *httptest.Server {
-	return httptest.NewServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+	return httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
 		w.WriteHeader(rc)
 		w.Write([]byte(response))
 	}))
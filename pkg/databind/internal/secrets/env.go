@@ -0,0 +1,56 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Env defines a secret injected into the agent as an environment variable.
+type Env struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type,omitempty"` // can be 'json', 'equal' and 'plain' (default)
+}
+
+type envGatherer struct {
+	cfg *Env
+}
+
+// EnvGatherer instantiates an Env variable gatherer from the given configuration. The fetching process
+// will return either a map containing access paths to the stored JSON or ShortHand, or a string if
+// the stored secret is just a string.
+// E.g. if the environment variable contains `{"db":{"user":"admin"}}` with type `json`, the returned
+// Map contents will be:
+// "db.user" -> "admin"
+func EnvGatherer(env *Env) func() (interface{}, error) {
+	g := envGatherer{cfg: env}
+	return func() (interface{}, error) {
+		dt, err := g.get()
+		if err != nil {
+			return "", err
+		}
+		return dt, err
+	}
+}
+
+func (g *envGatherer) get() (interface{}, error) {
+	value, ok := os.LookupEnv(g.cfg.Name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable '%s' is not set", g.cfg.Name)
+	}
+	return handleDataType([]byte(value), g.cfg.Type)
+}
+
+// Validate checks if the Env configuration is correct
+func (e *Env) Validate() error {
+	if e.Name == "" {
+		return errors.New("env secrets must have a name parameter in order to be set")
+	}
+	if _, ok := os.LookupEnv(e.Name); !ok {
+		return fmt.Errorf("env secret variable '%s' is not set", e.Name)
+	}
+	return validateDataType(e.Type)
+}
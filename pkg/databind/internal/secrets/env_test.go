@@ -0,0 +1,37 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"testing"
+
+	"github.com/newrelic/infrastructure-agent/pkg/databind/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvGatherer(t *testing.T) {
+	t.Setenv("NRIA_TEST_SECRET_PLAIN", "my-password")
+	t.Setenv("NRIA_TEST_SECRET_JSON", `{"db":{"user":"admin"}}`)
+
+	r, err := EnvGatherer(&Env{Name: "NRIA_TEST_SECRET_PLAIN"})()
+	require.NoError(t, err)
+	assert.Equal(t, "my-password", r)
+
+	r, err = EnvGatherer(&Env{Name: "NRIA_TEST_SECRET_JSON", Type: typeJson})()
+	require.NoError(t, err)
+	assert.Equal(t, data.InterfaceMap{"db": map[string]interface{}{"user": "admin"}}, r)
+
+	_, err = EnvGatherer(&Env{Name: "NRIA_TEST_SECRET_MISSING"})()
+	assert.Error(t, err)
+}
+
+func TestEnv_Validate(t *testing.T) {
+	t.Setenv("NRIA_TEST_SECRET", "my-password")
+
+	assert.NoError(t, (&Env{Name: "NRIA_TEST_SECRET"}).Validate())
+	assert.Error(t, (&Env{}).Validate())
+	assert.Error(t, (&Env{Name: "NRIA_TEST_SECRET_MISSING"}).Validate())
+	assert.Error(t, (&Env{Name: "NRIA_TEST_SECRET", Type: "yaml"}).Validate())
+}
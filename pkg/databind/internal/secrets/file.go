@@ -0,0 +1,65 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// File defines a secret stored in a local file, e.g. a Kubernetes or Docker mounted secret.
+type File struct {
+	Path string `yaml:"path"`
+	Type string `yaml:"type,omitempty"` // can be 'json', 'equal' and 'plain' (default)
+}
+
+type fileGatherer struct {
+	cfg *File
+}
+
+// FileGatherer instantiates a File variable gatherer from the given configuration. The file is read
+// again each time the variable TTL expires, so rotated secrets are picked up. The fetching process
+// will return either a map containing access paths to the stored JSON or ShortHand, or a string if
+// the stored secret is just a string.
+// E.g. if the stored secret is `user=admin,password=secret` with type `equal`, the returned Map
+// contents will be:
+// "user"     -> "admin"
+// "password" -> "secret"
+func FileGatherer(file *File) func() (interface{}, error) {
+	g := fileGatherer{cfg: file}
+	return func() (interface{}, error) {
+		dt, err := g.get()
+		if err != nil {
+			return "", err
+		}
+		return dt, err
+	}
+}
+
+func (g *fileGatherer) get() (interface{}, error) {
+	dt, err := ioutil.ReadFile(g.cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file secret '%s': %s", g.cfg.Path, err)
+	}
+	// mounted secrets are usually written with a trailing end-of-line
+	return handleDataType(bytes.TrimRight(dt, "\r\n"), g.cfg.Type)
+}
+
+// Validate checks if the File configuration is correct
+func (f *File) Validate() error {
+	if f.Path == "" {
+		return errors.New("file secrets must have a path parameter in order to be set")
+	}
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return fmt.Errorf("file secret path '%s' can't be accessed: %s", f.Path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("file secret path '%s' is a directory", f.Path)
+	}
+	return validateDataType(f.Type)
+}
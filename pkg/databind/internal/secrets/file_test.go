@@ -0,0 +1,67 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/newrelic/infrastructure-agent/pkg/databind/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileGatherer(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		dataType string
+		expected interface{}
+	}{
+		{"plain", "my-password\n", "", "my-password"},
+		{"json", `{"db":{"user":"admin","password":"secret"}}`, typeJson, data.InterfaceMap{"db": map[string]interface{}{"user": "admin", "password": "secret"}}},
+		{"equal", "user=admin,password=secret\n", typeEqual, data.InterfaceMap{"user": "admin", "password": "secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "secret")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tt.content), 0600))
+
+			file := &File{Path: path, Type: tt.dataType}
+			require.NoError(t, file.Validate())
+
+			r, err := FileGatherer(file)()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestFileGatherer_ReadsRotatedSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, ioutil.WriteFile(path, []byte("old"), 0600))
+	g := FileGatherer(&File{Path: path})
+
+	r, err := g()
+	require.NoError(t, err)
+	assert.Equal(t, "old", r)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("new"), 0600))
+	r, err = g()
+	require.NoError(t, err)
+	assert.Equal(t, "new", r)
+}
+
+func TestFile_Validate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	require.NoError(t, ioutil.WriteFile(path, []byte("secret"), 0600))
+
+	assert.NoError(t, (&File{Path: path}).Validate())
+	assert.Error(t, (&File{}).Validate())
+	assert.Error(t, (&File{Path: filepath.Join(dir, "missing")}).Validate())
+	assert.Error(t, (&File{Path: dir}).Validate())
+	assert.Error(t, (&File{Path: path, Type: "yaml"}).Validate())
+}
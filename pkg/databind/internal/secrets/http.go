@@ -26,6 +26,16 @@ type tlsConfig struct {
 	Ca                 string `yaml:"ca"`
 }
 
+// httpStatusError is returned when the server answers with a non-OK status code.
+type httpStatusError struct {
+	code   int
+	status string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("error response received from server: %s", e.status)
+}
+
 func httpRequest(config *http, method string, body io.Reader) ([]byte, error) {
 	client := &gohttp.Client{}
 	tlsConfig := &tls.Config{
@@ -69,7 +79,7 @@ func httpRequest(config *http, method string, body io.Reader) ([]byte, error) {
 
 	if res.StatusCode != gohttp.StatusOK {
 		_, _ = io.Copy(ioutil.Discard, res.Body)
-		return nil, fmt.Errorf("error response received from server: %s", res.Status)
+		return nil, &httpStatusError{code: res.StatusCode, status: res.Status}
 	}
 	b, err := ioutil.ReadAll(res.Body)
 	if err != nil {
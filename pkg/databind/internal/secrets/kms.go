@@ -85,7 +85,12 @@ func (k *KMS) Validate() error {
 	if k.File == "" && k.Data == "" && (k.HTTP == nil || k.HTTP.URL == "") {
 		return errors.New("aws-kms must have a file, data or http parameter in order to be set")
 	}
-	if k.Type != "" && k.Type != typeJson && k.Type != typeEqual && k.Type != typePlain {
+	return validateDataType(k.Type)
+}
+
+// validateDataType checks the type used to decode a secret payload into a string or a map
+func validateDataType(dataType string) error {
+	if dataType != "" && dataType != typeJson && dataType != typeEqual && dataType != typePlain {
 		return errors.New("type can be only " + typePlain + ", " + typeJson + " or " + typeEqual)
 	}
 	return nil
@@ -4,18 +4,74 @@
 package secrets
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	gohttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/newrelic/infrastructure-agent/pkg/databind/pkg/data"
 )
 
+const (
+	vaultAuthAppRole    = "approle"
+	vaultAuthKubernetes = "kubernetes"
+
+	vaultTokenHeader         = "X-Vault-Token"
+	vaultAPIPrefix           = "/v1/"
+	defaultKubernetesJWTFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
 type Vault struct {
 	HTTP *http
+	// Auth enables logging into Vault instead of using a static token header.
+	Auth *VaultAuth `yaml:"auth,omitempty"`
+	// KVVersion forces the secret envelope format: 1 or 2. If unset, it is guessed from the response.
+	KVVersion int `yaml:"kv_version,omitempty"`
+	// Version pins a KV v2 secret version. If unset, the latest version is retrieved.
+	Version int `yaml:"version,omitempty"`
+}
+
+// VaultAuth defines how to log into Vault to obtain a client token.
+type VaultAuth struct {
+	Method string `yaml:"method"` // can be 'approle' or 'kubernetes'
+	Mount  string `yaml:"mount,omitempty"`
+	// URL is the Vault server address. If unset, it is taken from the secret URL.
+	URL string `yaml:"url,omitempty"`
+	// approle method
+	RoleID       string `yaml:"role_id,omitempty"`
+	SecretID     string `yaml:"secret_id,omitempty"`
+	SecretIDFile string `yaml:"secret_id_file,omitempty"`
+	// kubernetes method
+	Role    string `yaml:"role,omitempty"`
+	JWTFile string `yaml:"jwt_file,omitempty"`
+}
+
+// vaultToken is a client token obtained from a login, along with its lease.
+type vaultToken struct {
+	id        string
+	renewable bool
+	lease     time.Duration
+	expires   time.Time // zero value for non expiring tokens
+}
+
+// needsRefresh returns true when less than a third of the token lease is remaining.
+func (t *vaultToken) needsRefresh(now time.Time) bool {
+	if t.expires.IsZero() {
+		return false
+	}
+	return !now.Before(t.expires.Add(-t.lease / 3))
 }
 
 type vaultGatherer struct {
-	cfg *Vault
+	cfg   *Vault
+	now   func() time.Time
+	token *vaultToken
 }
 
 // VaultGatherer instantiates a Vault variable gatherer from the given configuration. The fetching process
@@ -24,8 +80,10 @@ type vaultGatherer struct {
 // contents will be:
 // "person.name"    -> "Matias"
 // "person.surname" -> "Burni"
+// When auth is configured, the gatherer logs into Vault and keeps the client token between fetches,
+// renewing it or logging in again when its lease is about to expire.
 func VaultGatherer(vault *Vault) func() (interface{}, error) {
-	g := vaultGatherer{cfg: vault}
+	g := vaultGatherer{cfg: vault, now: time.Now}
 	return func() (interface{}, error) {
 		dt, err := g.get()
 		if err != nil {
@@ -36,29 +94,235 @@ func VaultGatherer(vault *Vault) func() (interface{}, error) {
 }
 
 func (g *vaultGatherer) get() (data.InterfaceMap, error) {
-	secret := g.cfg
-	dt, err := httpRequest(secret.HTTP, "GET", nil)
+	secretURL, err := g.cfg.secretURL()
+	if err != nil {
+		return nil, err
+	}
+
+	dt, err := g.fetch(secretURL)
+	if isStatusError(err, gohttp.StatusForbidden) && g.cfg.Auth != nil {
+		// the token may have been revoked before its lease expired: log in again and retry once
+		slog.WithError(err).Debug("Vault denied access, logging in again.")
+		g.token = nil
+		dt, err = g.fetch(secretURL)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve vault secret from http server: %s", err)
 	}
 
+	return g.cfg.decode(dt)
+}
+
+// fetch retrieves the given URL with the user's http configuration, authenticating the request
+// with the client token if the auth section is set.
+func (g *vaultGatherer) fetch(url string) ([]byte, error) {
+	if g.cfg.Auth == nil {
+		cfg := *g.cfg.HTTP
+		cfg.URL = url
+		return httpRequest(&cfg, "GET", nil)
+	}
+
+	token, err := g.clientToken()
+	if err != nil {
+		return nil, err
+	}
+	return httpRequest(g.cfg.authHTTP(url, token), "GET", nil)
+}
+
+// clientToken returns a valid client token, renewing the current one or logging in when required.
+func (g *vaultGatherer) clientToken() (string, error) {
+	if g.token != nil {
+		if !g.token.needsRefresh(g.now()) {
+			return g.token.id, nil
+		}
+		if g.token.renewable {
+			err := g.renew()
+			if err == nil {
+				return g.token.id, nil
+			}
+			slog.WithError(err).Debug("Unable to renew vault token, logging in again.")
+		}
+	}
+
+	if err := g.login(); err != nil {
+		return "", err
+	}
+	return g.token.id, nil
+}
+
+func (g *vaultGatherer) login() error {
+	auth := g.cfg.Auth
+	payload, err := auth.loginPayload()
+	if err != nil {
+		return err
+	}
+
+	addr, err := g.cfg.address()
+	if err != nil {
+		return err
+	}
+	loginURL := addr + vaultAPIPrefix + "auth/" + auth.mount() + "/login"
+	dt, err := httpRequest(g.cfg.authHTTP(loginURL, ""), "POST", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to login into vault with %s auth method: %s", auth.Method, err)
+	}
+
+	token, err := parseVaultToken(dt, g.now())
+	if err != nil {
+		return fmt.Errorf("unable to login into vault with %s auth method: %s", auth.Method, err)
+	}
+	g.token = token
+	return nil
+}
+
+func (g *vaultGatherer) renew() error {
+	addr, err := g.cfg.address()
+	if err != nil {
+		return err
+	}
+	renewURL := addr + vaultAPIPrefix + "auth/token/renew-self"
+	dt, err := httpRequest(g.cfg.authHTTP(renewURL, g.token.id), "POST", nil)
+	if err != nil {
+		return err
+	}
+
+	token, err := parseVaultToken(dt, g.now())
+	if err != nil {
+		return err
+	}
+	g.token = token
+	return nil
+}
+
+func parseVaultToken(dt []byte, now time.Time) (*vaultToken, error) {
+	var res struct {
+		Auth *struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+			Renewable     bool   `json:"renewable"`
+		} `json:"auth"`
+	}
+	if err := json.Unmarshal(dt, &res); err != nil {
+		return nil, fmt.Errorf("unable to decode vault auth response: %s", err)
+	}
+	if res.Auth == nil || res.Auth.ClientToken == "" {
+		return nil, errors.New("vault auth response does not contain a client token")
+	}
+
+	token := &vaultToken{
+		id:        res.Auth.ClientToken,
+		renewable: res.Auth.Renewable,
+		lease:     time.Duration(res.Auth.LeaseDuration) * time.Second,
+	}
+	if token.lease > 0 {
+		token.expires = now.Add(token.lease)
+	}
+	return token, nil
+}
+
+// decode extracts the secret from the KV v1 (`data`) or KV v2 (`data.data`) envelope.
+func (v *Vault) decode(dt []byte) (data.InterfaceMap, error) {
 	smap := data.InterfaceMap{}
 	if err := json.Unmarshal(dt, &smap); err != nil {
 		return nil, fmt.Errorf("unable to decode vault secret: %s", err)
 	}
-	if d, ok := smap["data"]; ok {
-		if sdata, ok := d.(map[string]interface{})["data"]; ok {
-			if idata, ok := sdata.(map[string]interface{}); ok {
+	if d, ok := smap["data"].(map[string]interface{}); ok {
+		if v.KVVersion != 1 {
+			if idata, ok := d["data"].(map[string]interface{}); ok {
 				return idata, nil
 			}
 		}
-		if idata, ok := d.(map[string]interface{}); ok {
-			return idata, nil
+		if v.KVVersion != 2 {
+			return d, nil
 		}
 	}
 	return nil, fmt.Errorf("vault returned an unexpected format from the http server: %s", string(dt))
 }
 
+// secretURL returns the configured secret URL, pinned to the configured version, if any.
+func (v *Vault) secretURL() (string, error) {
+	if v.Version == 0 {
+		return v.HTTP.URL, nil
+	}
+	u, err := url.Parse(v.HTTP.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid vault secret URL: %s", err)
+	}
+	q := u.Query()
+	q.Set("version", strconv.Itoa(v.Version))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// address returns the Vault server address, either from the auth configuration or from the
+// secret URL, by removing the API path.
+func (v *Vault) address() (string, error) {
+	if v.Auth.URL != "" {
+		return strings.TrimSuffix(v.Auth.URL, "/"), nil
+	}
+	if i := strings.Index(v.HTTP.URL, vaultAPIPrefix); i > 0 {
+		return v.HTTP.URL[:i], nil
+	}
+	return "", fmt.Errorf("unable to get the vault address from URL %q, please set the auth url parameter", v.HTTP.URL)
+}
+
+// authHTTP returns a copy of the user http configuration for the given URL, replacing any
+// static token header by the provided client token.
+func (v *Vault) authHTTP(url string, token string) *http {
+	cfg := *v.HTTP
+	cfg.URL = url
+	cfg.Headers = map[string]string{}
+	for key, value := range v.HTTP.Headers {
+		if !strings.EqualFold(key, vaultTokenHeader) {
+			cfg.Headers[key] = value
+		}
+	}
+	if token != "" {
+		cfg.Headers[vaultTokenHeader] = token
+	}
+	return &cfg
+}
+
+func (a *VaultAuth) mount() string {
+	if a.Mount != "" {
+		return strings.Trim(a.Mount, "/")
+	}
+	return a.Method
+}
+
+// loginPayload returns the login request body. Secret ID and JWT files are read on each login
+// so rotated credentials are picked up.
+func (a *VaultAuth) loginPayload() ([]byte, error) {
+	payload := map[string]string{}
+	switch a.Method {
+	case vaultAuthAppRole:
+		payload["role_id"] = a.RoleID
+		secretID := a.SecretID
+		if a.SecretIDFile != "" {
+			dt, err := ioutil.ReadFile(a.SecretIDFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read vault secret_id file '%s': %s", a.SecretIDFile, err)
+			}
+			secretID = strings.TrimSpace(string(dt))
+		}
+		if secretID != "" {
+			payload["secret_id"] = secretID
+		}
+	case vaultAuthKubernetes:
+		jwtFile := a.JWTFile
+		if jwtFile == "" {
+			jwtFile = defaultKubernetesJWTFile
+		}
+		dt, err := ioutil.ReadFile(jwtFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read vault jwt file '%s': %s", jwtFile, err)
+		}
+		payload["role"] = a.Role
+		payload["jwt"] = strings.TrimSpace(string(dt))
+	}
+	return json.Marshal(payload)
+}
+
 func (g *Vault) Validate() error {
 	if g.HTTP == nil {
 		return errors.New("vault secrets must have an http parameter with a URL in order to be set")
@@ -66,5 +330,41 @@ func (g *Vault) Validate() error {
 	if g.HTTP.URL == "" {
 		return errors.New("vault secrets must have an http URL parameter in order to be set")
 	}
+	if g.KVVersion != 0 && g.KVVersion != 1 && g.KVVersion != 2 {
+		return errors.New("vault kv_version can be only 1 or 2")
+	}
+	if g.Version < 0 {
+		return errors.New("vault secret version must be a positive number")
+	}
+	if g.Version > 0 && g.KVVersion == 1 {
+		return errors.New("vault secret version can only be pinned for kv_version 2")
+	}
+	if g.Auth != nil {
+		return g.Auth.validate()
+	}
+	return nil
+}
+
+func (a *VaultAuth) validate() error {
+	switch a.Method {
+	case vaultAuthAppRole:
+		if a.RoleID == "" {
+			return errors.New("vault approle auth must have a role_id parameter in order to be set")
+		}
+		if a.SecretID != "" && a.SecretIDFile != "" {
+			return errors.New("vault approle auth can't have both secret_id and secret_id_file parameters")
+		}
+	case vaultAuthKubernetes:
+		if a.Role == "" {
+			return errors.New("vault kubernetes auth must have a role parameter in order to be set")
+		}
+	default:
+		return errors.New("vault auth method can be only " + vaultAuthAppRole + " or " + vaultAuthKubernetes)
+	}
 	return nil
 }
+
+func isStatusError(err error, code int) bool {
+	var statusErr *httpStatusError
+	return errors.As(err, &statusErr) && statusErr.code == code
+}
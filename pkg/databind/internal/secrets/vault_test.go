@@ -0,0 +1,250 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	gohttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/pkg/databind/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kv2Response = `{"data":{"data":{"user":"admin","password":"secret"},"metadata":{"version":3}}}`
+
+func TestVault_StaticToken(t *testing.T) {
+	ts := httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		if r.Header.Get(vaultTokenHeader) != "static-token" {
+			w.WriteHeader(gohttp.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(kv2Response))
+	}))
+	defer ts.Close()
+
+	g := VaultGatherer(&Vault{HTTP: &http{
+		URL:     ts.URL + "/v1/secret/data/db",
+		Headers: map[string]string{vaultTokenHeader: "static-token"},
+	}})
+
+	r, err := g()
+	require.NoError(t, err)
+	assert.Equal(t, data.InterfaceMap{"user": "admin", "password": "secret"}, r)
+}
+
+func TestVault_KVVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		kvVersion int
+		response  string
+		expected  data.InterfaceMap
+		fails     bool
+	}{
+		{"v2 autodetected", 0, kv2Response, data.InterfaceMap{"user": "admin", "password": "secret"}, false},
+		{"v1 autodetected", 0, `{"data":{"user":"admin"}}`, data.InterfaceMap{"user": "admin"}, false},
+		{"v1 forced", 1, `{"data":{"data":{"user":"admin"}}}`, data.InterfaceMap{"data": map[string]interface{}{"user": "admin"}}, false},
+		{"v2 forced with v1 envelope", 2, `{"data":{"user":"admin"}}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Vault{KVVersion: tt.kvVersion}
+			r, err := v.decode([]byte(tt.response))
+			if tt.fails {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestVault_PinnedVersion(t *testing.T) {
+	ts := httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		if r.URL.Query().Get("version") != "2" {
+			w.WriteHeader(gohttp.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(kv2Response))
+	}))
+	defer ts.Close()
+
+	g := VaultGatherer(&Vault{HTTP: &http{URL: ts.URL + "/v1/secret/data/db"}, KVVersion: 2, Version: 2})
+
+	_, err := g()
+	assert.NoError(t, err)
+}
+
+// vaultServer emulates the login, renewal and KV v2 endpoints of a Vault server.
+type vaultServer struct {
+	*httptest.Server
+	logins  int
+	renews  int
+	token   string
+	lease   int
+	revoked bool
+}
+
+func newVaultServer(t *testing.T, loginPath string, expectedLogin map[string]string) *vaultServer {
+	vs := &vaultServer{lease: 60}
+	vs.Server = httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		switch r.URL.Path {
+		case loginPath:
+			body := map[string]string{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, expectedLogin, body)
+			vs.logins++
+			vs.revoked = false
+			vs.token = "token-" + string(rune('0'+vs.logins))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{
+				"client_token": vs.token, "lease_duration": vs.lease, "renewable": true,
+			}})
+		case "/v1/auth/token/renew-self":
+			if r.Header.Get(vaultTokenHeader) != vs.token || vs.revoked {
+				w.WriteHeader(gohttp.StatusForbidden)
+				return
+			}
+			vs.renews++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{
+				"client_token": vs.token, "lease_duration": vs.lease, "renewable": true,
+			}})
+		case "/v1/secret/data/db":
+			if r.Header.Get(vaultTokenHeader) != vs.token || vs.revoked {
+				w.WriteHeader(gohttp.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(kv2Response))
+		default:
+			w.WriteHeader(gohttp.StatusNotFound)
+		}
+	}))
+	return vs
+}
+
+func TestVault_AppRole(t *testing.T) {
+	vs := newVaultServer(t, "/v1/auth/approle/login", map[string]string{"role_id": "my-role", "secret_id": "my-secret"})
+	defer vs.Close()
+
+	now := time.Now()
+	g := vaultGatherer{
+		cfg: &Vault{
+			HTTP: &http{URL: vs.URL + "/v1/secret/data/db", Headers: map[string]string{"X-Vault-Namespace": "ns1"}},
+			Auth: &VaultAuth{Method: vaultAuthAppRole, RoleID: "my-role", SecretID: "my-secret"},
+		},
+		now: func() time.Time { return now },
+	}
+
+	// first fetch logs in
+	r, err := g.get()
+	require.NoError(t, err)
+	assert.Equal(t, data.InterfaceMap{"user": "admin", "password": "secret"}, r)
+	assert.Equal(t, 1, vs.logins)
+
+	// token is reused while the lease is valid
+	now = now.Add(10 * time.Second)
+	_, err = g.get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, vs.logins)
+	assert.Equal(t, 0, vs.renews)
+
+	// token is renewed when close to expire
+	now = now.Add(40 * time.Second)
+	_, err = g.get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, vs.logins)
+	assert.Equal(t, 1, vs.renews)
+
+	// revoked tokens force a new login
+	vs.revoked = true
+	_, err = g.get()
+	require.NoError(t, err)
+	assert.Equal(t, 2, vs.logins)
+}
+
+func TestVault_AppRole_ExpiredTokenLogsInAgain(t *testing.T) {
+	vs := newVaultServer(t, "/v1/auth/approle/login", map[string]string{"role_id": "my-role"})
+	defer vs.Close()
+
+	now := time.Now()
+	g := vaultGatherer{
+		cfg: &Vault{
+			HTTP: &http{URL: vs.URL + "/v1/secret/data/db"},
+			Auth: &VaultAuth{Method: vaultAuthAppRole, RoleID: "my-role"},
+		},
+		now: func() time.Time { return now },
+	}
+
+	_, err := g.get()
+	require.NoError(t, err)
+
+	// renewal fails for expired tokens
+	vs.revoked = true
+	now = now.Add(time.Hour)
+	_, err = g.get()
+	require.NoError(t, err)
+	assert.Equal(t, 2, vs.logins)
+	assert.Equal(t, 0, vs.renews)
+}
+
+func TestVault_Kubernetes(t *testing.T) {
+	jwtFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, ioutil.WriteFile(jwtFile, []byte("my-jwt\n"), 0600))
+
+	vs := newVaultServer(t, "/v1/auth/k8s-cluster/login", map[string]string{"role": "infra", "jwt": "my-jwt"})
+	defer vs.Close()
+
+	g := VaultGatherer(&Vault{
+		HTTP: &http{URL: vs.URL + "/v1/secret/data/db"},
+		Auth: &VaultAuth{Method: vaultAuthKubernetes, Mount: "k8s-cluster", Role: "infra", JWTFile: jwtFile},
+	})
+
+	r, err := g()
+	require.NoError(t, err)
+	assert.Equal(t, data.InterfaceMap{"user": "admin", "password": "secret"}, r)
+}
+
+func TestVault_Kubernetes_MissingJWT(t *testing.T) {
+	g := VaultGatherer(&Vault{
+		HTTP: &http{URL: "http://localhost/v1/secret/data/db"},
+		Auth: &VaultAuth{Method: vaultAuthKubernetes, Role: "infra", JWTFile: filepath.Join(os.TempDir(), "non-existing-jwt")},
+	})
+
+	_, err := g()
+	assert.Error(t, err)
+}
+
+func TestVault_Validate(t *testing.T) {
+	valid := &http{URL: "http://localhost/v1/secret/data/db"}
+	tests := []struct {
+		name  string
+		vault Vault
+		fails bool
+	}{
+		{"static token", Vault{HTTP: valid}, false},
+		{"missing http", Vault{}, true},
+		{"invalid kv version", Vault{HTTP: valid, KVVersion: 3}, true},
+		{"version for kv v1", Vault{HTTP: valid, KVVersion: 1, Version: 2}, true},
+		{"approle", Vault{HTTP: valid, Auth: &VaultAuth{Method: vaultAuthAppRole, RoleID: "role"}}, false},
+		{"approle without role_id", Vault{HTTP: valid, Auth: &VaultAuth{Method: vaultAuthAppRole}}, true},
+		{"kubernetes without role", Vault{HTTP: valid, Auth: &VaultAuth{Method: vaultAuthKubernetes}}, true},
+		{"unknown method", Vault{HTTP: valid, Auth: &VaultAuth{Method: "ldap"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.vault.Validate()
+			if tt.fails {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -51,6 +51,8 @@ type varEntry struct {
 	CyberArkCLI *secrets.CyberArkCLI `yaml:"cyberark-cli,omitempty" json:"cyberark-cli,omitempty"`
 	CyberArkAPI *secrets.CyberArkAPI `yaml:"cyberark-api,omitempty" json:"cyberark-api,omitempty"`
 	Obfuscated  *secrets.Obfuscated  `yaml:"obfuscated,omitempty" json:"obfuscated,omitempty"`
+	File        *secrets.File        `yaml:"file,omitempty" json:"file,omitempty"`
+	Env         *secrets.Env         `yaml:"env,omitempty" json:"env,omitempty"`
 }
 
 // Test for testing purposes until providers get decoupled.
@@ -259,8 +261,20 @@ func (v *varEntry) validate() error {
 			return err
 		}
 	}
+	if v.File != nil {
+		sections++
+		if err := v.File.Validate(); err != nil {
+			return err
+		}
+	}
+	if v.Env != nil {
+		sections++
+		if err := v.Env.Validate(); err != nil {
+			return err
+		}
+	}
 	if sections == 0 {
-		return errors.New("you should specify one source to gather the variable: aws-kms or vault or cyberark-cli")
+		return errors.New("you should specify one source to gather the variable: aws-kms, vault, cyberark-cli, cyberark-api, obfuscated, file or env")
 	}
 	if sections > 1 {
 		return errors.New("you can't specify more than one source into a single variable. Use another variable")
@@ -297,6 +311,16 @@ func (v *varEntry) selectGatherer(ttl time.Duration) *gatherer {
 			cache: cachedEntry{ttl: ttl},
 			fetch: secrets.ObfuscateGatherer(v.Obfuscated),
 		}
+	} else if v.File != nil {
+		return &gatherer{
+			cache: cachedEntry{ttl: ttl},
+			fetch: secrets.FileGatherer(v.File),
+		}
+	} else if v.Env != nil {
+		return &gatherer{
+			cache: cachedEntry{ttl: ttl},
+			fetch: secrets.EnvGatherer(v.Env),
+		}
 	} else if v.Test != nil {
 		return &gatherer{
 			cache: cachedEntry{ttl: ttl},
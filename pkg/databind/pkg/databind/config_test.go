@@ -4,9 +4,13 @@
 package databind
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidYAMLs(t *testing.T) {
@@ -26,6 +30,27 @@ variables:
     vault:
       http:
         url: http://www.example.com
+`}, {"vault variable with approle auth and pinned kv v2 version", `
+variables:
+  myData:
+    vault:
+      http:
+        url: http://www.example.com/v1/secret/data/db
+      auth:
+        method: approle
+        role_id: my-role
+        secret_id_file: /etc/newrelic-infra/vault-secret-id
+      kv_version: 2
+      version: 3
+`}, {"vault variable with kubernetes auth", `
+variables:
+  myData:
+    vault:
+      http:
+        url: http://www.example.com/v1/secret/data/db
+      auth:
+        method: kubernetes
+        role: newrelic-infra
 `}, {"simple cyberark-cli variable", `
 variables:
   myData:
@@ -91,7 +116,15 @@ variables:
       safe: CYBER-ARK-SAFE
       folder: Root
       object:
-      `}, {"incomplete cyberark-api variable", `
+      `}, {"vault variable with unknown auth method", `
+variables:
+  myData:
+    vault:
+      http:
+        url: http://www.example.com/v1/secret/data/db
+      auth:
+        method: userpass
+`}, {"incomplete cyberark-api variable", `
 variables:
   myData:
     cyberark-api:
@@ -105,3 +138,33 @@ variables:
 		})
 	}
 }
+
+func TestFileAndEnvVariables(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, ioutil.WriteFile(path, []byte("secret\n"), 0600))
+	t.Setenv("NRIA_TEST_DB_USER", "admin")
+
+	sources, err := LoadYAML([]byte(fmt.Sprintf(`
+variables:
+  password:
+    file:
+      path: %s
+  user:
+    env:
+      name: NRIA_TEST_DB_USER
+`, path)))
+	require.NoError(t, err)
+
+	vals, err := Fetch(sources)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", vals.vars["password"])
+	assert.Equal(t, "admin", vals.vars["user"])
+
+	_, err = LoadYAML([]byte(`
+variables:
+  password:
+    file:
+      path: /non/existing/secret/path
+`))
+	assert.Error(t, err)
+}
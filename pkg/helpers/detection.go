@@ -18,4 +18,6 @@ const (
 	OS_UNKNOWN
 
 	LINUX_COREOS
+	LINUX_ALPINE
+	LINUX_ARCH
 )
@@ -49,6 +49,24 @@ ANSI_COLOR="1;32"
 HOME_URL="https://coreos.com/"
 BUG_REPORT_URL="https://github.com/coreos/bugs/issues"`,
 	)
+
+	ALPINE = []byte(`
+NAME="Alpine Linux"
+ID=alpine
+VERSION_ID=3.16.2
+PRETTY_NAME="Alpine Linux v3.16"
+HOME_URL="https://alpinelinux.org/"
+BUG_REPORT_URL="https://gitlab.alpinelinux.org/alpine/aports/-/issues"`,
+	)
+
+	MANJARO = []byte(`
+NAME="Manjaro Linux"
+ID=manjaro
+ID_LIKE=arch
+BUILD_ID=rolling
+PRETTY_NAME="Manjaro Linux"
+HOME_URL="https://manjaro.org/"`,
+	)
 )
 
 func (s *DetectionSuite) TestGetLinuxDistroCoreOS(c *C) {
@@ -83,6 +101,24 @@ func (s *DetectionSuite) TestGetLinuxDistro(c *C) {
 	c.Assert(val, Equals, LINUX_REDHAT)
 }
 
+func (s *DetectionSuite) TestGetLinuxDistroAlpineAndArch(c *C) {
+	tests := []struct {
+		osRelease []byte
+		distro    int
+	}{
+		{ALPINE, LINUX_ALPINE},
+		{MANJARO, LINUX_ARCH},
+	}
+	for _, tt := range tests {
+		tmpEtc := c.MkDir()
+		if err := ioutil.WriteFile(filepath.Join(tmpEtc, "os-release"), tt.osRelease, 0666); err != nil {
+			c.Fatal(err)
+		}
+		os.Setenv("HOST_ETC", tmpEtc)
+		c.Assert(GetLinuxDistro(), Equals, tt.distro)
+	}
+}
+
 func (s *DetectionSuite) TestGetLinuxOSInfo(c *C) {
 	tmpEtc, err := ioutil.TempDir("", "/testing")
 	if err != nil {
@@ -27,3 +27,11 @@ func TestHostVar(t *testing.T) {
 	newPath := HostVar("/testing", "test")
 	assert.Equal(t, filepath.Join("/dockervar/testing/test"), newPath)
 }
+
+func TestHostRoot(t *testing.T) {
+	path := HostRoot("/lib/apk")
+	assert.Equal(t, filepath.Join("/lib/apk"), path)
+	t.Setenv("HOST_ROOT", "/host")
+	newPath := HostRoot("/lib/apk")
+	assert.Equal(t, filepath.Join("/host/lib/apk"), newPath)
+}
@@ -47,6 +47,33 @@ type EnableConditions struct {
 	// EnvExists conditions the execution of the OHI only if the given
 	// environment variables exists and match the value.
 	EnvExists map[string]string `yaml:"env_exists"`
+	// ProcessRunning conditions the execution of the OHI only if a process whose name matches the
+	// given value is running. Regular expressions are accepted with the `regex "expr"` format.
+	ProcessRunning string `yaml:"process_running"`
+	// PortListening conditions the execution of the OHI only if the given TCP "host:port" or "port"
+	// (on localhost) accepts connections.
+	PortListening string `yaml:"port_listening"`
+	// Command conditions the execution of the OHI only if the given command exits with the
+	// expected exit code.
+	Command *CommandCondition `yaml:"command"`
+	// OS conditions the execution of the OHI only if the agent runs in the given OS (e.g. linux).
+	OS string `yaml:"os"`
+	// Distro conditions the execution of the OHI only if the host distribution name matches the
+	// given value. Regular expressions are accepted with the `regex "expr"` format.
+	Distro string `yaml:"distro"`
+	// All conditions the execution of the OHI only if all the nested conditions are true.
+	All []EnableConditions `yaml:"all"`
+	// Any conditions the execution of the OHI only if any of the nested conditions is true.
+	Any []EnableConditions `yaml:"any"`
+	// Not conditions the execution of the OHI only if the nested conditions are false.
+	Not *EnableConditions `yaml:"not"`
+}
+
+// CommandCondition executes a command and checks its exit code
+type CommandCondition struct {
+	Exec     ShlexOpt       `yaml:"exec"`
+	ExitCode int            `yaml:"exit_code"`
+	Timeout  *time.Duration `yaml:"timeout"`
 }
 
 // ShlexOpt is a wrapper around []string so we can use go-shlex for shell tokenizing
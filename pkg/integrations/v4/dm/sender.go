@@ -29,6 +29,10 @@ type MetricsSenderConfig struct {
 	SubmissionPeriod    time.Duration
 	MaxEntitiesPerReq   int
 	MaxEntitiesPerBatch int
+	// SpoolDir is the folder storing the failed submissions, to be sent again later. Empty when disabled.
+	SpoolDir     string
+	SpoolMaxSize int64
+	SpoolMaxAge  time.Duration
 }
 
 func NewConfig(url string, fedramp bool, licenseKey string, submissionPeriod time.Duration, maxEntitiesPerReq int, maxEntitiesPerBatch int) MetricsSenderConfig {
@@ -42,6 +46,15 @@ func NewConfig(url string, fedramp bool, licenseKey string, submissionPeriod tim
 	}
 }
 
+// WithSpool returns a copy of the config storing the failed submissions in the given folder, bounded
+// by size in bytes and age.
+func (c MetricsSenderConfig) WithSpool(dir string, maxSize int64, maxAge time.Duration) MetricsSenderConfig {
+	c.SpoolDir = dir
+	c.SpoolMaxSize = maxSize
+	c.SpoolMaxAge = maxAge
+	return c
+}
+
 // NewDMSender creates a Dimensional Metrics sender.
 func NewDMSender(config MetricsSenderConfig, transport http.RoundTripper, idProvide id.Provide) (s MetricsSender, err error) {
 	s = &sender{
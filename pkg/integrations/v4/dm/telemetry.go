@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/newrelic/infrastructure-agent/internal/agent/id"
+	"github.com/newrelic/infrastructure-agent/pkg/backend/spool"
 	telemetry "github.com/newrelic/infrastructure-agent/pkg/backend/telemetryapi"
 	"github.com/newrelic/infrastructure-agent/pkg/integrations/v4/protocol"
 	"github.com/newrelic/infrastructure-agent/pkg/log"
@@ -57,6 +58,7 @@ func newTelemetryHarverster(conf MetricsSenderConfig, transport http.RoundTrippe
 		telemetry.ConfigHarvestPeriod(conf.SubmissionPeriod),
 		telemetry.ConfigMaxEntitiesPerRequest(conf.MaxEntitiesPerReq),
 		telemetry.ConfigMaxEntitiesPerBatch(conf.MaxEntitiesPerBatch),
+		telemetryHarvesterWithSpool(conf),
 	)
 }
 
@@ -78,6 +80,21 @@ func telemetryHarvesterWithFedramp(fedramp bool) func(*telemetry.Config) {
 	}
 }
 
+func telemetryHarvesterWithSpool(conf MetricsSenderConfig) func(*telemetry.Config) {
+	return func(config *telemetry.Config) {
+		if conf.SpoolDir == "" {
+			return
+		}
+		s, err := spool.New(conf.SpoolDir, conf.SpoolMaxSize, conf.SpoolMaxAge)
+		if err != nil {
+			logger.WithError(err).WithField("dir", conf.SpoolDir).Warn("cannot create dimensional metrics spool, failed submissions will be dropped")
+			return
+		}
+		logger.WithField("dir", conf.SpoolDir).WithField("spooledSubmissions", s.Depth()).Debug("Dimensional metrics spool enabled.")
+		config.Spool = s
+	}
+}
+
 type Conversion struct {
 	toTelemetry         Converter
 	toMultipleTelemetry DerivingConvertor
@@ -9,6 +9,7 @@ import (
 	"github.com/newrelic/infrastructure-agent/pkg/entity/host"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/newrelic/infrastructure-agent/pkg/integrations/v4/fs"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/integration"
 	"github.com/newrelic/infrastructure-agent/internal/integrations/v4/runner"
 	"github.com/newrelic/infrastructure-agent/pkg/integrations/v4/emitter"
@@ -30,6 +32,9 @@ import (
 
 var illog = log.WithComponent("integrations.Manager")
 
+// ErrIntegrationNotFound is returned when a requested integration is not found in any running group.
+var ErrIntegrationNotFound = errors.New("integration not found")
+
 // runner-groups contexts indexed per config path, bundling lock to support concurrent access.
 type rgsPerPath struct {
 	l sync.RWMutex
@@ -88,6 +93,7 @@ func (r *rgsPerPath) isGroupRunning(cfgPath string) bool {
 
 type Manager struct {
 	managerConfig            ManagerConfig
+	pathsLock                sync.Mutex // protects managerConfig.ConfigPaths on reloads
 	configLoader             v4Config.Loader
 	watcher                  *fsnotify.Watcher
 	runners                  *rgsPerPath
@@ -108,6 +114,7 @@ type groupContext struct {
 	l      sync.RWMutex
 	cancel func() // nil when there's no cancellable context
 	runner runner.Group
+	cmdFF  *runner.CmdFF // command channel feature flag enabling the group, nil when there's none
 }
 
 func newGroupContext(gr runner.Group) *groupContext {
@@ -147,6 +154,39 @@ func (g *groupContext) isRunning() bool {
 	return g.cancel != nil
 }
 
+// stopIntegration stops the running integrations with the passed name, returning false if there is none.
+func (g *groupContext) stopIntegration(name string) bool {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	return g.runner.Stop(name)
+}
+
+// restartIntegration restarts the integrations with the passed name, returning false if there is none.
+func (g *groupContext) restartIntegration(name string) bool {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	if g.cancel == nil {
+		return false
+	}
+	return g.runner.Restart(name)
+}
+
+func (g *groupContext) runIntegrationOnce(ctx context.Context, name string) bool {
+	g.l.RLock()
+	defer g.l.RUnlock()
+
+	return g.runner.RunOnceNamed(ctx, name)
+}
+
+func (g *groupContext) status() []runner.RunStatus {
+	g.l.RLock()
+	defer g.l.RUnlock()
+
+	return g.runner.Status()
+}
+
 type ManagerConfig struct {
 	// ConfigPaths store the YAML integrations configurations.
 	// They may also contain -config.yml files from v3 integrations
@@ -276,6 +316,158 @@ func (mgr *Manager) RunOnce(ctx context.Context) {
 	wg.Wait()
 }
 
+// IntegrationsStatus returns the status of the integrations from the started runner groups, sorted by
+// config file.
+func (mgr *Manager) IntegrationsStatus() []status.IntegrationReport {
+	groups := mgr.runners.List()
+	paths := make([]string, 0, len(groups))
+	for path := range groups {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var reports []status.IntegrationReport
+	for _, path := range paths {
+		for _, st := range groups[path].status() {
+			reports = append(reports, status.IntegrationReport{
+				Name:         st.Name,
+				ConfigFile:   path,
+				Running:      st.Running,
+				LastRun:      status.FormatTime(st.LastRun),
+				NextRun:      status.FormatTime(st.NextRun),
+				LastExitCode: st.LastExitCode,
+				LastError:    st.LastError,
+				StderrTail:   st.StderrTail,
+			})
+		}
+	}
+	return reports
+}
+
+// RunIntegration executes once, in background, the integrations with the passed name.
+func (mgr *Manager) RunIntegration(ctx context.Context, name string) error {
+	var found bool
+	for _, group := range mgr.runners.List() {
+		if group.runIntegrationOnce(contextWithVerbose(ctx, mgr.managerConfig.Verbose), name) {
+			found = true
+		}
+	}
+	if !found {
+		return ErrIntegrationNotFound
+	}
+	return nil
+}
+
+// StopIntegration stops the running integrations with the passed name. They remain stopped until
+// they are restarted or their configuration file is reloaded.
+func (mgr *Manager) StopIntegration(name string) error {
+	var found bool
+	for path, group := range mgr.runners.List() {
+		if group.stopIntegration(name) {
+			illog.WithField("file", path).WithField("integration_name", name).Info("Integration stopped.")
+			found = true
+		}
+	}
+	if !found {
+		return ErrIntegrationNotFound
+	}
+	return nil
+}
+
+// RestartIntegration stops the integrations with the passed name, if running, and starts them again.
+func (mgr *Manager) RestartIntegration(name string) error {
+	var found bool
+	for path, group := range mgr.runners.List() {
+		if group.restartIntegration(name) {
+			illog.WithField("file", path).WithField("integration_name", name).Info("Integration restarted.")
+			found = true
+		}
+	}
+	if !found {
+		return ErrIntegrationNotFound
+	}
+	return nil
+}
+
+// Reload stops all the integrations and starts them again from the configuration folders, picking up
+// any configuration change. Integrations enabled through command channel feature flags are kept enabled.
+func (mgr *Manager) Reload(ctx context.Context) {
+	mgr.pathsLock.Lock()
+	defer mgr.pathsLock.Unlock()
+
+	cmdFFs := make(map[string]*runner.CmdFF)
+	for path, group := range mgr.runners.List() {
+		if group.cmdFF != nil {
+			cmdFFs[path] = group.cmdFF
+		}
+		mgr.stopRunnerGroup(path)
+	}
+
+	for _, path := range mgr.managerConfig.ConfigPaths {
+		flog := illog.WithField("path", path)
+
+		configs, err := mgr.configLoader.Load(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				flog.WithError(err).Warn("can't load path. Ignoring")
+			}
+			continue
+		}
+
+		for cfgPath, cfg := range configs {
+			rc, err := mgr.loadRunnerGroup(cfgPath, cfg, cmdFFs[cfgPath])
+			if err != nil {
+				flog.WithField("file", cfgPath).WithError(err).Warn("can't instantiate integrations from file")
+				continue
+			}
+			mgr.runners.Set(cfgPath, rc)
+			rc.start(contextWithVerbose(ctx, mgr.managerConfig.Verbose))
+		}
+	}
+	illog.Info("Integrations reloaded.")
+}
+
+// SetConfigPaths replaces the integrations configuration folders, watching the new ones for changes
+// instead of the former ones. Integrations from the new folders are started on the next Reload.
+func (mgr *Manager) SetConfigPaths(paths []string) {
+	mgr.pathsLock.Lock()
+	defer mgr.pathsLock.Unlock()
+
+	if mgr.watcher != nil {
+		for _, path := range mgr.managerConfig.ConfigPaths {
+			if containsPath(paths, path) {
+				continue
+			}
+			_ = mgr.watcher.Remove(path)
+			for cfgPath := range mgr.runners.List() {
+				if filepath.Dir(cfgPath) == path {
+					_ = mgr.watcher.Remove(cfgPath)
+				}
+			}
+		}
+		for _, path := range paths {
+			if containsPath(mgr.managerConfig.ConfigPaths, path) {
+				continue
+			}
+			if err := mgr.watcher.Add(path); err != nil && !os.IsNotExist(err) {
+				illog.WithField("path", path).WithError(err).Warn("cant watch for file changes in folder")
+			}
+		}
+	}
+
+	mgr.managerConfig.ConfigPaths = paths
+	illog.WithField("paths", paths).Info("Integrations configuration folders changed.")
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
 // EnableOHIFromFF enables an integration coming from CC request.
 func (mgr *Manager) EnableOHIFromFF(ctx context.Context, featureFlag string) error {
 	cfgPath, err := mgr.cfgPathForFF(featureFlag)
@@ -330,7 +522,9 @@ func (mgr *Manager) loadRunnerGroup(path string, cfg v4Config.YAML, cmdFF *runne
 
 	mgr.featuresCache.Update(fc)
 
-	return newGroupContext(gr), nil
+	gc := newGroupContext(gr)
+	gc.cmdFF = cmdFF
+	return gc, nil
 }
 
 func (mgr *Manager) handleRequestsQueue(ctx context.Context) {
@@ -767,6 +767,38 @@ func TestManager_EnableFeature_WhenFeatureOnOHICfgAndAgentCfgIsDisabledAndEnable
 	require.Equal(t, "hello", metric["value"])
 }
 
+func TestManager_Reload_KeepsFeatureEnabledFromCmdCh(t *testing.T) {
+	// GIVEN a configuration file for an OHI with a feature disabled in the agent config
+	dir, err := tempFiles(map[string]string{
+		"foo.yaml": v4FileWithNriDockerNameAndDockerFF,
+	})
+	require.NoError(t, err)
+	defer removeTempFiles(t, dir)
+
+	e := &testemit.RecordEmitter{}
+	mgr := NewManager(ManagerConfig{
+		ConfigPaths:            []string{dir},
+		PassthroughEnvironment: passthroughEnv,
+	}, config.NewPathLoader(), e, integration.ErrLookup, definitionQ, configEntryQ, track.NewTracker(nil), host.IDLookup{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mgr.Start(ctx)
+
+	// AND the OHI enabled through the command channel
+	require.NoError(t, mgr.EnableOHIFromFF(ctx, "docker_enabled"))
+	expectOneMetric(t, e, "nri-docker")
+
+	// WHEN the integrations are reloaded
+	mgr.Reload(ctx)
+
+	// THEN the integration is still enabled
+	group, ok := mgr.runners.Get(filepath.Join(dir, "foo.yaml"))
+	require.True(t, ok)
+	assert.True(t, group.isRunning())
+	require.Len(t, mgr.IntegrationsStatus(), 1)
+	assert.Equal(t, "nri-docker", mgr.IntegrationsStatus()[0].Name)
+}
+
 func TestManager_EnableFeatureFromAgentConfig(t *testing.T) {
 	// GIVEN a configuration file for an OHI with feature in it
 	dir, err := tempFiles(map[string]string{
@@ -1280,3 +1312,34 @@ func instancesLookupLegacy(definitionFolders ...string) integration.InstancesLoo
 		},
 	}
 }
+
+func TestManager_SetConfigPaths(t *testing.T) {
+	// GIVEN two integrations folders, the manager running the first one
+	firstDir, err := tempFiles(map[string]string{
+		"first.yaml": v4File,
+	})
+	require.NoError(t, err)
+	defer removeTempFiles(t, firstDir)
+	secondDir, err := tempFiles(map[string]string{
+		"second.yaml": v4FileWithConfigYAML,
+	})
+	require.NoError(t, err)
+	defer removeTempFiles(t, secondDir)
+
+	emitter := &testemit.RecordEmitter{}
+	mgr := NewManager(ManagerConfig{ConfigPaths: []string{firstDir}, PassthroughEnvironment: passthroughEnv}, config.NewPathLoader(), emitter, integration.ErrLookup, definitionQ, configEntryQ, track.NewTracker(nil), host.IDLookup{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mgr.Start(ctx)
+	expectOneMetric(t, emitter, "hello-test")
+
+	// WHEN the configuration folders are replaced and the integrations reloaded
+	mgr.SetConfigPaths([]string{secondDir})
+	mgr.Reload(ctx)
+
+	// THEN only the integrations from the new folder are run
+	statuses := mgr.IntegrationsStatus()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "config-test", statuses[0].Name)
+	assert.Equal(t, filepath.Join(secondDir, "second.yaml"), statuses[0].ConfigFile)
+}
@@ -143,9 +143,19 @@ func GetFormatter() logrus.Formatter {
 	return w.l.Formatter
 }
 
+// levelChanges counts the standard logger level changes, so a temporary level isn't restored over a level
+// set meanwhile.
+var (
+	levelLock    sync.Mutex
+	levelChanges uint64
+)
+
 // SetLevel sets the standard logger level.
 func SetLevel(level logrus.Level) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
 	w.l.SetLevel(level)
+	levelChanges++
 }
 
 // GetLevel returns the standard logger level.
@@ -3,6 +3,7 @@
 package log
 
 import (
+	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -13,28 +14,56 @@ var vlog = WithComponent("Verboser")
 // DefaultVerboseMin default verbose time range in minutes.
 const DefaultVerboseMin = 5
 
+// ErrTemporaryLevelSet is returned when a temporary log level is requested while another one is set.
+var ErrTemporaryLevelSet = errors.New("temporary log level already set")
+
 // We don't want to EnableTemporalVerbose if it's already enabled.
 var sem = make(chan struct{}, 1)
 
 // EnableTemporaryVerbose enables verbose logging for a given amount of minutes.
 func EnableTemporaryVerbose() {
+	_ = SetTemporaryLevel(logrus.TraceLevel, time.Duration(DefaultVerboseMin)*time.Minute)
+}
+
+// SetTemporaryLevel sets the log level for the given duration, restoring the previous one afterwards unless
+// the level was changed meanwhile, e.g. by a config reload. It fails if there is a temporary log level already set.
+func SetTemporaryLevel(level logrus.Level, duration time.Duration) error {
 	if !shouldRun() {
-		return
+		return ErrTemporaryLevelSet
 	}
 
-	prevLvl := GetLevel()
-
-	vlog.WithField("durationMin", DefaultVerboseMin).Info("setting temporal verbose logs")
-	SetLevel(logrus.TraceLevel)
+	vlog.WithField("level", level.String()).WithField("duration", duration.String()).Info("setting temporal log level")
+	levelLock.Lock()
+	prevLvl := w.l.GetLevel()
+	w.l.SetLevel(level)
+	levelChanges++
+	changes := levelChanges
+	levelLock.Unlock()
 
 	go func() {
 		defer finish()
 
-		time.Sleep(time.Duration(DefaultVerboseMin) * time.Minute)
+		time.Sleep(duration)
 
-		SetLevel(prevLvl)
-		vlog.WithField("level", prevLvl.String()).Info("Temporal verbose log end, restored previous log level")
+		if !restoreLevel(prevLvl, changes) {
+			vlog.WithField("level", GetLevel().String()).Info("Temporal log level end, keeping the log level set meanwhile")
+			return
+		}
+		vlog.WithField("level", prevLvl.String()).Info("Temporal log level end, restored previous log level")
 	}()
+	return nil
+}
+
+// restoreLevel sets the given level if there were no level changes after the given count of them.
+func restoreLevel(level logrus.Level, changes uint64) bool {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	if levelChanges != changes {
+		return false
+	}
+	w.l.SetLevel(level)
+	levelChanges++
+	return true
 }
 
 // finish will be called when log level is restored.
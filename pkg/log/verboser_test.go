@@ -0,0 +1,47 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func temporaryLevelEnded() bool {
+	return len(sem) == 0
+}
+
+func TestSetTemporaryLevel_RestoresPreviousLevel(t *testing.T) {
+	defer SetLevel(GetLevel())
+
+	// GIVEN the info log level
+	SetLevel(logrus.InfoLevel)
+
+	// WHEN a temporary debug level is set
+	require.NoError(t, SetTemporaryLevel(logrus.DebugLevel, 10*time.Millisecond))
+	assert.Equal(t, logrus.DebugLevel, GetLevel())
+	assert.Equal(t, ErrTemporaryLevelSet, SetTemporaryLevel(logrus.TraceLevel, time.Millisecond))
+
+	// THEN the info level is restored when it ends
+	require.Eventually(t, temporaryLevelEnded, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, logrus.InfoLevel, GetLevel())
+}
+
+func TestSetTemporaryLevel_KeepsLevelSetMeanwhile(t *testing.T) {
+	defer SetLevel(GetLevel())
+
+	// GIVEN a temporary debug level over the info level
+	SetLevel(logrus.InfoLevel)
+	require.NoError(t, SetTemporaryLevel(logrus.DebugLevel, 10*time.Millisecond))
+
+	// WHEN the level is changed meanwhile, as a config reload does
+	SetLevel(logrus.WarnLevel)
+
+	// THEN the level isn't restored when the temporary one ends
+	require.Eventually(t, temporaryLevelEnded, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, logrus.WarnLevel, GetLevel())
+}
@@ -0,0 +1,277 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package cgroup
+
+import (
+	"fmt"
+	"path"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
+)
+
+var sslog = log.WithComponent("CgroupSampler")
+
+const (
+	cgroupV1 = "v1"
+	cgroupV2 = "v2"
+)
+
+type Sampler struct {
+	context   agent.AgentContext
+	lastRun   time.Time
+	lastStats map[string]*stats
+}
+
+// stats are the values read from the hierarchy of a cgroup. Values not supported by the cgroup version or
+// whose controller isn't enabled are nil.
+type stats struct {
+	path    string
+	version string
+
+	cpuUsageUsec        *uint64
+	cpuUserUsec         *uint64
+	cpuSystemUsec       *uint64
+	cpuPeriods          *uint64
+	cpuThrottledPeriods *uint64
+	cpuThrottledUsec    *uint64
+
+	memoryCurrent  *uint64
+	memoryMax      *uint64
+	memoryOoms     *uint64
+	memoryOomKills *uint64
+	ioReadBytes    *uint64
+	ioWriteBytes   *uint64
+	ioReads        *uint64
+	ioWrites       *uint64
+	pidsCurrent    *uint64
+	pidsMax        *uint64
+}
+
+type Sample struct {
+	sample.BaseEvent
+
+	// Path of the cgroup, relative to the root of the hierarchy
+	CgroupPath string `json:"cgroupPath"`
+	// Version of the cgroup hierarchy (v1 or v2)
+	CgroupVersion string `json:"cgroupVersion"`
+	// Percentage of CPU time used by the cgroup, relative to a single core
+	CPUPercent *float64 `json:"cpuPercent,omitempty"`
+	// Percentage of CPU time used by the cgroup in user space, relative to a single core
+	CPUUserPercent *float64 `json:"cpuUserPercent,omitempty"`
+	// Percentage of CPU time used by the cgroup in kernel space, relative to a single core
+	CPUSystemPercent *float64 `json:"cpuSystemPercent,omitempty"`
+	// Number of enforcement periods in which the cgroup was throttled, per second
+	CPUThrottledPeriodsPerSec *float64 `json:"cpuThrottledPeriodsPerSecond,omitempty"`
+	// Percentage of the enforcement periods in which the cgroup was throttled
+	CPUThrottledPeriodsPercent *float64 `json:"cpuThrottledPeriodsPercent,omitempty"`
+	// Percentage of time the cgroup was throttled
+	CPUThrottledTimePercent *float64 `json:"cpuThrottledTimePercent,omitempty"`
+	// Memory used by the cgroup, including page cache
+	MemoryUsedBytes *uint64 `json:"memoryUsedBytes,omitempty"`
+	// Memory limit of the cgroup. Not reported when the cgroup has no limit
+	MemoryLimitBytes *uint64 `json:"memoryLimitBytes,omitempty"`
+	// Percentage of the memory limit used by the cgroup
+	MemoryUsedPercent *float64 `json:"memoryUsedPercent,omitempty"`
+	// Number of times the cgroup reached its memory limit and the OOM killer was invoked, since the previous sample
+	MemoryOomEvents *uint64 `json:"memoryOomEvents,omitempty"`
+	// Number of processes of the cgroup killed by the OOM killer, since the previous sample
+	MemoryOomKillEvents *uint64 `json:"memoryOomKillEvents,omitempty"`
+	// Number of bytes read per second by the cgroup
+	IOReadBytesPerSec *float64 `json:"ioReadBytesPerSecond,omitempty"`
+	// Number of bytes written per second by the cgroup
+	IOWriteBytesPerSec *float64 `json:"ioWriteBytesPerSecond,omitempty"`
+	// Number of read operations per second performed by the cgroup
+	IOReadsPerSec *float64 `json:"ioReadsPerSecond,omitempty"`
+	// Number of write operations per second performed by the cgroup
+	IOWritesPerSec *float64 `json:"ioWritesPerSecond,omitempty"`
+	// Number of processes in the cgroup
+	PidsCount *uint64 `json:"pidsCount,omitempty"`
+	// Maximum number of processes allowed in the cgroup. Not reported when the cgroup has no limit
+	PidsLimit *uint64 `json:"pidsLimit,omitempty"`
+}
+
+func (s *Sampler) OnStartup() {}
+
+func (s *Sampler) Name() string {
+	return "CgroupSampler"
+}
+
+func (s *Sampler) Interval() time.Duration {
+	return time.Second * time.Duration(s.context.Config().MetricsCgroupSampleRate)
+}
+
+func (s *Sampler) Disabled() bool {
+	return s.Interval() <= config.FREQ_DISABLE_SAMPLING
+}
+
+func (s *Sampler) Sample() (eventBatch sample.EventBatch, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("Panic in cgroup.Sampler: %v\nStack: %s", panicErr, debug.Stack())
+		}
+	}()
+
+	cfg := s.context.Config()
+	filter := newPathFilter(cfg.CgroupIncludePaths, cfg.CgroupExcludePaths)
+	current, err := readStats(filter.match)
+	if err != nil {
+		sslog.WithError(err).Debug("Unable to retrieve cgroup stats.")
+		return nil, nil
+	}
+
+	now := time.Now()
+	elapsedSeconds := now.Sub(s.lastRun).Seconds()
+
+	paths := make([]string, 0, len(current))
+	for p := range current {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		ss := newSample(current[p], s.lastStats[p], elapsedSeconds)
+		ss.Type("CgroupSample")
+		eventBatch = append(eventBatch, ss)
+	}
+
+	s.lastStats = current
+	s.lastRun = now
+	return eventBatch, nil
+}
+
+// newSample builds the sample of a cgroup. Rates and event counts are only reported when the previous
+// stats of the cgroup are available.
+func newSample(current, previous *stats, elapsedSeconds float64) *Sample {
+	ss := &Sample{
+		CgroupPath:       current.path,
+		CgroupVersion:    current.version,
+		MemoryUsedBytes:  current.memoryCurrent,
+		MemoryLimitBytes: current.memoryMax,
+		PidsCount:        current.pidsCurrent,
+		PidsLimit:        current.pidsMax,
+	}
+	if current.memoryCurrent != nil && current.memoryMax != nil && *current.memoryMax > 0 {
+		ss.MemoryUsedPercent = floatPtr(float64(*current.memoryCurrent) / float64(*current.memoryMax) * 100)
+	}
+
+	if previous == nil || elapsedSeconds <= 0 {
+		return ss
+	}
+
+	ss.MemoryOomEvents = delta(current.memoryOoms, previous.memoryOoms)
+	ss.MemoryOomKillEvents = delta(current.memoryOomKills, previous.memoryOomKills)
+
+	elapsedUsec := elapsedSeconds * float64(time.Second/time.Microsecond)
+	ss.CPUPercent = percent(current.cpuUsageUsec, previous.cpuUsageUsec, elapsedUsec)
+	ss.CPUUserPercent = percent(current.cpuUserUsec, previous.cpuUserUsec, elapsedUsec)
+	ss.CPUSystemPercent = percent(current.cpuSystemUsec, previous.cpuSystemUsec, elapsedUsec)
+	ss.CPUThrottledTimePercent = percent(current.cpuThrottledUsec, previous.cpuThrottledUsec, elapsedUsec)
+	ss.CPUThrottledPeriodsPerSec = rate(current.cpuThrottledPeriods, previous.cpuThrottledPeriods, elapsedSeconds)
+	if current.cpuPeriods != nil && previous.cpuPeriods != nil && *current.cpuPeriods > *previous.cpuPeriods {
+		ss.CPUThrottledPeriodsPercent = percent(current.cpuThrottledPeriods, previous.cpuThrottledPeriods,
+			float64(*current.cpuPeriods-*previous.cpuPeriods))
+	}
+	ss.IOReadBytesPerSec = rate(current.ioReadBytes, previous.ioReadBytes, elapsedSeconds)
+	ss.IOWriteBytesPerSec = rate(current.ioWriteBytes, previous.ioWriteBytes, elapsedSeconds)
+	ss.IOReadsPerSec = rate(current.ioReads, previous.ioReads, elapsedSeconds)
+	ss.IOWritesPerSec = rate(current.ioWrites, previous.ioWrites, elapsedSeconds)
+	return ss
+}
+
+// delta returns the increase of a counter. When it's lower than the previous value, the cgroup has been
+// created again, so its current value is the increase.
+func delta(current, previous *uint64) *uint64 {
+	if current == nil || previous == nil {
+		return nil
+	}
+	if *current < *previous {
+		return uintPtr(*current)
+	}
+	return uintPtr(*current - *previous)
+}
+
+func rate(current, previous *uint64, elapsedSeconds float64) *float64 {
+	if current == nil || previous == nil {
+		return nil
+	}
+	return floatPtr(acquire.CalculateSafeDelta(*current, *previous, elapsedSeconds))
+}
+
+func percent(current, previous *uint64, total float64) *float64 {
+	if current == nil || previous == nil {
+		return nil
+	}
+	return floatPtr(acquire.CalculateSafeDelta(*current, *previous, total) * 100)
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func uintPtr(u uint64) *uint64 {
+	return &u
+}
+
+// pathFilter selects the cgroups to sample by their path, relative to the root of the hierarchy.
+type pathFilter struct {
+	include []string
+	exclude []string
+}
+
+func newPathFilter(include, exclude []string) pathFilter {
+	return pathFilter{include: include, exclude: exclude}
+}
+
+// match returns true when the path matches any of the include patterns, or there are none, and it doesn't
+// match any of the exclude patterns.
+func (f pathFilter) match(cgroupPath string) bool {
+	for _, pattern := range f.exclude {
+		if matchPattern(pattern, cgroupPath) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, pattern := range f.include {
+		if matchPattern(pattern, cgroupPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches a path against a path.Match pattern. Patterns ending in "/**" also match the
+// descendants of the matching paths.
+func matchPattern(pattern, cgroupPath string) bool {
+	if !strings.HasSuffix(pattern, "/**") {
+		matched, _ := path.Match(pattern, cgroupPath)
+		return matched
+	}
+	prefix := strings.TrimSuffix(pattern, "/**")
+	if prefix == "" {
+		return true
+	}
+	for p := cgroupPath; ; p = path.Dir(p) {
+		if matched, _ := path.Match(prefix, p); matched {
+			return true
+		}
+		if p == "/" || p == "." {
+			return false
+		}
+	}
+}
+
+func NewSampler(context agent.AgentContext) *Sampler {
+	return &Sampler{
+		context:   context,
+		lastStats: map[string]*stats{},
+	}
+}
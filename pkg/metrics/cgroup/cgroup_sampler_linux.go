@@ -0,0 +1,267 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package cgroup
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+)
+
+// ErrCgroupNotFound is returned when no cgroup hierarchy is mounted.
+var ErrCgroupNotFound = errors.New("no cgroup hierarchy found")
+
+const (
+	// clockTicks is the USER_HZ value, the unit of the v1 cpuacct.stat values.
+	clockTicks = 100
+	// memoryUnlimitedV1 is the lowest value reported by v1 memory.limit_in_bytes when there is no limit,
+	// which is the maximum int64 value rounded down to the page size.
+	memoryUnlimitedV1 = 1 << 62
+)
+
+// readStats reads the stats of all the cgroups accepted by the filter, from the unified hierarchy when
+// cgroup v2 is mounted, or from the v1 controllers hierarchies otherwise.
+func readStats(accept func(cgroupPath string) bool) (map[string]*stats, error) {
+	root := helpers.HostSys("fs", "cgroup")
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return readStatsV2(root, accept)
+	}
+	return readStatsV1(root, accept)
+}
+
+func readStatsV2(root string, accept func(string) bool) (map[string]*stats, error) {
+	result := map[string]*stats{}
+	err := walk(root, accept, func(cgroupPath, dir string) {
+		st := &stats{path: cgroupPath, version: cgroupV2}
+
+		cpu := readFlatKeyed(filepath.Join(dir, "cpu.stat"))
+		st.cpuUsageUsec = cpu["usage_usec"]
+		st.cpuUserUsec = cpu["user_usec"]
+		st.cpuSystemUsec = cpu["system_usec"]
+		st.cpuPeriods = cpu["nr_periods"]
+		st.cpuThrottledPeriods = cpu["nr_throttled"]
+		st.cpuThrottledUsec = cpu["throttled_usec"]
+
+		st.memoryCurrent = readUint(filepath.Join(dir, "memory.current"))
+		st.memoryMax = readUint(filepath.Join(dir, "memory.max"))
+		events := readFlatKeyed(filepath.Join(dir, "memory.events"))
+		st.memoryOoms = events["oom"]
+		st.memoryOomKills = events["oom_kill"]
+
+		io := readIOStatV2(filepath.Join(dir, "io.stat"))
+		st.ioReadBytes = io["rbytes"]
+		st.ioWriteBytes = io["wbytes"]
+		st.ioReads = io["rios"]
+		st.ioWrites = io["wios"]
+
+		st.pidsCurrent = readUint(filepath.Join(dir, "pids.current"))
+		st.pidsMax = readUint(filepath.Join(dir, "pids.max"))
+
+		result[cgroupPath] = st
+	})
+	return result, err
+}
+
+// readStatsV1 reads the stats from each v1 controller hierarchy, merging them by cgroup path.
+func readStatsV1(root string, accept func(string) bool) (map[string]*stats, error) {
+	result := map[string]*stats{}
+	get := func(cgroupPath string) *stats {
+		st, ok := result[cgroupPath]
+		if !ok {
+			st = &stats{path: cgroupPath, version: cgroupV1}
+			result[cgroupPath] = st
+		}
+		return st
+	}
+
+	found := false
+	walkController := func(walkFn func(st *stats, dir string), controllers ...string) {
+		for _, c := range controllers {
+			controllerRoot, err := filepath.EvalSymlinks(filepath.Join(root, c))
+			if err != nil {
+				continue
+			}
+			found = true
+			err = walk(controllerRoot, accept, func(cgroupPath, dir string) {
+				walkFn(get(cgroupPath), dir)
+			})
+			if err != nil {
+				sslog.WithError(err).WithField("controller", c).Debug("Unable to walk cgroup hierarchy.")
+			}
+			return
+		}
+	}
+
+	walkController(func(st *stats, dir string) {
+		if usage := readUint(filepath.Join(dir, "cpuacct.usage")); usage != nil {
+			st.cpuUsageUsec = uintPtr(*usage / 1000)
+		}
+		cpuacct := readFlatKeyed(filepath.Join(dir, "cpuacct.stat"))
+		if user := cpuacct["user"]; user != nil {
+			st.cpuUserUsec = uintPtr(*user * 1e6 / clockTicks)
+		}
+		if system := cpuacct["system"]; system != nil {
+			st.cpuSystemUsec = uintPtr(*system * 1e6 / clockTicks)
+		}
+	}, "cpuacct", "cpu,cpuacct")
+
+	walkController(func(st *stats, dir string) {
+		cpu := readFlatKeyed(filepath.Join(dir, "cpu.stat"))
+		st.cpuPeriods = cpu["nr_periods"]
+		st.cpuThrottledPeriods = cpu["nr_throttled"]
+		if throttled := cpu["throttled_time"]; throttled != nil {
+			st.cpuThrottledUsec = uintPtr(*throttled / 1000)
+		}
+	}, "cpu", "cpu,cpuacct")
+
+	walkController(func(st *stats, dir string) {
+		st.memoryCurrent = readUint(filepath.Join(dir, "memory.usage_in_bytes"))
+		if limit := readUint(filepath.Join(dir, "memory.limit_in_bytes")); limit != nil && *limit < memoryUnlimitedV1 {
+			st.memoryMax = limit
+		}
+		st.memoryOomKills = readFlatKeyed(filepath.Join(dir, "memory.oom_control"))["oom_kill"]
+	}, "memory")
+
+	walkController(func(st *stats, dir string) {
+		serviceBytes := readBlkioV1(filepath.Join(dir, "blkio.throttle.io_service_bytes"))
+		st.ioReadBytes = serviceBytes["Read"]
+		st.ioWriteBytes = serviceBytes["Write"]
+		serviced := readBlkioV1(filepath.Join(dir, "blkio.throttle.io_serviced"))
+		st.ioReads = serviced["Read"]
+		st.ioWrites = serviced["Write"]
+	}, "blkio")
+
+	walkController(func(st *stats, dir string) {
+		st.pidsCurrent = readUint(filepath.Join(dir, "pids.current"))
+		st.pidsMax = readUint(filepath.Join(dir, "pids.max"))
+	}, "pids")
+
+	if !found {
+		return nil, ErrCgroupNotFound
+	}
+	return result, nil
+}
+
+// walk calls walkFn for each cgroup of the hierarchy accepted by the filter, with its path relative to the
+// root of the hierarchy and its directory.
+func walk(root string, accept func(string) bool, walkFn func(cgroupPath, dir string)) error {
+	return filepath.WalkDir(root, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// cgroups may be removed while walking the hierarchy
+			if dir != root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return err
+		}
+		cgroupPath := "/" + filepath.ToSlash(rel)
+		if rel == "." {
+			cgroupPath = "/"
+		}
+		if accept(cgroupPath) {
+			walkFn(cgroupPath, dir)
+		}
+		return nil
+	})
+}
+
+// readUint reads a file holding a single value. It returns nil when the file doesn't exist or holds "max".
+func readUint(file string) *uint64 {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// readFlatKeyed reads a file with a "key value" pair per line.
+func readFlatKeyed(file string) map[string]*uint64 {
+	result := map[string]*uint64{}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return result
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			result[fields[0]] = &value
+		}
+	}
+	return result
+}
+
+// readIOStatV2 reads the io.stat file, whose lines have the "MAJ:MIN key=value ..." format, adding up the
+// values of all the devices.
+func readIOStatV2(file string) map[string]*uint64 {
+	result := map[string]*uint64{}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return result
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			if value, err := strconv.ParseUint(kv[1], 10, 64); err == nil {
+				add(result, kv[0], value)
+			}
+		}
+	}
+	return result
+}
+
+// readBlkioV1 reads a blkio file, whose lines have the "MAJ:MIN operation value" format, adding up the
+// values of all the devices.
+func readBlkioV1(file string) map[string]*uint64 {
+	result := map[string]*uint64{}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return result
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			add(result, fields[1], value)
+		}
+	}
+	return result
+}
+
+func add(values map[string]*uint64, key string, value uint64) {
+	if current, ok := values[key]; ok {
+		*current += value
+		return
+	}
+	values[key] = &value
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package cgroup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+}
+
+func TestSampler_Sample_V2(t *testing.T) {
+	// GIVEN a unified cgroup hierarchy
+	sys := t.TempDir()
+	t.Setenv("HOST_SYS", sys)
+	root := filepath.Join(sys, "fs", "cgroup")
+	writeFiles(t, root, map[string]string{
+		"cgroup.controllers":                        "cpu io memory pids\n",
+		"cpu.stat":                                  "usage_usec 100\n",
+		"system.slice/a.service/cpu.stat":           "usage_usec 5000\nuser_usec 3000\nsystem_usec 2000\nnr_periods 10\nnr_throttled 1\nthrottled_usec 300\n",
+		"system.slice/a.service/memory.current":     "1024\n",
+		"system.slice/a.service/memory.max":         "max\n",
+		"system.slice/a.service/memory.events":      "low 0\nhigh 0\nmax 4\noom 2\noom_kill 1\n",
+		"system.slice/a.service/io.stat":            "8:0 rbytes=100 wbytes=200 rios=1 wios=2 dbytes=0 dios=0\n8:16 rbytes=10 wbytes=20 rios=1 wios=1 dbytes=0 dios=0\n",
+		"system.slice/a.service/pids.current":       "7\n",
+		"system.slice/a.service/pids.max":           "100\n",
+		"user.slice/user-1000.slice/cpu.stat":       "usage_usec 1\n",
+		"user.slice/user-1000.slice/memory.current": "1\n",
+	})
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{
+		MetricsCgroupSampleRate: 10,
+		CgroupIncludePaths:      []string{"/system.slice/**", "/user.slice/**"},
+		CgroupExcludePaths:      []string{"/user.slice/*"},
+	})
+
+	// WHEN it's sampled
+	sampler := NewSampler(ctx)
+	batch, err := sampler.Sample()
+	require.NoError(t, err)
+
+	// THEN a sample is reported for each matching cgroup
+	require.Len(t, batch, 3)
+	assert.Equal(t, "/system.slice", batch[0].(*Sample).CgroupPath)
+	assert.Equal(t, "/user.slice", batch[2].(*Sample).CgroupPath)
+
+	ss := batch[1].(*Sample)
+	assert.Equal(t, "CgroupSample", ss.EventType)
+	assert.Equal(t, "/system.slice/a.service", ss.CgroupPath)
+	assert.Equal(t, "v2", ss.CgroupVersion)
+	assert.Equal(t, uint64(1024), *ss.MemoryUsedBytes)
+	assert.Nil(t, ss.MemoryLimitBytes)
+	assert.Nil(t, ss.MemoryOomEvents)
+	assert.Nil(t, ss.MemoryOomKillEvents)
+	assert.Equal(t, uint64(7), *ss.PidsCount)
+	assert.Equal(t, uint64(100), *ss.PidsLimit)
+
+	// AND the OOM events since the previous sample are reported on the next one
+	writeFiles(t, root, map[string]string{
+		"system.slice/a.service/memory.events": "low 0\nhigh 0\nmax 6\noom 5\noom_kill 1\n",
+	})
+	batch, err = sampler.Sample()
+	require.NoError(t, err)
+	ss = batch[1].(*Sample)
+	assert.Equal(t, uint64(3), *ss.MemoryOomEvents)
+	assert.Equal(t, uint64(0), *ss.MemoryOomKillEvents)
+
+	st, err := readStats(func(string) bool { return true })
+	require.NoError(t, err)
+	io := st["/system.slice/a.service"]
+	assert.Equal(t, uint64(110), *io.ioReadBytes)
+	assert.Equal(t, uint64(220), *io.ioWriteBytes)
+	assert.Equal(t, uint64(2), *io.ioReads)
+	assert.Equal(t, uint64(3), *io.ioWrites)
+	assert.Equal(t, uint64(300), *io.cpuThrottledUsec)
+}
+
+func TestReadStats_V1(t *testing.T) {
+	// GIVEN the cgroup v1 controller hierarchies
+	sys := t.TempDir()
+	t.Setenv("HOST_SYS", sys)
+	root := filepath.Join(sys, "fs", "cgroup")
+	writeFiles(t, root, map[string]string{
+		"cpu,cpuacct/docker/abc/cpuacct.usage":             "2000000\n",
+		"cpu,cpuacct/docker/abc/cpuacct.stat":              "user 150\nsystem 50\n",
+		"cpu,cpuacct/docker/abc/cpu.stat":                  "nr_periods 20\nnr_throttled 4\nthrottled_time 8000000\n",
+		"memory/docker/abc/memory.usage_in_bytes":          "4096\n",
+		"memory/docker/abc/memory.limit_in_bytes":          "8192\n",
+		"memory/docker/abc/memory.oom_control":             "oom_kill_disable 0\nunder_oom 0\noom_kill 3\n",
+		"memory/memory.limit_in_bytes":                     "9223372036854771712\n",
+		"blkio/docker/abc/blkio.throttle.io_service_bytes": "8:0 Read 512\n8:0 Write 1024\n8:0 Total 1536\nTotal 1536\n",
+		"blkio/docker/abc/blkio.throttle.io_serviced":      "8:0 Read 1\n8:0 Write 2\n8:0 Total 3\nTotal 3\n",
+		"pids/docker/abc/pids.current":                     "4\n",
+		"pids/docker/abc/pids.max":                         "max\n",
+	})
+	require.NoError(t, os.Symlink("cpu,cpuacct", filepath.Join(root, "cpu")))
+	require.NoError(t, os.Symlink("cpu,cpuacct", filepath.Join(root, "cpuacct")))
+
+	// WHEN the stats are read
+	st, err := readStats(func(string) bool { return true })
+	require.NoError(t, err)
+
+	// THEN the values of all the controllers are merged by cgroup
+	require.Contains(t, st, "/")
+	assert.Nil(t, st["/"].memoryMax)
+
+	abc := st["/docker/abc"]
+	require.NotNil(t, abc)
+	assert.Equal(t, "v1", abc.version)
+	assert.Equal(t, uint64(2000), *abc.cpuUsageUsec)
+	assert.Equal(t, uint64(1500000), *abc.cpuUserUsec)
+	assert.Equal(t, uint64(500000), *abc.cpuSystemUsec)
+	assert.Equal(t, uint64(20), *abc.cpuPeriods)
+	assert.Equal(t, uint64(4), *abc.cpuThrottledPeriods)
+	assert.Equal(t, uint64(8000), *abc.cpuThrottledUsec)
+	assert.Equal(t, uint64(4096), *abc.memoryCurrent)
+	assert.Equal(t, uint64(8192), *abc.memoryMax)
+	assert.Nil(t, abc.memoryOoms)
+	assert.Equal(t, uint64(3), *abc.memoryOomKills)
+	assert.Equal(t, uint64(512), *abc.ioReadBytes)
+	assert.Equal(t, uint64(1024), *abc.ioWriteBytes)
+	assert.Equal(t, uint64(1), *abc.ioReads)
+	assert.Equal(t, uint64(2), *abc.ioWrites)
+	assert.Equal(t, uint64(4), *abc.pidsCurrent)
+	assert.Nil(t, abc.pidsMax)
+}
+
+func TestReadStats_NotFound(t *testing.T) {
+	t.Setenv("HOST_SYS", t.TempDir())
+
+	_, err := readStats(func(string) bool { return true })
+
+	assert.ErrorIs(t, err, ErrCgroupNotFound)
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		path    string
+		matches bool
+	}{
+		{"no patterns", nil, nil, "/system.slice/sshd.service", true},
+		{"included", []string{"/system.slice/*"}, nil, "/system.slice/sshd.service", true},
+		{"not included", []string{"/system.slice/*"}, nil, "/user.slice/user-1000.slice", false},
+		{"descendants not included", []string{"/system.slice/*"}, nil, "/system.slice/a.service/b", false},
+		{"descendants included", []string{"/kubepods/**"}, nil, "/kubepods/burstable/pod1/ctr", true},
+		{"parent of descendants included", []string{"/kubepods/**"}, nil, "/kubepods", true},
+		{"everything included", []string{"/**"}, nil, "/a/b", true},
+		{"excluded", nil, []string{"/user.slice/**"}, "/user.slice/user-1000.slice", false},
+		{"exclusion precedence", []string{"/**"}, []string{"/init.scope"}, "/init.scope", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, newPathFilter(tt.include, tt.exclude).match(tt.path))
+		})
+	}
+}
+
+func TestNewSample(t *testing.T) {
+	previous := &stats{
+		path:                "/system.slice/a.service",
+		version:             cgroupV2,
+		cpuUsageUsec:        uintPtr(1000000),
+		cpuUserUsec:         uintPtr(600000),
+		cpuSystemUsec:       uintPtr(400000),
+		cpuPeriods:          uintPtr(100),
+		cpuThrottledPeriods: uintPtr(10),
+		cpuThrottledUsec:    uintPtr(50000),
+		memoryOoms:          uintPtr(1),
+		ioReadBytes:         uintPtr(4096),
+		ioReads:             uintPtr(1),
+	}
+	current := &stats{
+		path:                "/system.slice/a.service",
+		version:             cgroupV2,
+		cpuUsageUsec:        uintPtr(2000000),
+		cpuUserUsec:         uintPtr(1200000),
+		cpuSystemUsec:       uintPtr(800000),
+		cpuPeriods:          uintPtr(120),
+		cpuThrottledPeriods: uintPtr(15),
+		cpuThrottledUsec:    uintPtr(250000),
+		memoryCurrent:       uintPtr(256),
+		memoryMax:           uintPtr(1024),
+		memoryOoms:          uintPtr(2),
+		ioReadBytes:         uintPtr(4096 * 11),
+		ioReads:             uintPtr(11),
+		pidsCurrent:         uintPtr(3),
+	}
+
+	// WHEN there are no previous stats
+	ss := newSample(current, nil, 0)
+
+	// THEN only the gauges are reported
+	assert.Equal(t, "/system.slice/a.service", ss.CgroupPath)
+	assert.Equal(t, "v2", ss.CgroupVersion)
+	assert.Equal(t, uint64(256), *ss.MemoryUsedBytes)
+	assert.Equal(t, uint64(1024), *ss.MemoryLimitBytes)
+	assert.Equal(t, 25.0, *ss.MemoryUsedPercent)
+	assert.Nil(t, ss.MemoryOomEvents)
+	assert.Nil(t, ss.MemoryOomKillEvents)
+	assert.Equal(t, uint64(3), *ss.PidsCount)
+	assert.Nil(t, ss.PidsLimit)
+	assert.Nil(t, ss.CPUPercent)
+	assert.Nil(t, ss.IOReadBytesPerSec)
+
+	// WHEN there are previous stats
+	ss = newSample(current, previous, 2)
+
+	// THEN the rates and the events since the previous stats are calculated
+	assert.Equal(t, uint64(1), *ss.MemoryOomEvents)
+	assert.Nil(t, ss.MemoryOomKillEvents)
+	require.NotNil(t, ss.CPUPercent)
+	assert.InDelta(t, 50.0, *ss.CPUPercent, 0.001)
+	assert.InDelta(t, 30.0, *ss.CPUUserPercent, 0.001)
+	assert.InDelta(t, 20.0, *ss.CPUSystemPercent, 0.001)
+	assert.InDelta(t, 10.0, *ss.CPUThrottledTimePercent, 0.001)
+	assert.InDelta(t, 2.5, *ss.CPUThrottledPeriodsPerSec, 0.001)
+	assert.InDelta(t, 25.0, *ss.CPUThrottledPeriodsPercent, 0.001)
+	assert.InDelta(t, 4096*5, *ss.IOReadBytesPerSec, 0.001)
+	assert.InDelta(t, 5.0, *ss.IOReadsPerSec, 0.001)
+	assert.Nil(t, ss.IOWriteBytesPerSec)
+}
+
+func TestDelta(t *testing.T) {
+	assert.Equal(t, uint64(3), *delta(uintPtr(5), uintPtr(2)))
+	// counter reset, the cgroup was created again
+	assert.Equal(t, uint64(1), *delta(uintPtr(1), uintPtr(4)))
+	assert.Nil(t, delta(uintPtr(1), nil))
+}
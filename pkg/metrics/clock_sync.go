@@ -0,0 +1,105 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"errors"
+	"time"
+)
+
+// Sources of the clock synchronization status.
+const (
+	ClockSyncSourceChrony   = "chrony"
+	ClockSyncSourceAdjtimex = "adjtimex"
+	ClockSyncSourcePool     = "pool"
+)
+
+var (
+	ErrClockSyncUnsupported = errors.New("local clock synchronization status is not supported on this platform")
+	ErrClockSyncUnavailable = errors.New("cannot get clock synchronization status")
+)
+
+// ClockSync is the clock synchronization status of the host.
+type ClockSync struct {
+	Source       string
+	Synchronized *bool
+	Stratum      *int
+	Offset       *time.Duration
+	// EstimatedError is the estimated maximum error of the clock
+	EstimatedError *time.Duration
+}
+
+// ClockSyncMonitor is a NtpMonitor that also reports the clock synchronization status.
+type ClockSyncMonitor interface {
+	NtpMonitor
+	ClockSync() (*ClockSync, error)
+}
+
+// LocalNtp reads the clock synchronization status from the local time sync daemon, through the chrony command
+// port, or from the kernel, through adjtimex, so no outbound NTP traffic is needed. When neither is available,
+// or the clock isn't synchronized, the offset is queried to the NTP pool servers, if configured.
+type LocalNtp struct {
+	pool     *Ntp
+	chrony   func() (*ClockSync, error)
+	adjtimex func() (*ClockSync, error)
+}
+
+// NewLocalNtp creates a LocalNtp falling back to the given pool, which can be nil.
+func NewLocalNtp(pool *Ntp) *LocalNtp {
+	return &LocalNtp{
+		pool:     pool,
+		chrony:   chronyTracking,
+		adjtimex: adjtimexClockSync,
+	}
+}
+
+// ClockSync returns the clock synchronization status from the first available source.
+func (l *LocalNtp) ClockSync() (*ClockSync, error) {
+	clockSync, err := l.chrony()
+	if err != nil {
+		syslog.WithError(err).Debug("cannot get chrony tracking")
+		clockSync, err = l.adjtimex()
+		if err != nil {
+			syslog.WithError(err).Debug("cannot get kernel clock synchronization status")
+		}
+	}
+	if clockSync != nil && clockSync.Synchronized != nil && *clockSync.Synchronized {
+		return clockSync, nil
+	}
+
+	if l.pool == nil || len(l.pool.pool) == 0 {
+		if clockSync != nil {
+			return clockSync, nil
+		}
+		return nil, ErrClockSyncUnavailable
+	}
+
+	offset, poolErr := l.pool.Offset()
+	if poolErr != nil {
+		if clockSync != nil {
+			return clockSync, nil
+		}
+		return nil, poolErr
+	}
+	// keep the status reported by the local source, if any, as the pool can't tell it
+	if clockSync == nil {
+		clockSync = &ClockSync{}
+	}
+	clockSync.Source = ClockSyncSourcePool
+	clockSync.Offset = &offset
+	clockSync.Stratum = nil
+	clockSync.EstimatedError = nil
+	return clockSync, nil
+}
+
+func (l *LocalNtp) Offset() (time.Duration, error) {
+	clockSync, err := l.ClockSync()
+	if err != nil {
+		return 0, err
+	}
+	if clockSync.Offset == nil {
+		return 0, ErrGettingNtpOffset
+	}
+	return *clockSync.Offset, nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package metrics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// chronyAddress is the command port chronyd listens on for monitoring requests from localhost.
+	chronyAddress = "127.0.0.1:323"
+	chronyTimeout = time.Second
+
+	chronyProtocolVersion = 6
+	chronyPktTypeRequest  = 1
+	chronyPktTypeReply    = 2
+	chronyReqTracking     = 33
+	chronyRpyTracking     = 5
+	chronyStatusSuccess   = 0
+	chronyLeapUnsynced    = 3
+
+	// kernel clock states and status bits, from linux/timex.h
+	kernelTimeError     = 5
+	kernelStatusUnsync  = 0x0040
+	kernelStatusNanoSec = 0x2000
+)
+
+// chronyRequestHeader is the header of the chrony command requests.
+type chronyRequestHeader struct {
+	Version  uint8
+	PktType  uint8
+	Res1     uint8
+	Res2     uint8
+	Command  uint16
+	Attempt  uint16
+	Sequence uint32
+	Pad1     uint32
+	Pad2     uint32
+}
+
+// chronyReplyHeader is the header of the chrony command replies.
+type chronyReplyHeader struct {
+	Version  uint8
+	PktType  uint8
+	Res1     uint8
+	Res2     uint8
+	Command  uint16
+	Reply    uint16
+	Status   uint16
+	Pad1     uint16
+	Pad2     uint16
+	Pad3     uint16
+	Sequence uint32
+	Pad4     uint32
+	Pad5     uint32
+}
+
+// chronyTrackingReply is the payload of the tracking reply. Floats are encoded in the chrony format, decoded
+// by chronyFloat.
+type chronyTrackingReply struct {
+	RefID              uint32
+	IPAddr             [16]byte
+	IPFamily           uint16
+	IPPad              uint16
+	Stratum            uint16
+	LeapStatus         uint16
+	RefTimeSecHigh     uint32
+	RefTimeSecLow      uint32
+	RefTimeNsec        uint32
+	CurrentCorrection  uint32
+	LastOffset         uint32
+	RMSOffset          uint32
+	FreqPPM            uint32
+	ResidFreqPPM       uint32
+	SkewPPM            uint32
+	RootDelay          uint32
+	RootDispersion     uint32
+	LastUpdateInterval uint32
+}
+
+// chronyTracking queries the tracking status of chronyd, as "chronyc tracking" does.
+func chronyTracking() (*ClockSync, error) {
+	conn, err := net.DialTimeout("udp", chronyAddress, chronyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(chronyTimeout)); err != nil {
+		return nil, err
+	}
+
+	sequence := rand.Uint32()
+	if _, err = conn.Write(chronyTrackingRequest(sequence)); err != nil {
+		return nil, err
+	}
+	response := make([]byte, 1024)
+	n, err := conn.Read(response)
+	if err != nil {
+		return nil, err
+	}
+	return parseChronyTracking(response[:n], sequence)
+}
+
+// chronyTrackingRequest builds a tracking request. It's padded to the length of the reply, as chronyd
+// ignores shorter requests to prevent traffic amplification.
+func chronyTrackingRequest(sequence uint32) []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.BigEndian, chronyRequestHeader{
+		Version:  chronyProtocolVersion,
+		PktType:  chronyPktTypeRequest,
+		Command:  chronyReqTracking,
+		Sequence: sequence,
+	})
+	replyLen := binary.Size(chronyReplyHeader{}) + binary.Size(chronyTrackingReply{})
+	buf.Write(make([]byte, replyLen-buf.Len()))
+	return buf.Bytes()
+}
+
+func parseChronyTracking(response []byte, sequence uint32) (*ClockSync, error) {
+	reader := bytes.NewReader(response)
+	var header chronyReplyHeader
+	if err := binary.Read(reader, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("cannot read chrony reply header: %w", err)
+	}
+	if header.PktType != chronyPktTypeReply || header.Sequence != sequence {
+		return nil, fmt.Errorf("unexpected chrony reply packet type %d", header.PktType)
+	}
+	if header.Status != chronyStatusSuccess {
+		return nil, fmt.Errorf("chrony reply status %d", header.Status)
+	}
+	if header.Reply != chronyRpyTracking {
+		return nil, fmt.Errorf("unexpected chrony reply %d", header.Reply)
+	}
+
+	var tracking chronyTrackingReply
+	if err := binary.Read(reader, binary.BigEndian, &tracking); err != nil {
+		return nil, fmt.Errorf("cannot read chrony tracking: %w", err)
+	}
+
+	synchronized := tracking.LeapStatus != chronyLeapUnsynced && tracking.RefID != 0
+	stratum := int(tracking.Stratum)
+	offset := secondsToDuration(chronyFloat(tracking.CurrentCorrection))
+	// maximum error of the clock, as the root distance
+	estimatedError := secondsToDuration(chronyFloat(tracking.RootDispersion) + chronyFloat(tracking.RootDelay)/2)
+	return &ClockSync{
+		Source:         ClockSyncSourceChrony,
+		Synchronized:   &synchronized,
+		Stratum:        &stratum,
+		Offset:         &offset,
+		EstimatedError: &estimatedError,
+	}, nil
+}
+
+// chronyFloat decodes the chrony network float format: a 7-bit signed exponent followed by a 25-bit signed
+// coefficient.
+func chronyFloat(f uint32) float64 {
+	const coefBits = 25
+	exp := int32(f >> coefBits)
+	if exp >= 1<<6 {
+		exp -= 1 << 7
+	}
+	coef := int32(f % (1 << coefBits))
+	if coef >= 1<<(coefBits-1) {
+		coef -= 1 << coefBits
+	}
+	return float64(coef) * math.Pow(2, float64(exp-coefBits))
+}
+
+// adjtimexClockSync reads the clock synchronization status of the kernel, kept by any time sync daemon
+// disciplining the clock (chronyd, ntpd, systemd-timesyncd). The stratum is not known by the kernel.
+func adjtimexClockSync() (*ClockSync, error) {
+	timex := &unix.Timex{}
+	state, err := unix.Adjtimex(timex)
+	if err != nil {
+		return nil, err
+	}
+	return kernelClockSync(state, int64(timex.Status), int64(timex.Offset), int64(timex.Esterror)), nil
+}
+
+func kernelClockSync(state int, status, offset, estimatedErrorUsec int64) *ClockSync {
+	synchronized := state != kernelTimeError && status&kernelStatusUnsync == 0
+	offsetUnit := time.Microsecond
+	if status&kernelStatusNanoSec != 0 {
+		offsetUnit = time.Nanosecond
+	}
+	// the kernel offset is the adjustment still to apply to the clock, so it's the same as the clock offset
+	offsetDuration := time.Duration(offset) * offsetUnit
+	estimatedError := time.Duration(estimatedErrorUsec) * time.Microsecond
+	return &ClockSync{
+		Source:         ClockSyncSourceAdjtimex,
+		Synchronized:   &synchronized,
+		Offset:         &offsetDuration,
+		EstimatedError: &estimatedError,
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package metrics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeChronyFloat encodes a value in the chrony float format, where value = coef * 2^(exp - 25).
+func encodeChronyFloat(x float64) uint32 {
+	frac, exp := math.Frexp(x)
+	coef := int32(frac * (1 << 24))
+	return uint32(exp+1)&(1<<7-1)<<25 | uint32(coef)&(1<<25-1)
+}
+
+func TestChronyFloat(t *testing.T) {
+	for _, value := range []float64{0, 1, -1, 0.000123, -0.5, 42.25} {
+		assert.InDelta(t, value, chronyFloat(encodeChronyFloat(value)), 1e-9, "value %v", value)
+	}
+}
+
+func chronyTrackingResponse(t *testing.T, header chronyReplyHeader, tracking chronyTrackingReply) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	require.NoError(t, binary.Write(buf, binary.BigEndian, header))
+	require.NoError(t, binary.Write(buf, binary.BigEndian, tracking))
+	return buf.Bytes()
+}
+
+func TestChronyTrackingRequest(t *testing.T) {
+	request := chronyTrackingRequest(1234)
+
+	// padded to the reply length
+	assert.Len(t, request, 104)
+	var header chronyRequestHeader
+	require.NoError(t, binary.Read(bytes.NewReader(request), binary.BigEndian, &header))
+	assert.Equal(t, chronyRequestHeader{
+		Version:  chronyProtocolVersion,
+		PktType:  chronyPktTypeRequest,
+		Command:  chronyReqTracking,
+		Sequence: 1234,
+	}, header)
+}
+
+func TestParseChronyTracking(t *testing.T) {
+	header := chronyReplyHeader{
+		Version: chronyProtocolVersion, PktType: chronyPktTypeReply, Command: chronyReqTracking,
+		Reply: chronyRpyTracking, Status: chronyStatusSuccess, Sequence: 1234,
+	}
+	tracking := chronyTrackingReply{
+		RefID:             0xc0a80001,
+		Stratum:           3,
+		LeapStatus:        0,
+		CurrentCorrection: encodeChronyFloat(-0.25),
+		RootDelay:         encodeChronyFloat(0.5),
+		RootDispersion:    encodeChronyFloat(0.125),
+	}
+
+	clockSync, err := parseChronyTracking(chronyTrackingResponse(t, header, tracking), 1234)
+
+	require.NoError(t, err)
+	assert.Equal(t, ClockSyncSourceChrony, clockSync.Source)
+	assert.True(t, *clockSync.Synchronized)
+	assert.Equal(t, 3, *clockSync.Stratum)
+	assert.Equal(t, -250*time.Millisecond, *clockSync.Offset)
+	assert.Equal(t, 375*time.Millisecond, *clockSync.EstimatedError)
+}
+
+func TestParseChronyTracking_Unsynchronized(t *testing.T) {
+	header := chronyReplyHeader{PktType: chronyPktTypeReply, Reply: chronyRpyTracking, Sequence: 1}
+	tracking := chronyTrackingReply{Stratum: 0, LeapStatus: chronyLeapUnsynced}
+
+	clockSync, err := parseChronyTracking(chronyTrackingResponse(t, header, tracking), 1)
+
+	require.NoError(t, err)
+	assert.False(t, *clockSync.Synchronized)
+}
+
+func TestParseChronyTracking_Errors(t *testing.T) {
+	tracking := chronyTrackingReply{}
+	testCases := []struct {
+		name     string
+		response []byte
+	}{
+		{"truncated", []byte{6, 2, 0}},
+		{"wrong sequence", chronyTrackingResponse(t, chronyReplyHeader{PktType: chronyPktTypeReply, Reply: chronyRpyTracking, Sequence: 2}, tracking)},
+		{"failed status", chronyTrackingResponse(t, chronyReplyHeader{PktType: chronyPktTypeReply, Reply: chronyRpyTracking, Status: 2, Sequence: 1}, tracking)},
+		{"missing payload", chronyTrackingResponse(t, chronyReplyHeader{PktType: chronyPktTypeReply, Reply: chronyRpyTracking, Sequence: 1}, tracking)[:40]},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := parseChronyTracking(testCase.response, 1)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKernelClockSync(t *testing.T) {
+	testCases := []struct {
+		name           string
+		state          int
+		status         int64
+		offset         int64
+		synchronized   bool
+		expectedOffset time.Duration
+	}{
+		{"synchronized", 0, 0x0001, 1500, true, 1500 * time.Microsecond},
+		{"synchronized in nanoseconds", 0, kernelStatusNanoSec, 1500, true, 1500 * time.Nanosecond},
+		{"unsync status", 0, kernelStatusUnsync, 0, false, 0},
+		{"clock error state", kernelTimeError, 0, 0, false, 0},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			clockSync := kernelClockSync(testCase.state, testCase.status, testCase.offset, 2000)
+			assert.Equal(t, ClockSyncSourceAdjtimex, clockSync.Source)
+			assert.Equal(t, testCase.synchronized, *clockSync.Synchronized)
+			assert.Equal(t, testCase.expectedOffset, *clockSync.Offset)
+			assert.Equal(t, 2*time.Millisecond, *clockSync.EstimatedError)
+			assert.Nil(t, clockSync.Stratum)
+		})
+	}
+}
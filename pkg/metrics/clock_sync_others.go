@@ -0,0 +1,14 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build !linux
+// +build !linux
+
+package metrics
+
+func chronyTracking() (*ClockSync, error) {
+	return nil, ErrClockSyncUnsupported
+}
+
+func adjtimexClockSync() (*ClockSync, error) {
+	return nil, ErrClockSyncUnsupported
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beevik/ntp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clockSyncMock(clockSync *ClockSync, err error) func() (*ClockSync, error) {
+	return func() (*ClockSync, error) {
+		return clockSync, err
+	}
+}
+
+func synchronizedClock(source string, offset time.Duration) *ClockSync {
+	synchronized := true
+	stratum := 3
+	return &ClockSync{Source: source, Synchronized: &synchronized, Stratum: &stratum, Offset: &offset}
+}
+
+func poolMock(offset time.Duration) *Ntp {
+	pool := NewNtp([]string{"one"}, 5, 15)
+	pool.ntpQuery = ntpQueryMock(ntpResp{resp: &ntp.Response{ClockOffset: offset}})
+	pool.now = nowMock("2022-09-28 16:02:45")
+	return pool
+}
+
+func TestLocalNtp_ClockSync(t *testing.T) {
+	unavailable := errors.New("unavailable")
+	unsynchronized := false
+	unsynchronizedClock := &ClockSync{Source: ClockSyncSourceAdjtimex, Synchronized: &unsynchronized}
+	poolOffset := 30 * time.Millisecond
+
+	testCases := []struct {
+		name     string
+		chrony   func() (*ClockSync, error)
+		adjtimex func() (*ClockSync, error)
+		pool     *Ntp
+		expected *ClockSync
+	}{
+		{
+			name:     "chrony",
+			chrony:   clockSyncMock(synchronizedClock(ClockSyncSourceChrony, time.Millisecond), nil),
+			adjtimex: clockSyncMock(synchronizedClock(ClockSyncSourceAdjtimex, 2*time.Millisecond), nil),
+			pool:     poolMock(poolOffset),
+			expected: synchronizedClock(ClockSyncSourceChrony, time.Millisecond),
+		},
+		{
+			name:     "kernel when chrony is not available",
+			chrony:   clockSyncMock(nil, unavailable),
+			adjtimex: clockSyncMock(synchronizedClock(ClockSyncSourceAdjtimex, 2*time.Millisecond), nil),
+			pool:     poolMock(poolOffset),
+			expected: synchronizedClock(ClockSyncSourceAdjtimex, 2*time.Millisecond),
+		},
+		{
+			name:     "pool offset when the clock is not synchronized",
+			chrony:   clockSyncMock(nil, unavailable),
+			adjtimex: clockSyncMock(unsynchronizedClock, nil),
+			pool:     poolMock(poolOffset),
+			expected: &ClockSync{Source: ClockSyncSourcePool, Synchronized: &unsynchronized, Offset: &poolOffset},
+		},
+		{
+			name:     "pool offset when no local source is available",
+			chrony:   clockSyncMock(nil, unavailable),
+			adjtimex: clockSyncMock(nil, unavailable),
+			pool:     poolMock(poolOffset),
+			expected: &ClockSync{Source: ClockSyncSourcePool, Offset: &poolOffset},
+		},
+		{
+			name:     "local status without pool",
+			chrony:   clockSyncMock(nil, unavailable),
+			adjtimex: clockSyncMock(unsynchronizedClock, nil),
+			expected: unsynchronizedClock,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			localNtp := NewLocalNtp(testCase.pool)
+			localNtp.chrony = testCase.chrony
+			localNtp.adjtimex = testCase.adjtimex
+
+			clockSync, err := localNtp.ClockSync()
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, clockSync)
+		})
+	}
+}
+
+func TestLocalNtp_Unavailable(t *testing.T) {
+	localNtp := NewLocalNtp(NewNtp(nil, 5, 15))
+	localNtp.chrony = clockSyncMock(nil, errors.New("unavailable"))
+	localNtp.adjtimex = clockSyncMock(nil, errors.New("unavailable"))
+
+	_, err := localNtp.ClockSync()
+	assert.ErrorIs(t, err, ErrClockSyncUnavailable)
+
+	_, err = localNtp.Offset()
+	assert.ErrorIs(t, err, ErrClockSyncUnavailable)
+}
+
+func TestHostMonitor_ClockSync(t *testing.T) {
+	localNtp := NewLocalNtp(nil)
+	localNtp.chrony = clockSyncMock(synchronizedClock(ClockSyncSourceChrony, 500*time.Millisecond), nil)
+
+	hostSample, err := NewHostMonitor(localNtp).Sample()
+
+	require.NoError(t, err)
+	assert.Equal(t, ClockSyncSourceChrony, hostSample.NtpSource)
+	assert.Equal(t, "synchronized", hostSample.NtpSyncStatus)
+	require.NotNil(t, hostSample.NtpStratum)
+	assert.Equal(t, 3, *hostSample.NtpStratum)
+	require.NotNil(t, hostSample.NtpOffset)
+	assert.Equal(t, 0.5, *hostSample.NtpOffset)
+	assert.Nil(t, hostSample.NtpEstimatedError)
+}
@@ -5,11 +5,13 @@ package metrics
 import (
 	"fmt"
 	"runtime/debug"
+	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 
 	"github.com/newrelic/infrastructure-agent/internal/agent"
 	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
 )
 
 type CPUSample struct {
@@ -19,16 +21,31 @@ type CPUSample struct {
 	CPUIOWaitPercent float64 `json:"cpuIOWaitPercent"`
 	CPUIdlePercent   float64 `json:"cpuIdlePercent"`
 	CPUStealPercent  float64 `json:"cpuStealPercent"`
+
+	// Host-wide kernel activity rates, only reported when the per-core CPU metrics are enabled
+	ContextSwitchesPerSec *float64 `json:"contextSwitchesPerSecond,omitempty"`
+	InterruptsPerSec      *float64 `json:"interruptsPerSecond,omitempty"`
+	SoftInterruptsPerSec  *float64 `json:"softInterruptsPerSecond,omitempty"`
+}
+
+// kernelActivity holds the host-wide kernel activity counters.
+type kernelActivity struct {
+	contextSwitches uint64
+	interrupts      uint64
+	softInterrupts  uint64
 }
 
 type CPUMonitor struct {
-	context  agent.AgentContext
-	last     []cpu.TimesStat
-	cpuTimes func(bool) ([]cpu.TimesStat, error)
+	context        agent.AgentContext
+	last           []cpu.TimesStat
+	cpuTimes       func(bool) ([]cpu.TimesStat, error)
+	lastActivity   *kernelActivity
+	lastActivityAt time.Time
+	kernelActivity func() (*kernelActivity, error)
 }
 
 func NewCPUMonitor(context agent.AgentContext) *CPUMonitor {
-	return &CPUMonitor{context: context, cpuTimes: cpu.Times}
+	return &CPUMonitor{context: context, cpuTimes: cpu.Times, kernelActivity: readKernelActivity}
 }
 
 func (self *CPUMonitor) Sample() (sample *CPUSample, err error) {
@@ -54,6 +71,15 @@ func (self *CPUMonitor) Sample() (sample *CPUSample, err error) {
 	delta := cpuDelta(&currentTimes[0], &self.last[0])
 	self.last = currentTimes
 
+	sample = newCPUSample(delta)
+	if self.context != nil && self.context.Config().EnableCPUCoreMetrics {
+		self.sampleKernelActivity(sample)
+	}
+	return
+}
+
+// newCPUSample calculates the percentage of each CPU state from the CPU times spent since the previous sample.
+func newCPUSample(delta *cpu.TimesStat) *CPUSample {
 	userDelta := delta.User + delta.Nice
 	systemDelta := delta.System + delta.Irq + delta.Softirq
 	stolenDelta := delta.Steal
@@ -70,7 +96,7 @@ func (self *CPUMonitor) Sample() (sample *CPUSample, err error) {
 	}
 	idlePercent := 100 - userPercent - systemPercent - ioWaitPercent - stolenPercent
 
-	sample = &CPUSample{
+	return &CPUSample{
 		CPUPercent:       userPercent + systemPercent + ioWaitPercent + stolenPercent,
 		CPUUserPercent:   userPercent,
 		CPUSystemPercent: systemPercent,
@@ -78,7 +104,30 @@ func (self *CPUMonitor) Sample() (sample *CPUSample, err error) {
 		CPUIdlePercent:   idlePercent,
 		CPUStealPercent:  stolenPercent,
 	}
-	return
+}
+
+// sampleKernelActivity adds to the sample the rates of context switches and interrupts since the previous sample.
+func (self *CPUMonitor) sampleKernelActivity(sample *CPUSample) {
+	current, err := self.kernelActivity()
+	if err != nil {
+		syslog.WithError(err).Debug("Unable to retrieve kernel activity.")
+		return
+	}
+	if current == nil {
+		return
+	}
+	now := time.Now()
+	if self.lastActivity != nil {
+		elapsedSeconds := now.Sub(self.lastActivityAt).Seconds()
+		contextSwitches := acquire.CalculateSafeDelta(current.contextSwitches, self.lastActivity.contextSwitches, elapsedSeconds)
+		interrupts := acquire.CalculateSafeDelta(current.interrupts, self.lastActivity.interrupts, elapsedSeconds)
+		softInterrupts := acquire.CalculateSafeDelta(current.softInterrupts, self.lastActivity.softInterrupts, elapsedSeconds)
+		sample.ContextSwitchesPerSec = &contextSwitches
+		sample.InterruptsPerSec = &interrupts
+		sample.SoftInterruptsPerSec = &softInterrupts
+	}
+	self.lastActivity = current
+	self.lastActivityAt = now
 }
 
 func cpuDelta(current, previous *cpu.TimesStat) *cpu.TimesStat {
@@ -0,0 +1,133 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package metrics
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
+)
+
+// CPUCoreSample reports the usage of a single CPU core.
+type CPUCoreSample struct {
+	sample.BaseEvent
+	CPUSample
+
+	// CPUCore is the name of the core, e.g. "cpu0"
+	CPUCore string `json:"cpuCore"`
+	// Percentage of time servicing hardware interrupts, also accounted in cpuSystemPercent
+	CPUIRQPercent float64 `json:"cpuIrqPercent"`
+	// Percentage of time servicing software interrupts, also accounted in cpuSystemPercent
+	CPUSoftIRQPercent float64 `json:"cpuSoftIrqPercent"`
+	// Hardware interrupts handled by the core per second
+	HardInterruptsPerSec *float64 `json:"hardInterruptsPerSecond,omitempty"`
+	// Software interrupts handled by the core per second
+	SoftInterruptsPerSec *float64 `json:"softInterruptsPerSecond,omitempty"`
+}
+
+// coreInterrupts holds the hardware and software interrupt counters by core name.
+type coreInterrupts struct {
+	hard map[string]uint64
+	soft map[string]uint64
+}
+
+// CPUCoreSampler reports a CPUCoreSample per CPU core, at the system sample rate. As it can report many
+// samples on hosts with many cores, it's only enabled through the enable_cpu_core_metrics option.
+type CPUCoreSampler struct {
+	context        agent.AgentContext
+	cpuTimes       func(bool) ([]cpu.TimesStat, error)
+	interrupts     func() (*coreInterrupts, error)
+	last           map[string]cpu.TimesStat
+	lastInterrupts *coreInterrupts
+	lastRun        time.Time
+}
+
+func NewCPUCoreSampler(context agent.AgentContext) *CPUCoreSampler {
+	return &CPUCoreSampler{
+		context:    context,
+		cpuTimes:   cpu.Times,
+		interrupts: readCoreInterrupts,
+	}
+}
+
+func (s *CPUCoreSampler) Name() string { return "CPUCoreSampler" }
+
+func (s *CPUCoreSampler) OnStartup() {}
+
+func (s *CPUCoreSampler) Interval() time.Duration {
+	return time.Second * time.Duration(s.context.Config().MetricsSystemSampleRate)
+}
+
+func (s *CPUCoreSampler) Disabled() bool {
+	return !s.context.Config().EnableCPUCoreMetrics || s.Interval() <= config.FREQ_DISABLE_SAMPLING
+}
+
+func (s *CPUCoreSampler) Sample() (results sample.EventBatch, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("Panic in CPUCoreSampler.Sample: %v\nStack: %s", panicErr, debug.Stack())
+		}
+	}()
+
+	times, err := s.cpuTimes(true)
+	if err != nil {
+		return nil, err
+	}
+	interrupts, err := s.interrupts()
+	if err != nil {
+		syslog.WithError(err).Debug("Unable to retrieve CPU core interrupts.")
+	}
+
+	now := time.Now()
+	elapsedSeconds := now.Sub(s.lastRun).Seconds()
+	current := make(map[string]cpu.TimesStat, len(times))
+	for i := range times {
+		core := times[i].CPU
+		current[core] = times[i]
+		last, ok := s.last[core]
+		if !ok {
+			continue
+		}
+
+		delta := cpuDelta(&times[i], &last)
+		coreSample := &CPUCoreSample{
+			CPUSample: *newCPUSample(delta),
+			CPUCore:   core,
+		}
+		if total := delta.Total(); total != 0 {
+			coreSample.CPUIRQPercent = delta.Irq / total * 100.0
+			coreSample.CPUSoftIRQPercent = delta.Softirq / total * 100.0
+		}
+		if interrupts != nil && s.lastInterrupts != nil {
+			coreSample.HardInterruptsPerSec = interruptsRate(interrupts.hard, s.lastInterrupts.hard, core, elapsedSeconds)
+			coreSample.SoftInterruptsPerSec = interruptsRate(interrupts.soft, s.lastInterrupts.soft, core, elapsedSeconds)
+		}
+		coreSample.Type("CPUCoreSample")
+		results = append(results, coreSample)
+	}
+
+	s.last = current
+	s.lastInterrupts = interrupts
+	s.lastRun = now
+	return results, nil
+}
+
+func interruptsRate(current, last map[string]uint64, core string, elapsedSeconds float64) *float64 {
+	currentValue, ok := current[core]
+	if !ok {
+		return nil
+	}
+	lastValue, ok := last[core]
+	if !ok {
+		return nil
+	}
+	rate := acquire.CalculateSafeDelta(currentValue, lastValue, elapsedSeconds)
+	return &rate
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+)
+
+// readKernelActivity reads the context switches and interrupts counters from /proc/stat.
+func readKernelActivity() (*kernelActivity, error) {
+	lines, err := acquire.ReadLines(helpers.HostProc("stat"))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	activity := &kernelActivity{}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		var counter *uint64
+		switch fields[0] {
+		case "ctxt":
+			counter = &activity.contextSwitches
+		case "intr":
+			counter = &activity.interrupts
+		case "softirq":
+			counter = &activity.softInterrupts
+		default:
+			continue
+		}
+		// intr and softirq lines start with the total, followed by the count of each interrupt
+		if *counter, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", fields[0], fields[1], err)
+		}
+	}
+	return activity, nil
+}
+
+// readCoreInterrupts reads the hardware interrupts handled by each core from /proc/interrupts and the
+// software ones from /proc/softirqs.
+func readCoreInterrupts() (*coreInterrupts, error) {
+	hard, err := readInterruptsTable(helpers.HostProc("interrupts"))
+	if err != nil {
+		return nil, err
+	}
+	soft, err := readInterruptsTable(helpers.HostProc("softirqs"))
+	if err != nil {
+		return nil, err
+	}
+	return &coreInterrupts{hard: hard, soft: soft}, nil
+}
+
+// readInterruptsTable adds up, by core, the interrupt counts of a file with a header line holding the names
+// of the online cores and a line for each interrupt, e.g.:
+//
+//	            CPU0       CPU1
+//	 24:          1          0  IO-APIC   5-edge      ACPI:Ged
+//	NMI:          0          0   Non-maskable interrupts
+func readInterruptsTable(file string) (map[string]uint64, error) {
+	lines, err := acquire.ReadLines(file)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("empty file %s", file)
+	}
+
+	cores := strings.Fields(lines[0])
+	for i := range cores {
+		// named after the gopsutil CPU times, e.g. "cpu0"
+		cores[i] = strings.ToLower(cores[i])
+	}
+	result := make(map[string]uint64, len(cores))
+	for _, core := range cores {
+		result[core] = 0
+	}
+
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		// ERR and MIS are host-wide counters, not per core
+		if len(fields) < 2 || fields[0] == "ERR:" || fields[0] == "MIS:" {
+			continue
+		}
+		for i := 0; i < len(cores) && i+1 < len(fields); i++ {
+			value, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				break
+			}
+			result[cores[i]] += value
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package metrics
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCoreInterrupts(t *testing.T) {
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(proc, "interrupts"), []byte(
+		`           CPU0       CPU2
+ 24:          1          3  IO-APIC   5-edge      ACPI:Ged
+ 31:        937         10  PCI-MSIX-0000:00:01.0   3-edge      virtio0-stats
+NMI:          2          0   Non-maskable interrupts
+ERR:          7
+MIS:          0
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(proc, "softirqs"), []byte(
+		`                    CPU0       CPU2
+          HI:          0          1
+       TIMER:     139522      20000
+      NET_RX:      14785         20
+`), 0644))
+
+	interrupts, err := readCoreInterrupts()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]uint64{"cpu0": 940, "cpu2": 13}, interrupts.hard)
+	assert.Equal(t, map[string]uint64{"cpu0": 154307, "cpu2": 20021}, interrupts.soft)
+}
+
+func TestReadKernelActivity(t *testing.T) {
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(proc, "stat"), []byte(
+		`cpu  1000 0 500 8000 10 0 20 0 0 0
+cpu0 1000 0 500 8000 10 0 20 0 0 0
+intr 2035608 0 0 0 1
+ctxt 4263019
+btime 1700000000
+processes 5000
+softirq 379992 0 139522 3
+`), 0644))
+
+	activity, err := readKernelActivity()
+	require.NoError(t, err)
+
+	assert.Equal(t, &kernelActivity{contextSwitches: 4263019, interrupts: 2035608, softInterrupts: 379992}, activity)
+}
@@ -0,0 +1,16 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build !linux
+// +build !linux
+
+package metrics
+
+// readKernelActivity returns nil, as the context switches and interrupts counters are only read on Linux.
+func readKernelActivity() (*kernelActivity, error) {
+	return nil, nil
+}
+
+// readCoreInterrupts returns nil, as the per-core interrupts are only read on Linux.
+func readCoreInterrupts() (*coreInterrupts, error) {
+	return nil, nil
+}
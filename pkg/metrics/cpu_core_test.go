@@ -0,0 +1,113 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+)
+
+func TestCPUCoreSampler_Disabled(t *testing.T) {
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{MetricsSystemSampleRate: 5})
+
+	assert.True(t, NewCPUCoreSampler(ctx).Disabled())
+}
+
+func TestCPUCoreSampler_Sample(t *testing.T) {
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{MetricsSystemSampleRate: 5, EnableCPUCoreMetrics: true})
+	s := NewCPUCoreSampler(ctx)
+	require.False(t, s.Disabled())
+
+	times := []cpu.TimesStat{
+		{CPU: "cpu0", User: 10, System: 10, Idle: 80},
+		{CPU: "cpu1", User: 50, System: 10, Idle: 40},
+	}
+	interrupts := &coreInterrupts{
+		hard: map[string]uint64{"cpu0": 100, "cpu1": 100},
+		soft: map[string]uint64{"cpu0": 1000, "cpu1": 1000},
+	}
+	s.cpuTimes = func(perCPU bool) ([]cpu.TimesStat, error) {
+		assert.True(t, perCPU)
+		return times, nil
+	}
+	s.interrupts = func() (*coreInterrupts, error) {
+		return interrupts, nil
+	}
+
+	// GIVEN a first sample, which doesn't report anything as there is nothing to compare with
+	samples, err := s.Sample()
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+
+	// WHEN the cores have been used
+	times = []cpu.TimesStat{
+		{CPU: "cpu0", User: 20, System: 20, Irq: 5, Softirq: 5, Idle: 150},
+		{CPU: "cpu1", User: 140, System: 10, Idle: 50},
+	}
+	interrupts = &coreInterrupts{
+		hard: map[string]uint64{"cpu0": 300, "cpu1": 100},
+		soft: map[string]uint64{"cpu0": 1000, "cpu1": 3000},
+	}
+	s.lastRun = time.Now().Add(-2 * time.Second)
+	samples, err = s.Sample()
+	require.NoError(t, err)
+
+	// THEN a sample is reported for each core
+	require.Len(t, samples, 2)
+	core0 := samples[0].(*CPUCoreSample)
+	assert.Equal(t, "CPUCoreSample", core0.EventType)
+	assert.Equal(t, "cpu0", core0.CPUCore)
+	assert.InDelta(t, 10, core0.CPUUserPercent, 0.001)
+	assert.InDelta(t, 20, core0.CPUSystemPercent, 0.001)
+	assert.InDelta(t, 5, core0.CPUIRQPercent, 0.001)
+	assert.InDelta(t, 5, core0.CPUSoftIRQPercent, 0.001)
+	assert.InDelta(t, 70, core0.CPUIdlePercent, 0.001)
+	assert.InDelta(t, 100, *core0.HardInterruptsPerSec, 1)
+	assert.InDelta(t, 0, *core0.SoftInterruptsPerSec, 1)
+
+	core1 := samples[1].(*CPUCoreSample)
+	assert.Equal(t, "cpu1", core1.CPUCore)
+	assert.InDelta(t, 90, core1.CPUPercent, 0.001)
+	assert.InDelta(t, 1000, *core1.SoftInterruptsPerSec, 10)
+}
+
+func TestCPUMonitor_KernelActivity(t *testing.T) {
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{EnableCPUCoreMetrics: true})
+	m := NewCPUMonitor(ctx)
+	m.cpuTimes = func(_ bool) ([]cpu.TimesStat, error) {
+		return []cpu.TimesStat{{CPU: "cpu-total", User: 1, Idle: 1}}, nil
+	}
+	activity := &kernelActivity{contextSwitches: 1000, interrupts: 500, softInterrupts: 100}
+	m.kernelActivity = func() (*kernelActivity, error) {
+		return activity, nil
+	}
+
+	// GIVEN previous samples
+	_, err := m.Sample()
+	require.NoError(t, err)
+	sample, err := m.Sample()
+	require.NoError(t, err)
+	assert.Nil(t, sample.ContextSwitchesPerSec)
+
+	// WHEN the counters increase
+	activity = &kernelActivity{contextSwitches: 3000, interrupts: 1500, softInterrupts: 300}
+	m.lastActivityAt = time.Now().Add(-2 * time.Second)
+	sample, err = m.Sample()
+	require.NoError(t, err)
+
+	// THEN their rates are reported
+	require.NotNil(t, sample.ContextSwitchesPerSec)
+	assert.InDelta(t, 1000, *sample.ContextSwitchesPerSec, 10)
+	assert.InDelta(t, 500, *sample.InterruptsPerSec, 5)
+	assert.InDelta(t, 100, *sample.SoftInterruptsPerSec, 1)
+}
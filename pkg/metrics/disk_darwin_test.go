@@ -238,7 +238,7 @@ func TestGetStorageData(t *testing.T) {
 			name: "sample with not UsedBytes",
 			samples: []*storage.Sample{
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s1",
 						FileSystemType: "hfs",
 						TotalBytes:     fp(10000),
@@ -252,7 +252,7 @@ func TestGetStorageData(t *testing.T) {
 			name: "sample with not FreeBytes",
 			samples: []*storage.Sample{
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s1",
 						FileSystemType: "hfs",
 						TotalBytes:     fp(10000),
@@ -266,7 +266,7 @@ func TestGetStorageData(t *testing.T) {
 			name: "sample with not TotalBytes",
 			samples: []*storage.Sample{
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s1",
 						FileSystemType: "hfs",
 						FreeBytes:      fp(2500),
@@ -280,7 +280,7 @@ func TestGetStorageData(t *testing.T) {
 			name: "one non apfs partition",
 			samples: []*storage.Sample{
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s1",
 						FileSystemType: "hfs",
 						TotalBytes:     fp(10000),
@@ -301,7 +301,7 @@ func TestGetStorageData(t *testing.T) {
 			name: "multiple non apfs partition",
 			samples: []*storage.Sample{
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s1",
 						FileSystemType: "hfs",
 						TotalBytes:     fp(10000),
@@ -310,7 +310,7 @@ func TestGetStorageData(t *testing.T) {
 					},
 				},
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s3",
 						FileSystemType: "hfs",
 						TotalBytes:     fp(20000),
@@ -331,7 +331,7 @@ func TestGetStorageData(t *testing.T) {
 			name: "multiple with apfs partition",
 			samples: []*storage.Sample{
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s1s1",
 						FileSystemType: "apfs",
 						TotalBytes:     fp(10000),
@@ -340,7 +340,7 @@ func TestGetStorageData(t *testing.T) {
 					},
 				},
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s2",
 						FileSystemType: "apfs",
 						TotalBytes:     fp(10000),
@@ -349,7 +349,7 @@ func TestGetStorageData(t *testing.T) {
 					},
 				},
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s3",
 						FileSystemType: "apfs",
 						TotalBytes:     fp(10000),
@@ -370,7 +370,7 @@ func TestGetStorageData(t *testing.T) {
 			name: "multiple partitions types",
 			samples: []*storage.Sample{
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s1s1",
 						FileSystemType: "apfs",
 						TotalBytes:     fp(10000),
@@ -379,7 +379,7 @@ func TestGetStorageData(t *testing.T) {
 					},
 				},
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s2",
 						FileSystemType: "apfs",
 						TotalBytes:     fp(10000),
@@ -388,7 +388,7 @@ func TestGetStorageData(t *testing.T) {
 					},
 				},
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk1s3",
 						FileSystemType: "apfs",
 						TotalBytes:     fp(10000),
@@ -397,7 +397,7 @@ func TestGetStorageData(t *testing.T) {
 					},
 				},
 				{
-					storage.BaseSample{
+					BaseSample: storage.BaseSample{
 						Device:         "/dev/disk2s1",
 						FileSystemType: "hps",
 						TotalBytes:     fp(20000),
@@ -13,6 +13,14 @@ import (
 type HostSample struct {
 	Uptime    uint64   `json:"uptime"`
 	NtpOffset *float64 `json:"ntpOffset,omitempty"`
+	// Source of the clock synchronization values: chrony, adjtimex or pool
+	NtpSource string `json:"ntpSource,omitempty"`
+	// Clock synchronization status: synchronized or unsynchronized
+	NtpSyncStatus string `json:"ntpSyncStatus,omitempty"`
+	// Stratum of the local clock, as reported by chrony
+	NtpStratum *int `json:"ntpStratum,omitempty"`
+	// Estimated maximum error of the clock, in seconds
+	NtpEstimatedError *float64 `json:"ntpEstimatedError,omitempty"`
 }
 
 type HostMonitor struct {
@@ -35,7 +43,14 @@ func (m *HostMonitor) Sample() (*HostSample, error) {
 	}
 	hostSample.Uptime = uptime
 
-	if m.ntpMonitor != nil {
+	if clockSyncMonitor, ok := m.ntpMonitor.(ClockSyncMonitor); ok {
+		clockSync, err := clockSyncMonitor.ClockSync()
+		if err != nil {
+			syslog.WithError(err).Error("cannot get clock synchronization status")
+		} else {
+			populateClockSync(hostSample, clockSync)
+		}
+	} else if m.ntpMonitor != nil {
 		ntpOffset, err := m.ntpMonitor.Offset()
 		if err != nil {
 			syslog.WithError(err).Error("cannot get ntp offset")
@@ -47,3 +62,22 @@ func (m *HostMonitor) Sample() (*HostSample, error) {
 
 	return hostSample, nil
 }
+
+func populateClockSync(hostSample *HostSample, clockSync *ClockSync) {
+	hostSample.NtpSource = clockSync.Source
+	if clockSync.Synchronized != nil {
+		hostSample.NtpSyncStatus = "unsynchronized"
+		if *clockSync.Synchronized {
+			hostSample.NtpSyncStatus = "synchronized"
+		}
+	}
+	hostSample.NtpStratum = clockSync.Stratum
+	if clockSync.Offset != nil {
+		seconds := clockSync.Offset.Seconds()
+		hostSample.NtpOffset = &seconds
+	}
+	if clockSync.EstimatedError != nil {
+		seconds := clockSync.EstimatedError.Seconds()
+		hostSample.NtpEstimatedError = &seconds
+	}
+}
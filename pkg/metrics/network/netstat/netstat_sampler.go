@@ -0,0 +1,273 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package netstat
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
+)
+
+var sslog = log.WithComponent("NetstatSampler")
+
+// protocolStats holds the kernel network statistics by protocol (e.g. "Tcp", "TcpExt") and name
+// (e.g. "RetransSegs").
+type protocolStats map[string]map[string]int64
+
+func (p protocolStats) value(protocol, name string) (int64, bool) {
+	value, ok := p[protocol][name]
+	return value, ok
+}
+
+type Sampler struct {
+	context   agent.AgentContext
+	lastRun   time.Time
+	lastStats protocolStats
+}
+
+// Sample reports the kernel TCP and UDP statistics of the host network namespace. Counters are reported
+// as per-second rates and socket counts as gauges.
+type Sample struct {
+	sample.BaseEvent
+
+	// TCP connections that transitioned to SYN-SENT from CLOSED
+	TCPActiveOpensPerSec *float64 `json:"tcpActiveOpensPerSecond,omitempty"`
+	// TCP connections that transitioned to SYN-RCVD from LISTEN
+	TCPPassiveOpensPerSec *float64 `json:"tcpPassiveOpensPerSecond,omitempty"`
+	// Failed TCP connection attempts
+	TCPAttemptFailsPerSec *float64 `json:"tcpAttemptFailsPerSecond,omitempty"`
+	// TCP connections reset from the ESTABLISHED or CLOSE-WAIT states
+	TCPEstablishedResetsPerSec *float64 `json:"tcpEstablishedResetsPerSecond,omitempty"`
+	// TCP segments received
+	TCPInSegmentsPerSec *float64 `json:"tcpInSegmentsPerSecond,omitempty"`
+	// TCP segments sent
+	TCPOutSegmentsPerSec *float64 `json:"tcpOutSegmentsPerSecond,omitempty"`
+	// TCP segments retransmitted
+	TCPRetransSegmentsPerSec *float64 `json:"tcpRetransSegmentsPerSecond,omitempty"`
+	// TCP segments received with errors
+	TCPInErrorsPerSec *float64 `json:"tcpInErrorsPerSecond,omitempty"`
+	// TCP segments sent with the RST flag
+	TCPOutResetsPerSec *float64 `json:"tcpOutResetsPerSecond,omitempty"`
+	// Times the accept queue of a listening socket overflowed
+	TCPListenOverflowsPerSec *float64 `json:"tcpListenOverflowsPerSecond,omitempty"`
+	// Connection requests dropped by listening sockets, including the accept queue overflows
+	TCPListenDropsPerSec *float64 `json:"tcpListenDropsPerSecond,omitempty"`
+	// SYN segments retransmitted
+	TCPSynRetransPerSec *float64 `json:"tcpSynRetransPerSecond,omitempty"`
+	// TCP retransmission timeouts
+	TCPTimeoutsPerSec *float64 `json:"tcpTimeoutsPerSecond,omitempty"`
+	// SYN cookies sent because the SYN backlog was full
+	TCPSyncookiesSentPerSec *float64 `json:"tcpSyncookiesSentPerSecond,omitempty"`
+	// UDP datagrams received
+	UDPInDatagramsPerSec *float64 `json:"udpInDatagramsPerSecond,omitempty"`
+	// UDP datagrams sent
+	UDPOutDatagramsPerSec *float64 `json:"udpOutDatagramsPerSecond,omitempty"`
+	// UDP datagrams received for a port without listener
+	UDPNoPortsPerSec *float64 `json:"udpNoPortsPerSecond,omitempty"`
+	// UDP datagrams that couldn't be delivered, other than for the lack of listener
+	UDPInErrorsPerSec *float64 `json:"udpInErrorsPerSecond,omitempty"`
+	// UDP datagrams dropped because the receive buffer was full
+	UDPReceiveBufferErrorsPerSec *float64 `json:"udpReceiveBufferErrorsPerSecond,omitempty"`
+	// UDP datagrams dropped because the send buffer was full
+	UDPSendBufferErrorsPerSec *float64 `json:"udpSendBufferErrorsPerSecond,omitempty"`
+
+	// TCP connections in the ESTABLISHED or CLOSE-WAIT states
+	TCPCurrentEstablished *int64 `json:"tcpCurrentEstablished,omitempty"`
+	// Sockets of any protocol in use
+	SocketsUsed *int64 `json:"socketsUsed,omitempty"`
+	// TCP sockets in use, not including the TIME-WAIT ones
+	TCPSocketsInUse *int64 `json:"tcpSocketsInUse,omitempty"`
+	// TCP sockets not attached to any file descriptor
+	TCPSocketsOrphan *int64 `json:"tcpSocketsOrphan,omitempty"`
+	// TCP sockets in the TIME-WAIT state
+	TCPSocketsTimeWait *int64 `json:"tcpSocketsTimeWait,omitempty"`
+	// TCP sockets allocated, including the TIME-WAIT ones
+	TCPSocketsAllocated *int64 `json:"tcpSocketsAllocated,omitempty"`
+	// UDP sockets in use
+	UDPSocketsInUse *int64 `json:"udpSocketsInUse,omitempty"`
+
+	// IPv4 and IPv6 TCP sockets by state
+	TCPStateEstablished *int64 `json:"tcpStateEstablished,omitempty"`
+	TCPStateSynSent     *int64 `json:"tcpStateSynSent,omitempty"`
+	TCPStateSynRecv     *int64 `json:"tcpStateSynRecv,omitempty"`
+	TCPStateFinWait1    *int64 `json:"tcpStateFinWait1,omitempty"`
+	TCPStateFinWait2    *int64 `json:"tcpStateFinWait2,omitempty"`
+	TCPStateTimeWait    *int64 `json:"tcpStateTimeWait,omitempty"`
+	TCPStateClose       *int64 `json:"tcpStateClose,omitempty"`
+	TCPStateCloseWait   *int64 `json:"tcpStateCloseWait,omitempty"`
+	TCPStateLastAck     *int64 `json:"tcpStateLastAck,omitempty"`
+	TCPStateListen      *int64 `json:"tcpStateListen,omitempty"`
+	TCPStateClosing     *int64 `json:"tcpStateClosing,omitempty"`
+}
+
+// counters maps the kernel counters to the Sample field reporting their rate.
+var counters = []struct {
+	protocol string
+	name     string
+	field    func(*Sample) **float64
+}{
+	{"Tcp", "ActiveOpens", func(s *Sample) **float64 { return &s.TCPActiveOpensPerSec }},
+	{"Tcp", "PassiveOpens", func(s *Sample) **float64 { return &s.TCPPassiveOpensPerSec }},
+	{"Tcp", "AttemptFails", func(s *Sample) **float64 { return &s.TCPAttemptFailsPerSec }},
+	{"Tcp", "EstabResets", func(s *Sample) **float64 { return &s.TCPEstablishedResetsPerSec }},
+	{"Tcp", "InSegs", func(s *Sample) **float64 { return &s.TCPInSegmentsPerSec }},
+	{"Tcp", "OutSegs", func(s *Sample) **float64 { return &s.TCPOutSegmentsPerSec }},
+	{"Tcp", "RetransSegs", func(s *Sample) **float64 { return &s.TCPRetransSegmentsPerSec }},
+	{"Tcp", "InErrs", func(s *Sample) **float64 { return &s.TCPInErrorsPerSec }},
+	{"Tcp", "OutRsts", func(s *Sample) **float64 { return &s.TCPOutResetsPerSec }},
+	{"TcpExt", "ListenOverflows", func(s *Sample) **float64 { return &s.TCPListenOverflowsPerSec }},
+	{"TcpExt", "ListenDrops", func(s *Sample) **float64 { return &s.TCPListenDropsPerSec }},
+	{"TcpExt", "TCPSynRetrans", func(s *Sample) **float64 { return &s.TCPSynRetransPerSec }},
+	{"TcpExt", "TCPTimeouts", func(s *Sample) **float64 { return &s.TCPTimeoutsPerSec }},
+	{"TcpExt", "SyncookiesSent", func(s *Sample) **float64 { return &s.TCPSyncookiesSentPerSec }},
+	{"Udp", "InDatagrams", func(s *Sample) **float64 { return &s.UDPInDatagramsPerSec }},
+	{"Udp", "OutDatagrams", func(s *Sample) **float64 { return &s.UDPOutDatagramsPerSec }},
+	{"Udp", "NoPorts", func(s *Sample) **float64 { return &s.UDPNoPortsPerSec }},
+	{"Udp", "InErrors", func(s *Sample) **float64 { return &s.UDPInErrorsPerSec }},
+	{"Udp", "RcvbufErrors", func(s *Sample) **float64 { return &s.UDPReceiveBufferErrorsPerSec }},
+	{"Udp", "SndbufErrors", func(s *Sample) **float64 { return &s.UDPSendBufferErrorsPerSec }},
+}
+
+// tcpStateProtocol is the protocol of the TCP sockets count by state, tallied from the tcp and tcp6 files.
+const tcpStateProtocol = "TcpState"
+
+// tcpStates are the names of the TCP states, by their code in the st column of the tcp and tcp6 files.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// gauges maps the kernel gauges to the Sample field reporting them. The "sockets", "TCP" and "UDP"
+// protocols come from the sockstat file.
+var gauges = []struct {
+	protocol string
+	name     string
+	field    func(*Sample) **int64
+}{
+	{"Tcp", "CurrEstab", func(s *Sample) **int64 { return &s.TCPCurrentEstablished }},
+	{"sockets", "used", func(s *Sample) **int64 { return &s.SocketsUsed }},
+	{"TCP", "inuse", func(s *Sample) **int64 { return &s.TCPSocketsInUse }},
+	{"TCP", "orphan", func(s *Sample) **int64 { return &s.TCPSocketsOrphan }},
+	{"TCP", "tw", func(s *Sample) **int64 { return &s.TCPSocketsTimeWait }},
+	{"TCP", "alloc", func(s *Sample) **int64 { return &s.TCPSocketsAllocated }},
+	{"UDP", "inuse", func(s *Sample) **int64 { return &s.UDPSocketsInUse }},
+	{tcpStateProtocol, "ESTABLISHED", func(s *Sample) **int64 { return &s.TCPStateEstablished }},
+	{tcpStateProtocol, "SYN_SENT", func(s *Sample) **int64 { return &s.TCPStateSynSent }},
+	{tcpStateProtocol, "SYN_RECV", func(s *Sample) **int64 { return &s.TCPStateSynRecv }},
+	{tcpStateProtocol, "FIN_WAIT1", func(s *Sample) **int64 { return &s.TCPStateFinWait1 }},
+	{tcpStateProtocol, "FIN_WAIT2", func(s *Sample) **int64 { return &s.TCPStateFinWait2 }},
+	{tcpStateProtocol, "TIME_WAIT", func(s *Sample) **int64 { return &s.TCPStateTimeWait }},
+	{tcpStateProtocol, "CLOSE", func(s *Sample) **int64 { return &s.TCPStateClose }},
+	{tcpStateProtocol, "CLOSE_WAIT", func(s *Sample) **int64 { return &s.TCPStateCloseWait }},
+	{tcpStateProtocol, "LAST_ACK", func(s *Sample) **int64 { return &s.TCPStateLastAck }},
+	{tcpStateProtocol, "LISTEN", func(s *Sample) **int64 { return &s.TCPStateListen }},
+	{tcpStateProtocol, "CLOSING", func(s *Sample) **int64 { return &s.TCPStateClosing }},
+}
+
+func (s *Sampler) OnStartup() {}
+
+func (s *Sampler) Name() string {
+	return "NetstatSampler"
+}
+
+func (s *Sampler) Interval() time.Duration {
+	return time.Second * time.Duration(s.context.Config().MetricsNetstatSampleRate)
+}
+
+func (s *Sampler) Disabled() bool {
+	return s.Interval() <= config.FREQ_DISABLE_SAMPLING
+}
+
+func (s *Sampler) Sample() (eventBatch sample.EventBatch, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("Panic in netstat.Sampler: %v\nStack: %s", panicErr, debug.Stack())
+		}
+	}()
+
+	current, err := readStats()
+	if err != nil {
+		sslog.WithError(err).Debug("Unable to retrieve network protocol stats.")
+		return nil, nil
+	}
+	if current == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	ss := newSample(current, s.lastStats, now.Sub(s.lastRun).Seconds())
+	ss.Type("NetstatSample")
+
+	s.lastStats = current
+	s.lastRun = now
+	return sample.EventBatch{ss}, nil
+}
+
+// countTCPStates tallies the sockets of the tcp and tcp6 files by the state of their st column, after
+// the header line:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	 0: 0100007F:0277 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 17927 1 ...
+func countTCPStates(lines []string, stats protocolStats) {
+	if stats[tcpStateProtocol] == nil {
+		stats[tcpStateProtocol] = map[string]int64{}
+		for _, name := range tcpStates {
+			stats[tcpStateProtocol][name] = 0
+		}
+	}
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 4 {
+			continue
+		}
+		if name, ok := tcpStates[strings.ToUpper(fields[3])]; ok {
+			stats[tcpStateProtocol][name]++
+		}
+	}
+}
+
+// newSample builds the sample from the current stats. Rates are only reported when the previous stats
+// are available.
+func newSample(current, previous protocolStats, elapsedSeconds float64) *Sample {
+	ss := &Sample{}
+	for _, g := range gauges {
+		if value, ok := current.value(g.protocol, g.name); ok {
+			*g.field(ss) = &value
+		}
+	}
+	if previous == nil || elapsedSeconds <= 0 {
+		return ss
+	}
+	for _, c := range counters {
+		value, ok := current.value(c.protocol, c.name)
+		lastValue, lastOk := previous.value(c.protocol, c.name)
+		if !ok || !lastOk || value < 0 || lastValue < 0 {
+			continue
+		}
+		rate := acquire.CalculateSafeDelta(uint64(value), uint64(lastValue), elapsedSeconds)
+		*c.field(ss) = &rate
+	}
+	return ss
+}
+
+func NewSampler(context agent.AgentContext) *Sampler {
+	return &Sampler{
+		context: context,
+	}
+}
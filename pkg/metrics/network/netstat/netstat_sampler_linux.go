@@ -0,0 +1,99 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package netstat
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+)
+
+// readStats reads the network statistics and the TCP sockets of the namespace of the init process, which
+// is the host one also when the agent runs in a container with the host /proc mounted through
+// OverrideHostProc.
+func readStats() (protocolStats, error) {
+	stats := protocolStats{}
+	for _, file := range []string{"snmp", "netstat"} {
+		lines, err := acquire.ReadLines(helpers.HostProc("1", "net", file))
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err := parseHeaderValues(lines, stats); err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", file, err)
+		}
+	}
+
+	lines, err := acquire.ReadLines(helpers.HostProc("1", "net", "sockstat"))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	parseKeyValues(lines, stats)
+
+	// tcp6 is missing when IPv6 is disabled
+	for _, file := range []string{"tcp", "tcp6"} {
+		lines, err = acquire.ReadLines(helpers.HostProc("1", "net", file))
+		if err != nil && err != io.EOF {
+			sslog.WithError(err).WithField("file", file).Debug("Unable to read TCP sockets.")
+			continue
+		}
+		countTCPStates(lines, stats)
+	}
+
+	return stats, nil
+}
+
+// parseHeaderValues parses the snmp and netstat files, where each protocol has a line with the names of the
+// statistics followed by a line with their values:
+//
+//	Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens ...
+//	Tcp: 1 200 120000 -1 446 ...
+func parseHeaderValues(lines []string, stats protocolStats) error {
+	for i := 0; i+1 < len(lines); i += 2 {
+		names := strings.Fields(lines[i])
+		values := strings.Fields(lines[i+1])
+		if len(names) == 0 {
+			continue
+		}
+		if len(names) != len(values) || names[0] != values[0] {
+			return fmt.Errorf("mismatching lines for %q", names[0])
+		}
+		protocol := strings.TrimSuffix(names[0], ":")
+		if stats[protocol] == nil {
+			stats[protocol] = map[string]int64{}
+		}
+		for j := 1; j < len(names); j++ {
+			if value, err := strconv.ParseInt(values[j], 10, 64); err == nil {
+				stats[protocol][names[j]] = value
+			}
+		}
+	}
+	return nil
+}
+
+// parseKeyValues parses the sockstat file, where each protocol line holds name and value pairs:
+//
+//	TCP: inuse 4 orphan 0 tw 0 alloc 4 mem 0
+func parseKeyValues(lines []string, stats protocolStats) {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		protocol := strings.TrimSuffix(fields[0], ":")
+		if stats[protocol] == nil {
+			stats[protocol] = map[string]int64{}
+		}
+		for j := 1; j+1 < len(fields); j += 2 {
+			if value, err := strconv.ParseInt(fields[j+1], 10, 64); err == nil {
+				stats[protocol][fields[j]] = value
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package netstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+)
+
+const snmp = `Ip: Forwarding DefaultTTL InReceives
+Ip: 2 64 17142
+Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens PassiveOpens AttemptFails EstabResets CurrEstab InSegs OutSegs RetransSegs InErrs OutRsts InCsumErrors
+Tcp: 1 200 120000 -1 %d 315 74 140 2 17096 17081 %d 0 149 0
+Udp: InDatagrams NoPorts InErrors OutDatagrams RcvbufErrors SndbufErrors InCsumErrors IgnoredMulti MemErrors
+Udp: 46 0 0 46 %d 0 0 0 0
+`
+
+const netstat = `TcpExt: SyncookiesSent ListenOverflows ListenDrops TCPTimeouts TCPSynRetrans
+TcpExt: 0 %d 6 1 2
+IpExt: InNoRoutes InTruncatedPkts
+IpExt: 0 0
+`
+
+const sockstat = `sockets: used 18
+TCP: inuse 4 orphan 1 tw 3 alloc 7 mem 0
+UDP: inuse 2 mem 0
+FRAG: inuse 0 memory 0
+`
+
+const tcp = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0277 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 17927 1 0000000000000000 100 0 0 10 0
+   1: 0F02000A:0016 0202000A:C5C4 01 00000000:00000000 02:0009F8B5 00000000     0        0 21354 4 0000000000000000 20 4 29 10 -1
+   2: 0F02000A:0016 0202000A:C5C6 08 00000000:00000000 00:00000000 00000000     0        0 21360 1 0000000000000000 20 4 29 10 -1
+`
+
+const tcp6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 17938 1 0000000000000000 100 0 0 10 0
+`
+
+func writeNetFiles(t *testing.T, proc string, activeOpens, retrans, rcvbufErrors, listenOverflows int) {
+	t.Helper()
+	dir := filepath.Join(proc, "1", "net")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "snmp"),
+		[]byte(fmt.Sprintf(snmp, activeOpens, retrans, rcvbufErrors)), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "netstat"),
+		[]byte(fmt.Sprintf(netstat, listenOverflows)), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sockstat"), []byte(sockstat), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "tcp"), []byte(tcp), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "tcp6"), []byte(tcp6), 0644))
+}
+
+func TestSampler_Sample(t *testing.T) {
+	// GIVEN the network statistics of the host
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+	writeNetFiles(t, proc, 100, 10, 0, 5)
+
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{MetricsNetstatSampleRate: 10})
+	sampler := NewSampler(ctx)
+
+	// WHEN it's sampled for the first time
+	batch, err := sampler.Sample()
+	require.NoError(t, err)
+
+	// THEN only the gauges are reported
+	require.Len(t, batch, 1)
+	ss := batch[0].(*Sample)
+	assert.Equal(t, "NetstatSample", ss.EventType)
+	assert.Equal(t, int64(2), *ss.TCPCurrentEstablished)
+	assert.Equal(t, int64(18), *ss.SocketsUsed)
+	assert.Equal(t, int64(4), *ss.TCPSocketsInUse)
+	assert.Equal(t, int64(1), *ss.TCPSocketsOrphan)
+	assert.Equal(t, int64(3), *ss.TCPSocketsTimeWait)
+	assert.Equal(t, int64(7), *ss.TCPSocketsAllocated)
+	assert.Equal(t, int64(2), *ss.UDPSocketsInUse)
+	assert.Equal(t, int64(2), *ss.TCPStateListen)
+	assert.Equal(t, int64(1), *ss.TCPStateEstablished)
+	assert.Equal(t, int64(1), *ss.TCPStateCloseWait)
+	assert.Equal(t, int64(0), *ss.TCPStateSynRecv)
+	assert.Nil(t, ss.TCPRetransSegmentsPerSec)
+
+	// WHEN it's sampled again
+	writeNetFiles(t, proc, 120, 30, 4, 15)
+	sampler.lastRun = time.Now().Add(-2 * time.Second)
+	batch, err = sampler.Sample()
+	require.NoError(t, err)
+
+	// THEN the counters are reported as rates
+	require.Len(t, batch, 1)
+	ss = batch[0].(*Sample)
+	assert.InDelta(t, 10, *ss.TCPActiveOpensPerSec, 0.1)
+	assert.InDelta(t, 10, *ss.TCPRetransSegmentsPerSec, 0.1)
+	assert.InDelta(t, 2, *ss.UDPReceiveBufferErrorsPerSec, 0.1)
+	assert.InDelta(t, 5, *ss.TCPListenOverflowsPerSec, 0.1)
+	assert.Equal(t, 0.0, *ss.TCPPassiveOpensPerSec)
+	assert.Equal(t, 0.0, *ss.TCPListenDropsPerSec)
+}
+
+func TestSampler_Sample_NotSupported(t *testing.T) {
+	t.Setenv("HOST_PROC", t.TempDir())
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{MetricsNetstatSampleRate: 10})
+
+	batch, err := NewSampler(ctx).Sample()
+
+	assert.NoError(t, err)
+	assert.Empty(t, batch)
+}
+
+func TestParseHeaderValues_Mismatch(t *testing.T) {
+	err := parseHeaderValues([]string{"Tcp: ActiveOpens PassiveOpens", "Tcp: 1"}, protocolStats{})
+
+	assert.Error(t, err)
+}
+
+func TestCountTCPStates(t *testing.T) {
+	// GIVEN the lines of the tcp and tcp6 files
+	stats := protocolStats{}
+
+	// WHEN the states are tallied
+	countTCPStates(strings.Split(tcp, "\n"), stats)
+	countTCPStates(strings.Split(tcp6, "\n"), stats)
+
+	// THEN each state holds its sockets, absent states being zero
+	assert.Len(t, stats[tcpStateProtocol], len(tcpStates))
+	assert.Equal(t, int64(2), stats[tcpStateProtocol]["LISTEN"])
+	assert.Equal(t, int64(1), stats[tcpStateProtocol]["ESTABLISHED"])
+	assert.Equal(t, int64(1), stats[tcpStateProtocol]["CLOSE_WAIT"])
+	assert.Equal(t, int64(0), stats[tcpStateProtocol]["TIME_WAIT"])
+}
@@ -60,6 +60,9 @@ func NewNetworkSampler(context agent.AgentContext) *NetworkSampler {
 func (ns *NetworkSampler) Name() string { return "NetworkSampler" }
 
 func (ns *NetworkSampler) Interval() time.Duration {
+	if ns.context != nil {
+		return time.Second * time.Duration(ns.context.Config().MetricsNetworkSampleRate)
+	}
 	return ns.sampleInterval
 }
 
@@ -0,0 +1,70 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package metrics
+
+import "time"
+
+// PressureSample reports the Pressure Stall Information (PSI) of the CPU, memory and IO resources. The
+// "some" metrics refer to the share of time at least one task was stalled waiting for the resource, and
+// the "full" ones to the share of time all the non-idle tasks were stalled at once.
+// Averages are percentages over the last 10, 60 and 300 seconds, and stall rates are the microseconds
+// of stall time per second since the previous sample. Fields are only present when the kernel provides them.
+type PressureSample struct {
+	CPUPressureSomeAvg10            *float64 `json:"cpuPressureSomeAvg10,omitempty"`
+	CPUPressureSomeAvg60            *float64 `json:"cpuPressureSomeAvg60,omitempty"`
+	CPUPressureSomeAvg300           *float64 `json:"cpuPressureSomeAvg300,omitempty"`
+	CPUPressureSomeStallUsPerSecond *float64 `json:"cpuPressureSomeStallUsPerSecond,omitempty"`
+	CPUPressureFullAvg10            *float64 `json:"cpuPressureFullAvg10,omitempty"`
+	CPUPressureFullAvg60            *float64 `json:"cpuPressureFullAvg60,omitempty"`
+	CPUPressureFullAvg300           *float64 `json:"cpuPressureFullAvg300,omitempty"`
+	CPUPressureFullStallUsPerSecond *float64 `json:"cpuPressureFullStallUsPerSecond,omitempty"`
+
+	MemoryPressureSomeAvg10            *float64 `json:"memoryPressureSomeAvg10,omitempty"`
+	MemoryPressureSomeAvg60            *float64 `json:"memoryPressureSomeAvg60,omitempty"`
+	MemoryPressureSomeAvg300           *float64 `json:"memoryPressureSomeAvg300,omitempty"`
+	MemoryPressureSomeStallUsPerSecond *float64 `json:"memoryPressureSomeStallUsPerSecond,omitempty"`
+	MemoryPressureFullAvg10            *float64 `json:"memoryPressureFullAvg10,omitempty"`
+	MemoryPressureFullAvg60            *float64 `json:"memoryPressureFullAvg60,omitempty"`
+	MemoryPressureFullAvg300           *float64 `json:"memoryPressureFullAvg300,omitempty"`
+	MemoryPressureFullStallUsPerSecond *float64 `json:"memoryPressureFullStallUsPerSecond,omitempty"`
+
+	IOPressureSomeAvg10            *float64 `json:"ioPressureSomeAvg10,omitempty"`
+	IOPressureSomeAvg60            *float64 `json:"ioPressureSomeAvg60,omitempty"`
+	IOPressureSomeAvg300           *float64 `json:"ioPressureSomeAvg300,omitempty"`
+	IOPressureSomeStallUsPerSecond *float64 `json:"ioPressureSomeStallUsPerSecond,omitempty"`
+	IOPressureFullAvg10            *float64 `json:"ioPressureFullAvg10,omitempty"`
+	IOPressureFullAvg60            *float64 `json:"ioPressureFullAvg60,omitempty"`
+	IOPressureFullAvg300           *float64 `json:"ioPressureFullAvg300,omitempty"`
+	IOPressureFullStallUsPerSecond *float64 `json:"ioPressureFullStallUsPerSecond,omitempty"`
+}
+
+// PressureMonitor samples the Pressure Stall Information, calculating the stall rates from the total stall
+// times of consecutive samples.
+type PressureMonitor struct {
+	lastTotals map[string]uint64 // stall time totals of the previous sample, keyed by resource and kind
+	lastRun    time.Time
+}
+
+// NewPressureMonitor returns a Pressure Stall Information monitor.
+func NewPressureMonitor() *PressureMonitor {
+	return &PressureMonitor{}
+}
+
+// fields returns the avg10, avg60, avg300 and stall rate fields of the given resource and kind of stall.
+func (s *PressureSample) fields(resource, kind string) (avg10, avg60, avg300, stallRate **float64, ok bool) {
+	switch resource + " " + kind {
+	case "cpu some":
+		return &s.CPUPressureSomeAvg10, &s.CPUPressureSomeAvg60, &s.CPUPressureSomeAvg300, &s.CPUPressureSomeStallUsPerSecond, true
+	case "cpu full":
+		return &s.CPUPressureFullAvg10, &s.CPUPressureFullAvg60, &s.CPUPressureFullAvg300, &s.CPUPressureFullStallUsPerSecond, true
+	case "memory some":
+		return &s.MemoryPressureSomeAvg10, &s.MemoryPressureSomeAvg60, &s.MemoryPressureSomeAvg300, &s.MemoryPressureSomeStallUsPerSecond, true
+	case "memory full":
+		return &s.MemoryPressureFullAvg10, &s.MemoryPressureFullAvg60, &s.MemoryPressureFullAvg300, &s.MemoryPressureFullStallUsPerSecond, true
+	case "io some":
+		return &s.IOPressureSomeAvg10, &s.IOPressureSomeAvg60, &s.IOPressureSomeAvg300, &s.IOPressureSomeStallUsPerSecond, true
+	case "io full":
+		return &s.IOPressureFullAvg10, &s.IOPressureFullAvg60, &s.IOPressureFullAvg300, &s.IOPressureFullStallUsPerSecond, true
+	}
+	return nil, nil, nil, nil, false
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+)
+
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// pressureLine holds a parsed line of a /proc/pressure file, e.g.:
+// some avg10=0.12 avg60=0.05 avg300=0.01 total=1234567
+type pressureLine struct {
+	kind   string
+	avg10  float64
+	avg60  float64
+	avg300 float64
+	total  uint64 // stall time in microseconds
+}
+
+// Sample returns the Pressure Stall Information from /proc/pressure, or nil if the kernel doesn't
+// provide it (not supported or disabled).
+func (m *PressureMonitor) Sample() (sample *PressureSample, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("Panic in PressureMonitor.Sample: %v\nStack: %s", panicErr, debug.Stack())
+		}
+	}()
+
+	now := time.Now()
+	var elapsedSeconds float64
+	if m.lastTotals != nil {
+		elapsedSeconds = now.Sub(m.lastRun).Seconds()
+	}
+	totals := map[string]uint64{}
+
+	for _, resource := range pressureResources {
+		lines, err := acquire.ReadLines(helpers.HostProc("pressure", resource))
+		if err != nil && err != io.EOF {
+			// missing on kernels without PSI
+			continue
+		}
+		if sample == nil {
+			sample = &PressureSample{}
+		}
+		for _, line := range parsePressure(lines) {
+			avg10, avg60, avg300, stallRate, ok := sample.fields(resource, line.kind)
+			if !ok {
+				continue
+			}
+			*avg10 = floatPtr(line.avg10)
+			*avg60 = floatPtr(line.avg60)
+			*avg300 = floatPtr(line.avg300)
+
+			key := resource + " " + line.kind
+			totals[key] = line.total
+			if previous, ok := m.lastTotals[key]; ok && elapsedSeconds > 0 {
+				*stallRate = floatPtr(acquire.CalculateSafeDelta(line.total, previous, elapsedSeconds))
+			}
+		}
+	}
+
+	m.lastTotals = totals
+	m.lastRun = now
+	return sample, nil
+}
+
+// parsePressure parses the content of a /proc/pressure file, ignoring malformed lines.
+func parsePressure(lines []string) []pressureLine {
+	var parsed []pressureLine
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			continue
+		}
+		pl := pressureLine{kind: fields[0]}
+		valid := true
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				valid = false
+				break
+			}
+			var err error
+			switch kv[0] {
+			case "avg10":
+				pl.avg10, err = strconv.ParseFloat(kv[1], 64)
+			case "avg60":
+				pl.avg60, err = strconv.ParseFloat(kv[1], 64)
+			case "avg300":
+				pl.avg300, err = strconv.ParseFloat(kv[1], 64)
+			case "total":
+				pl.total, err = strconv.ParseUint(kv[1], 10, 64)
+			}
+			if err != nil {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			parsed = append(parsed, pl)
+		}
+	}
+	return parsed
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package metrics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePressure(t *testing.T) {
+	lines := []string{
+		"some avg10=1.50 avg60=0.75 avg300=0.25 total=123456",
+		"full avg10=0.00 avg60=0.00 avg300=0.00 total=42",
+		"invalid line",
+		"some avg10=wrong avg60=0.75 avg300=0.25 total=123456",
+	}
+
+	assert.Equal(t, []pressureLine{
+		{kind: "some", avg10: 1.5, avg60: 0.75, avg300: 0.25, total: 123456},
+		{kind: "full", total: 42},
+	}, parsePressure(lines))
+}
+
+func writePressureFile(t *testing.T, proc, resource, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(proc, "pressure"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(proc, "pressure", resource), []byte(content), 0644))
+}
+
+func TestPressureMonitor_Sample(t *testing.T) {
+	proc := t.TempDir()
+	t.Setenv("HOST_PROC", proc)
+
+	// GIVEN a kernel providing cpu (without full line) and memory pressure, but not io
+	writePressureFile(t, proc, "cpu", "some avg10=1.50 avg60=0.75 avg300=0.25 total=1000000\n")
+	writePressureFile(t, proc, "memory", "some avg10=0.10 avg60=0.20 avg300=0.30 total=500\nfull avg10=0.01 avg60=0.02 avg300=0.03 total=100\n")
+	m := NewPressureMonitor()
+
+	// WHEN it's sampled for the first time
+	sample, err := m.Sample()
+	require.NoError(t, err)
+	require.NotNil(t, sample)
+
+	// THEN averages are reported, but not the stall rates
+	assert.Equal(t, 1.5, *sample.CPUPressureSomeAvg10)
+	assert.Equal(t, 0.75, *sample.CPUPressureSomeAvg60)
+	assert.Equal(t, 0.25, *sample.CPUPressureSomeAvg300)
+	assert.Nil(t, sample.CPUPressureSomeStallUsPerSecond)
+	assert.Nil(t, sample.CPUPressureFullAvg10)
+	assert.Equal(t, 0.03, *sample.MemoryPressureFullAvg300)
+	assert.Nil(t, sample.IOPressureSomeAvg10)
+
+	// AND stall rates are reported on next samples
+	writePressureFile(t, proc, "cpu", "some avg10=1.50 avg60=0.75 avg300=0.25 total=3000000\n")
+	m.lastRun = time.Now().Add(-2 * time.Second)
+	sample, err = m.Sample()
+	require.NoError(t, err)
+	require.NotNil(t, sample.CPUPressureSomeStallUsPerSecond)
+	assert.InDelta(t, 1000000, *sample.CPUPressureSomeStallUsPerSecond, 10000)
+	assert.Equal(t, float64(0), *sample.MemoryPressureSomeStallUsPerSecond)
+}
+
+func TestPressureMonitor_Sample_NotSupported(t *testing.T) {
+	t.Setenv("HOST_PROC", t.TempDir())
+
+	sample, err := NewPressureMonitor().Sample()
+
+	assert.NoError(t, err)
+	assert.Nil(t, sample)
+}
@@ -0,0 +1,11 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build !linux
+// +build !linux
+
+package metrics
+
+// Sample returns nil, as Pressure Stall Information is only provided by Linux.
+func (m *PressureMonitor) Sample() (*PressureSample, error) {
+	return nil, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package process
+
+import (
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/types"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
+)
+
+// groupKey identifies a group of processes in aggregation mode.
+type groupKey struct {
+	commandName string
+	user        string
+	containerID string
+}
+
+// processGroup accumulates the metrics of the processes of a group.
+type processGroup struct {
+	first *types.ProcessSample
+	count int
+
+	cpuPercent, cpuUserPercent, cpuSystemPercent float64
+	cpuPercentMin, cpuPercentMax                 float64
+	memoryRSS, memoryVMS                         int64
+	memoryRSSMin, memoryRSSMax                   int64
+	threadCount                                  int64
+	fdCount                                      *int64
+	ioReadCount, ioWriteCount                    *float64
+	ioReadBytes, ioWriteBytes                    *float64
+}
+
+func (g *processGroup) add(s *types.ProcessSample) {
+	if g.count == 0 {
+		g.first = s
+		g.cpuPercentMin, g.cpuPercentMax = s.CPUPercent, s.CPUPercent
+		g.memoryRSSMin, g.memoryRSSMax = s.MemoryRSSBytes, s.MemoryRSSBytes
+	}
+	g.count++
+
+	g.cpuPercent += s.CPUPercent
+	g.cpuUserPercent += s.CPUUserPercent
+	g.cpuSystemPercent += s.CPUSystemPercent
+	if s.CPUPercent < g.cpuPercentMin {
+		g.cpuPercentMin = s.CPUPercent
+	}
+	if s.CPUPercent > g.cpuPercentMax {
+		g.cpuPercentMax = s.CPUPercent
+	}
+
+	g.memoryRSS += s.MemoryRSSBytes
+	g.memoryVMS += s.MemoryVMSBytes
+	if s.MemoryRSSBytes < g.memoryRSSMin {
+		g.memoryRSSMin = s.MemoryRSSBytes
+	}
+	if s.MemoryRSSBytes > g.memoryRSSMax {
+		g.memoryRSSMax = s.MemoryRSSBytes
+	}
+
+	g.threadCount += int64(s.ThreadCount)
+	if s.FdCount != nil {
+		if g.fdCount == nil {
+			g.fdCount = new(int64)
+		}
+		*g.fdCount += int64(*s.FdCount)
+	}
+	g.ioReadCount = addRate(g.ioReadCount, s.IOReadCountPerSecond)
+	g.ioWriteCount = addRate(g.ioWriteCount, s.IOWriteCountPerSecond)
+	g.ioReadBytes = addRate(g.ioReadBytes, s.IOReadBytesPerSecond)
+	g.ioWriteBytes = addRate(g.ioWriteBytes, s.IOWriteBytesPerSecond)
+}
+
+func addRate(total, rate *float64) *float64 {
+	if rate == nil {
+		return total
+	}
+	if total == nil {
+		total = new(float64)
+	}
+	*total += *rate
+	return total
+}
+
+// sample returns the ProcessGroupSample of the group. It's a FlatProcessSample, so the process metrics matchers
+// are applied to it.
+func (g *processGroup) sample(byUser, byContainer bool) *types.FlatProcessSample {
+	s := types.FlatProcessSample{
+		"processDisplayName":         g.first.CommandName,
+		"commandName":                g.first.CommandName,
+		"processCount":               g.count,
+		"cpuPercent":                 g.cpuPercent,
+		"cpuUserPercent":             g.cpuUserPercent,
+		"cpuSystemPercent":           g.cpuSystemPercent,
+		"cpuPercentMin":              g.cpuPercentMin,
+		"cpuPercentMax":              g.cpuPercentMax,
+		"memoryResidentSizeBytes":    g.memoryRSS,
+		"memoryResidentSizeBytesMin": g.memoryRSSMin,
+		"memoryResidentSizeBytesMax": g.memoryRSSMax,
+		"memoryVirtualSizeBytes":     g.memoryVMS,
+		"threadCount":                g.threadCount,
+	}
+	if g.fdCount != nil {
+		s["fileDescriptorCount"] = *g.fdCount
+	}
+	if g.ioReadCount != nil {
+		s["ioReadCountPerSecond"] = *g.ioReadCount
+	}
+	if g.ioWriteCount != nil {
+		s["ioWriteCountPerSecond"] = *g.ioWriteCount
+	}
+	if g.ioReadBytes != nil {
+		s["ioReadBytesPerSecond"] = *g.ioReadBytes
+	}
+	if g.ioWriteBytes != nil {
+		s["ioWriteBytesPerSecond"] = *g.ioWriteBytes
+	}
+	if byUser && g.first.User != "" {
+		s["userName"] = g.first.User
+	}
+	if byContainer && g.first.ContainerID != "" {
+		s["containerId"] = g.first.ContainerID
+		s["containerName"] = g.first.ContainerName
+		s["containerImage"] = g.first.ContainerImage
+		s["containerImageName"] = g.first.ContainerImageName
+		s["contained"] = g.first.Contained
+		for name, value := range g.first.ContainerLabels {
+			s["containerLabel_"+name] = value
+		}
+	}
+	s.Type("ProcessGroupSample")
+	return &s
+}
+
+// aggregateSamples groups the process samples by command name, and optionally by user and container, returning
+// a ProcessGroupSample for each group, in the order the groups are first seen.
+func aggregateSamples(samples []*types.ProcessSample, groupBy []string) (results sample.EventBatch) {
+	var byUser, byContainer bool
+	for _, attribute := range groupBy {
+		switch attribute {
+		case config.ProcessAggregationByUser:
+			byUser = true
+		case config.ProcessAggregationByContainer:
+			byContainer = true
+		}
+	}
+
+	var keys []groupKey
+	groups := map[groupKey]*processGroup{}
+	for _, s := range samples {
+		key := groupKey{commandName: s.CommandName}
+		if byUser {
+			key.user = s.User
+		}
+		if byContainer {
+			key.containerID = s.ContainerID
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &processGroup{}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.add(s)
+	}
+
+	for _, key := range keys {
+		results = append(results, groups[key].sample(byUser, byContainer))
+	}
+	return results
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/types"
+)
+
+func aggregationSamples() []*types.ProcessSample {
+	fds := int32(10)
+	readRate := 2.5
+	return []*types.ProcessSample{
+		{ProcessID: 1, CommandName: "nginx", User: "root", CPUPercent: 1, CPUUserPercent: 0.5, MemoryRSSBytes: 100, MemoryVMSBytes: 1000, ThreadCount: 1, FdCount: &fds},
+		{ProcessID: 2, CommandName: "nginx", User: "www-data", CPUPercent: 10, CPUUserPercent: 8, MemoryRSSBytes: 300, MemoryVMSBytes: 2000, ThreadCount: 4, FdCount: &fds, IOReadBytesPerSecond: &readRate},
+		{ProcessID: 3, CommandName: "bash", User: "root", CPUPercent: 0, MemoryRSSBytes: 50, MemoryVMSBytes: 500, ThreadCount: 1, ContainerID: "abc", ContainerName: "shell", ContainerLabels: map[string]string{"team": "core"}},
+		{ProcessID: 4, CommandName: "nginx", User: "www-data", CPUPercent: 5, CPUUserPercent: 4, MemoryRSSBytes: 200, MemoryVMSBytes: 2000, ThreadCount: 4, ContainerID: "abc", ContainerName: "shell"},
+	}
+}
+
+func TestAggregateSamples_ByCommandName(t *testing.T) {
+	// GIVEN processes of two commands
+	samples := aggregationSamples()
+
+	// WHEN they are aggregated without additional attributes
+	results := aggregateSamples(samples, nil)
+
+	// THEN a group is reported per command, in the order they are first seen
+	require.Len(t, results, 2)
+	nginx := *results[0].(*types.FlatProcessSample)
+	bash := *results[1].(*types.FlatProcessSample)
+
+	assert.Equal(t, "ProcessGroupSample", nginx["eventType"])
+	assert.Equal(t, "nginx", nginx["commandName"])
+	assert.Equal(t, "nginx", nginx["processDisplayName"])
+	assert.Equal(t, 3, nginx["processCount"])
+	assert.Equal(t, 16.0, nginx["cpuPercent"])
+	assert.Equal(t, 12.5, nginx["cpuUserPercent"])
+	assert.Equal(t, 1.0, nginx["cpuPercentMin"])
+	assert.Equal(t, 10.0, nginx["cpuPercentMax"])
+	assert.Equal(t, int64(600), nginx["memoryResidentSizeBytes"])
+	assert.Equal(t, int64(100), nginx["memoryResidentSizeBytesMin"])
+	assert.Equal(t, int64(300), nginx["memoryResidentSizeBytesMax"])
+	assert.Equal(t, int64(5000), nginx["memoryVirtualSizeBytes"])
+	assert.Equal(t, int64(9), nginx["threadCount"])
+	assert.Equal(t, int64(20), nginx["fileDescriptorCount"])
+	assert.Equal(t, 2.5, nginx["ioReadBytesPerSecond"])
+	assert.NotContains(t, nginx, "ioWriteBytesPerSecond")
+	assert.NotContains(t, nginx, "userName")
+	assert.NotContains(t, nginx, "containerId")
+
+	assert.Equal(t, 1, bash["processCount"])
+	assert.NotContains(t, bash, "fileDescriptorCount")
+}
+
+func TestAggregateSamples_ByUser(t *testing.T) {
+	results := aggregateSamples(aggregationSamples(), []string{config.ProcessAggregationByUser})
+
+	require.Len(t, results, 3)
+	nginxRoot := *results[0].(*types.FlatProcessSample)
+	nginxWWW := *results[1].(*types.FlatProcessSample)
+	assert.Equal(t, "root", nginxRoot["userName"])
+	assert.Equal(t, 1, nginxRoot["processCount"])
+	assert.Equal(t, "www-data", nginxWWW["userName"])
+	assert.Equal(t, 2, nginxWWW["processCount"])
+	assert.Equal(t, 15.0, nginxWWW["cpuPercent"])
+}
+
+func TestAggregateSamples_ByContainer(t *testing.T) {
+	results := aggregateSamples(aggregationSamples(), []string{config.ProcessAggregationByContainer})
+
+	require.Len(t, results, 3)
+	nginxHost := *results[0].(*types.FlatProcessSample)
+	bash := *results[1].(*types.FlatProcessSample)
+	nginxContainer := *results[2].(*types.FlatProcessSample)
+
+	assert.Equal(t, 2, nginxHost["processCount"])
+	assert.NotContains(t, nginxHost, "containerId")
+	assert.Equal(t, "abc", bash["containerId"])
+	assert.Equal(t, "shell", bash["containerName"])
+	assert.Equal(t, "core", bash["containerLabel_team"])
+	assert.Equal(t, 1, nginxContainer["processCount"])
+	assert.Equal(t, "abc", nginxContainer["containerId"])
+}
@@ -25,6 +25,7 @@ type processSampler struct {
 	lastRun          time.Time
 	hasAlreadyRun    bool
 	interval         time.Duration
+	ctx              agent.AgentContext // nil when not configured, it provides the reloadable options
 	cache            *cache
 }
 
@@ -50,12 +51,16 @@ func NewProcessSampler(ctx agent.AgentContext) sampler.Sampler {
 	harvest := newHarvester(ctx, &cache)
 	dockerSampler := metrics.NewDockerSampler(time.Duration(ttlSecs)*time.Second, apiVersion)
 
-	return &processSampler{
+	ps := &processSampler{
 		harvest:          harvest,
 		containerSampler: dockerSampler,
 		cache:            &cache,
 		interval:         time.Second * time.Duration(interval),
 	}
+	if hasConfig {
+		ps.ctx = ctx
+	}
+	return ps
 }
 
 func (ps *processSampler) OnStartup() {}
@@ -64,7 +69,18 @@ func (ps *processSampler) Name() string {
 	return "ProcessSampler"
 }
 
+// config returns the current agent config, replaced on reload, or nil when not configured.
+func (ps *processSampler) config() *config.Config {
+	if ps.ctx == nil {
+		return nil
+	}
+	return ps.ctx.Config()
+}
+
 func (ps *processSampler) Interval() time.Duration {
+	if cfg := ps.config(); cfg != nil {
+		return time.Second * time.Duration(cfg.MetricsProcessSampleRate)
+	}
 	return ps.interval
 }
 
@@ -106,6 +122,7 @@ func (ps *processSampler) Sample() (results sample.EventBatch, err error) {
 		}
 	}
 
+	var processSamples []*types.ProcessSample
 	for _, pid := range pids {
 		var processSample *types.ProcessSample
 		var err error
@@ -125,7 +142,19 @@ func (ps *processSampler) Sample() (results sample.EventBatch, err error) {
 			dockerDecorator.Decorate(processSample)
 		}
 
-		results = append(results, ps.normalizeSample(processSample))
+		processSamples = append(processSamples, processSample)
+	}
+
+	cfg := ps.config()
+	if cfg != nil && cfg.ProcessAggregation {
+		results = aggregateSamples(processSamples, cfg.ProcessAggregationBy)
+	} else {
+		if cfg != nil {
+			markTopProcesses(processSamples, cfg.ProcessTopNByCPU, cfg.ProcessTopNByMemory)
+		}
+		for _, processSample := range processSamples {
+			results = append(results, ps.normalizeSample(processSample))
+		}
 	}
 
 	ps.cache.items.RemoveUntilLen(len(pids))
@@ -0,0 +1,34 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package process
+
+import (
+	"sort"
+
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/types"
+)
+
+// markTopProcesses flags as TopProcess the samples of the byCPU processes with the highest CPU usage and the
+// byMemory processes with the highest resident memory, so the metrics matchers include them along with the ones
+// matching include_matching_metrics. Nothing is flagged when both are 0 or less.
+func markTopProcesses(samples []*types.ProcessSample, byCPU, byMemory int) {
+	markTop := func(n int, less func(a, b *types.ProcessSample) bool) {
+		if n <= 0 {
+			return
+		}
+		sorted := make([]*types.ProcessSample, len(samples))
+		copy(sorted, samples)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return less(sorted[j], sorted[i])
+		})
+		if n > len(sorted) {
+			n = len(sorted)
+		}
+		for _, s := range sorted[:n] {
+			s.TopProcess = true
+		}
+	}
+	markTop(byCPU, func(a, b *types.ProcessSample) bool { return a.CPUPercent < b.CPUPercent })
+	markTop(byMemory, func(a, b *types.ProcessSample) bool { return a.MemoryRSSBytes < b.MemoryRSSBytes })
+}
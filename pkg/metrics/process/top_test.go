@@ -0,0 +1,51 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/types"
+)
+
+func TestMarkTopProcesses(t *testing.T) {
+	topPids := func(samples []*types.ProcessSample) (result []int32) {
+		for _, s := range samples {
+			if s.TopProcess {
+				result = append(result, s.ProcessID)
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		byCPU    int
+		byMemory int
+		want     []int32
+	}{
+		{"disabled", 0, 0, nil},
+		{"by cpu", 2, 0, []int32{2, 5}},
+		{"by memory", 0, 1, []int32{4}},
+		{"union", 2, 2, []int32{1, 2, 4, 5}},
+		{"more than available", 10, 0, []int32{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := []*types.ProcessSample{
+				{ProcessID: 1, CPUPercent: 1, MemoryRSSBytes: 500},
+				{ProcessID: 2, CPUPercent: 50, MemoryRSSBytes: 10},
+				{ProcessID: 3, CPUPercent: 20, MemoryRSSBytes: 20},
+				{ProcessID: 4, CPUPercent: 0, MemoryRSSBytes: 1000},
+				{ProcessID: 5, CPUPercent: 30, MemoryRSSBytes: 5},
+			}
+
+			markTopProcesses(samples, tt.byCPU, tt.byMemory)
+
+			assert.Equal(t, tt.want, topPids(samples))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/newrelic/infrastructure-agent/internal/agent/cmdchannel/fflag"
@@ -55,10 +56,41 @@ func init() {
 			"CmdLine",     // Field name from ProcessSample
 			"commandLine", // Field name from FlatProcessSample (i.e. the map key name)
 		},
+		"process.user":           []string{"User", "userName"},
+		"process.parent_pid":     []string{"ParentProcessID", "parentProcessId"},
+		"process.cpu_percent":    []string{"CPUPercent", "cpuPercent"},
+		"process.memory_rss":     []string{"MemoryRSSBytes", "memoryResidentSizeBytes"},
+		"process.memory_virtual": []string{"MemoryVMSBytes", "memoryVirtualSizeBytes"},
+		"process.thread_count":   []string{"ThreadCount", "threadCount"},
+		"process.fd_count":       []string{"FdCount", "fileDescriptorCount"},
+		"container.name":         []string{"ContainerName", "containerName"},
+		"container.image":        []string{"ContainerImageName", "containerImageName"},
 	}
 	regexCache = regexCompiledCache{}
 }
 
+// containerLabelDimension is the prefix of the dimensions matching a container label, e.g. "container.label.app".
+// Container labels are only present in FlatProcessSample, as "containerLabel_<name>" keys.
+const containerLabelDimension = "container.label."
+
+// comparisonExpr matches the numeric comparison expressions, e.g. "> 5" or ">= 100MB".
+var comparisonExpr = regexp.MustCompile(`^(>=|<=|==|!=|>|<)\s*([-+]?[0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+// sizeUnits are the multipliers of the units accepted by the numeric comparison expressions.
+var sizeUnits = map[string]float64{
+	"":   1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+	"tb": 1 << 40,
+}
+
+// comparison is the expected value of a numeric comparison expression.
+type comparison struct {
+	operator string
+	value    float64
+}
+
 type matcher struct {
 	PropertyName  []string
 	ExpectedValue interface{}
@@ -138,7 +170,69 @@ func skipSample(sample interface{}, typesToEvaluate map[string]bool) bool {
 }
 
 func literalExpressionEvaluator(expected interface{}, actual interface{}) bool {
-	return expected == actual
+	// non-string attributes, like the parent PID, are compared with their string representation
+	return expected == actual || expected == fmt.Sprintf("%v", actual)
+}
+
+func numericExpressionEvaluator(expected interface{}, actual interface{}) bool {
+	c := expected.(comparison)
+	value, ok := toFloat(actual)
+	if !ok {
+		return false
+	}
+	switch c.operator {
+	case ">":
+		return value > c.value
+	case ">=":
+		return value >= c.value
+	case "<":
+		return value < c.value
+	case "<=":
+		return value <= c.value
+	case "==":
+		return value == c.value
+	case "!=":
+		return value != c.value
+	}
+	return false
+}
+
+// toFloat converts a numeric attribute value, which might be a pointer, to float64.
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// parseComparison parses a numeric comparison expression, whose value might have a KB, MB, GB or TB size
+// unit (powers of 1024).
+func parseComparison(expr string) (comparison, error) {
+	parts := comparisonExpr.FindStringSubmatch(strings.TrimSpace(expr))
+	if parts == nil {
+		return comparison{}, fmt.Errorf("invalid comparison expression")
+	}
+	value, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return comparison{}, err
+	}
+	unit, ok := sizeUnits[strings.ToLower(parts[3])]
+	if !ok {
+		return comparison{}, fmt.Errorf("unknown unit %q", parts[3])
+	}
+	return comparison{operator: parts[1], value: value * unit}, nil
 }
 
 func regularExpressionEvaluator(expected interface{}, actual interface{}) bool {
@@ -157,6 +251,10 @@ func build(dimensionName string, expr string) ExpressionMatcher {
 	// "false" will make the chain continue (until either there is a "true" result or there's no more matchers),
 	// so this matcher basically get's ignored in the current implementation
 	mappedAttributeName, found := attrCache[dimensionName]
+	if !found && strings.HasPrefix(dimensionName, containerLabelDimension) {
+		mappedAttributeName = []string{"containerLabel_" + strings.TrimPrefix(dimensionName, containerLabelDimension)}
+		found = true
+	}
 	if !found {
 		return constantMatcher{value: false}
 	}
@@ -173,6 +271,14 @@ func build(dimensionName string, expr string) ExpressionMatcher {
 		}
 		eval.ExpectedValue = regex
 		eval.Evaluator = regularExpressionEvaluator
+	} else if strings.IndexAny(strings.TrimSpace(expr), "<>=!") == 0 {
+		c, err := parseComparison(expr)
+		if err != nil {
+			mlog.WithError(err).Error(fmt.Sprintf("could not intitilize expression matcher for the provided configuration: '%s'", expr))
+			return constantMatcher{value: false}
+		}
+		eval.ExpectedValue = c
+		eval.Evaluator = numericExpressionEvaluator
 	} else {
 		eval.ExpectedValue = strings.TrimSpace(strings.Trim(expr, `"`))
 		eval.Evaluator = literalExpressionEvaluator
@@ -270,8 +376,52 @@ func (ne constantMatcher) String() string {
 }
 
 // NewSampleMatchFn creates new includeSampleMatchFn func, enableProcessMetrics might be nil when
-// value was not set.
-func NewSampleMatchFn(enableProcessMetrics *bool, includeMetricsMatchers config.IncludeMetricsMap, ffRetriever feature_flags.Retriever) IncludeSampleMatchFn {
+// value was not set. The top processes are included along with the ones included by the rest of options,
+// unless process metrics are disabled. Samples matching any of the exclude matchers are excluded, even if
+// they are included by the rest of options.
+func NewSampleMatchFn(enableProcessMetrics *bool, includeMetricsMatchers, excludeMetricsMatchers config.IncludeMetricsMap, ffRetriever feature_flags.Retriever) IncludeSampleMatchFn {
+	includeFn := newIncludeSampleMatchFn(enableProcessMetrics, includeMetricsMatchers, ffRetriever)
+	if !excludeProcessMetrics(enableProcessMetrics) {
+		includeFn = includeTopProcesses(includeFn)
+	}
+
+	ec := NewMatcherChain(excludeMetricsMatchers)
+	if !ec.Enabled {
+		return includeFn
+	}
+	mlog.
+		WithField(config.TracesFieldName, config.FeatureTrace).
+		Trace("Exclusion rules ARE defined, matching process metrics will be DISABLED")
+	return func(sample interface{}) bool {
+		if !includeFn(sample) {
+			return false
+		}
+		// the chain matches any sample it doesn't evaluate, which must not be excluded
+		return skipSample(sample, typesToEvaluate) || !ec.Evaluate(sample)
+	}
+}
+
+// includeTopProcesses includes the process samples flagged by the top-N selection of the process sampler,
+// along with the ones included by includeFn.
+func includeTopProcesses(includeFn IncludeSampleMatchFn) IncludeSampleMatchFn {
+	return func(sample interface{}) bool {
+		return includeFn(sample) || isTopProcess(sample)
+	}
+}
+
+func isTopProcess(sample interface{}) bool {
+	switch s := sample.(type) {
+	case *types.ProcessSample:
+		return s.TopProcess
+	case *types.FlatProcessSample:
+		top, _ := (*s)["topProcess"].(bool)
+		return top
+	default:
+		return false
+	}
+}
+
+func newIncludeSampleMatchFn(enableProcessMetrics *bool, includeMetricsMatchers config.IncludeMetricsMap, ffRetriever feature_flags.Retriever) IncludeSampleMatchFn {
 	// configuration option always takes precedence over FF and matchers configuration
 	if enableProcessMetrics == nil {
 		// if config option is not set, check if we have rules defined. those take precedence over the FF
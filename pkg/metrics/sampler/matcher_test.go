@@ -576,10 +576,17 @@ func TestNewSampleMatchFn(t *testing.T) {
 	trueVar := true
 	falseVar := false
 	emptyMatchers := config.IncludeMetricsMap{}
+	topProcessSample := fixture.ProcessSample
+	topProcessSample.TopProcess = true
+	topFlatProcessSample := types.FlatProcessSample{"topProcess": true}
+	for k, v := range fixture.FlatProcessSample {
+		topFlatProcessSample[k] = v
+	}
 
 	type args struct {
 		enableProcessMetrics   *bool
 		includeMetricsMatchers config.IncludeMetricsMap
+		excludeMetricsMatchers config.IncludeMetricsMap
 		ffRetriever            feature_flags.Retriever
 		sample                 interface{}
 	}
@@ -738,11 +745,148 @@ func TestNewSampleMatchFn(t *testing.T) {
 			},
 			include: false,
 		},
+		{
+			name: "process samples matching exclusion rules are not included",
+			args: args{
+				enableProcessMetrics:   &trueVar,
+				excludeMetricsMatchers: config.IncludeMetricsMap{"process.user": []string{"baz"}},
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &fixture.ProcessSample,
+			},
+			include: false,
+		},
+		{
+			name: "flat process samples matching exclusion rules are not included",
+			args: args{
+				enableProcessMetrics:   &trueVar,
+				includeMetricsMatchers: config.IncludeMetricsMap{"process.name": []string{"foo"}},
+				excludeMetricsMatchers: config.IncludeMetricsMap{"process.executable": []string{"regex \"^/usr/bin/\""}},
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &fixture.FlatProcessSample,
+			},
+			include: false,
+		},
+		{
+			name: "process samples not matching exclusion rules are included",
+			args: args{
+				enableProcessMetrics:   &trueVar,
+				excludeMetricsMatchers: config.IncludeMetricsMap{"process.user": []string{"root"}},
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &fixture.ProcessSample,
+			},
+			include: true,
+		},
+		{
+			name: "top process samples not matching rules are included",
+			args: args{
+				enableProcessMetrics:   &trueVar,
+				includeMetricsMatchers: config.IncludeMetricsMap{"process.name": []string{"regex \"bar*\""}},
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &topProcessSample,
+			},
+			include: true,
+		},
+		{
+			name: "top flat process samples not matching rules are included",
+			args: args{
+				includeMetricsMatchers: config.IncludeMetricsMap{"process.name": []string{"regex \"bar*\""}},
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &topFlatProcessSample,
+			},
+			include: true,
+		},
+		{
+			name: "top process samples matching exclusion rules are not included",
+			args: args{
+				enableProcessMetrics:   &trueVar,
+				includeMetricsMatchers: config.IncludeMetricsMap{"process.name": []string{"regex \"bar*\""}},
+				excludeMetricsMatchers: config.IncludeMetricsMap{"process.user": []string{"baz"}},
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &topProcessSample,
+			},
+			include: false,
+		},
+		{
+			name: "when enableProcessMetrics is FALSE top process samples are excluded",
+			args: args{
+				enableProcessMetrics:   &falseVar,
+				includeMetricsMatchers: emptyMatchers,
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &topProcessSample,
+			},
+			include: false,
+		},
+		{
+			name: "non process samples are not excluded by exclusion rules",
+			args: args{
+				enableProcessMetrics:   &trueVar,
+				excludeMetricsMatchers: config.IncludeMetricsMap{"process.user": []string{"root"}},
+				ffRetriever:            testFF.EmptyFFRetriever,
+				sample:                 &fixture.NetworkSample,
+			},
+			include: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			matchFn := sampler.NewSampleMatchFn(tt.args.enableProcessMetrics, tt.args.includeMetricsMatchers, tt.args.ffRetriever)
+			matchFn := sampler.NewSampleMatchFn(tt.args.enableProcessMetrics, tt.args.includeMetricsMatchers, tt.args.excludeMetricsMatchers, tt.args.ffRetriever)
 			assert.Equal(t, tt.include, matchFn(tt.args.sample))
 		})
 	}
 }
+
+func Test_EvaluatorChain_ExtendedDimensions(t *testing.T) {
+	fdCount := int32(120)
+	processSample := &types.ProcessSample{
+		ProcessDisplayName: "java",
+		User:               "app",
+		ParentProcessID:    1,
+		CPUPercent:         12.5,
+		MemoryRSSBytes:     200 * 1024 * 1024,
+		ThreadCount:        40,
+		FdCount:            &fdCount,
+		ContainerName:      "web",
+		ContainerImageName: "nginx:1.21",
+	}
+	// FlatProcessSample values come from unmarshalled JSON, so numbers are float64
+	flatProcessSample := &types.FlatProcessSample{
+		"processDisplayName":      "java",
+		"parentProcessId":         float64(1),
+		"cpuPercent":              float64(1.5),
+		"memoryResidentSizeBytes": float64(50 * 1024 * 1024),
+		"containerName":           "web",
+		"containerLabel_team":     "payments",
+	}
+
+	tests := []struct {
+		name  string
+		input interface{}
+		rules config.IncludeMetricsMap
+		want  bool
+	}{
+		{"user", processSample, config.IncludeMetricsMap{"process.user": {"app"}}, true},
+		{"parent pid", processSample, config.IncludeMetricsMap{"process.parent_pid": {"1"}}, true},
+		{"flat parent pid", flatProcessSample, config.IncludeMetricsMap{"process.parent_pid": {"1"}}, true},
+		{"cpu greater than", processSample, config.IncludeMetricsMap{"process.cpu_percent": {"> 5"}}, true},
+		{"flat cpu greater than", flatProcessSample, config.IncludeMetricsMap{"process.cpu_percent": {"> 5"}}, false},
+		{"memory with unit", processSample, config.IncludeMetricsMap{"process.memory_rss": {">= 100MB"}}, true},
+		{"flat memory with unit", flatProcessSample, config.IncludeMetricsMap{"process.memory_rss": {">=100MB"}}, false},
+		{"threads lower than", processSample, config.IncludeMetricsMap{"process.thread_count": {"< 10"}}, false},
+		{"pointer value", processSample, config.IncludeMetricsMap{"process.fd_count": {"!= 0"}}, true},
+		{"invalid comparison", processSample, config.IncludeMetricsMap{"process.cpu_percent": {"> 5 parsecs"}}, false},
+		{"container name", processSample, config.IncludeMetricsMap{"container.name": {"web"}}, true},
+		{"container image", processSample, config.IncludeMetricsMap{"container.image": {"regex \"^nginx:\""}}, true},
+		{"container label", flatProcessSample, config.IncludeMetricsMap{"container.label.team": {"payments"}}, true},
+		{"missing container label", processSample, config.IncludeMetricsMap{"container.label.team": {"payments"}}, false},
+		{"any rule matches", flatProcessSample, config.IncludeMetricsMap{
+			"process.cpu_percent": {"> 5"},
+			"container.name":      {"web"},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := sampler.NewMatcherChain(tt.rules)
+			assert.Equal(t, tt.want, ec.Evaluate(tt.input))
+		})
+	}
+}
@@ -12,6 +12,8 @@ type Sampler interface {
 	Sample() (sample.EventBatch, error)
 	OnStartup()
 	Name() string
+	// Interval is checked again while the sampler runs, so samplers reading it from the agent config pick up
+	// reloaded sample rates.
 	Interval() time.Duration
 	Disabled() bool
 }
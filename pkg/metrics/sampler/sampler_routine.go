@@ -17,15 +17,38 @@ type SamplerRoutine struct {
 	name           string
 	stopChannel    chan bool
 	waitForCleanup *sync.WaitGroup
+	statusLock     sync.RWMutex
+	status         RoutineStatus
+	started        time.Time
+	interval       time.Duration
+}
+
+// RoutineStatus holds the result of the last sample taken by a SamplerRoutine.
+type RoutineStatus struct {
+	// LastSample is the time the last sample was taken. Zero if no sample has been taken yet.
+	LastSample time.Time
+	// Duration is the time the last sample took.
+	Duration time.Duration
+	// Err is the error returned by the last sample, if any.
+	Err error
+	// Stale is true when no sample has been taken for more than twice the sampler interval, e.g.
+	// because the sampler is stuck.
+	Stale bool
 }
 
 var mslog = log.WithField("component", "Sampler routine")
 
+// intervalPollPeriod is how often the routines check whether the interval of their sampler changed, e.g.
+// after an agent config reload, so the change doesn't wait for the next sample.
+var intervalPollPeriod = time.Second
+
 func StartSamplerRoutine(sampler Sampler, sampleQueue chan sample.EventBatch) *SamplerRoutine {
 	sr := &SamplerRoutine{
 		name:           sampler.Name(),
 		stopChannel:    make(chan bool),
 		waitForCleanup: &sync.WaitGroup{},
+		started:        time.Now(),
+		interval:       sampler.Interval(),
 	}
 
 	sampler.OnStartup()
@@ -33,9 +56,17 @@ func StartSamplerRoutine(sampler Sampler, sampleQueue chan sample.EventBatch) *S
 	sr.waitForCleanup.Add(1)
 
 	go func() {
-		ticker := time.NewTicker(sampler.Interval())
+		interval := sr.interval
+		// the ticker stays stopped while the sampler interval isn't positive
+		ticker := time.NewTicker(time.Hour)
+		ticker.Stop()
+		if interval > 0 {
+			ticker.Reset(interval)
+		}
+		poll := time.NewTicker(intervalPollPeriod)
 		defer func() {
 			ticker.Stop()
+			poll.Stop()
 			sr.waitForCleanup.Done()
 		}()
 		mslog.WithField("name", sr.name).Debug("Started sampler routine.")
@@ -43,11 +74,13 @@ func StartSamplerRoutine(sampler Sampler, sampleQueue chan sample.EventBatch) *S
 			select {
 			case <-ticker.C:
 
+				started := time.Now()
 				samples, err := func(s Sampler) (sample.EventBatch, error) {
 					_, trx := instrumentation.SelfInstrumentation.StartTransaction(context.Background(), fmt.Sprintf("sampler.%s", s.Name()))
 					defer trx.End()
 					return s.Sample()
 				}(sampler)
+				sr.setStatus(RoutineStatus{LastSample: started, Duration: time.Since(started), Err: err})
 
 				if err != nil {
 					mslog.WithError(err).WithField("samplerName", sr.name).Error("can't get sample from sampler")
@@ -58,6 +91,18 @@ func StartSamplerRoutine(sampler Sampler, sampleQueue chan sample.EventBatch) *S
 				case <-sr.stopChannel:
 					return
 				}
+			case <-poll.C:
+				// samplers reading their interval from the agent config pick up reloaded sample rates
+				if current := sampler.Interval(); current != interval {
+					mslog.WithField("name", sr.name).WithField("interval", current).Debug("Sampler interval changed.")
+					interval = current
+					sr.setInterval(interval)
+					if interval > 0 {
+						ticker.Reset(interval)
+					} else {
+						ticker.Stop()
+					}
+				}
 			case <-sr.stopChannel:
 				return
 			}
@@ -67,6 +112,31 @@ func StartSamplerRoutine(sampler Sampler, sampleQueue chan sample.EventBatch) *S
 	return sr
 }
 
+// Status returns the result of the last sample.
+func (sr *SamplerRoutine) Status() RoutineStatus {
+	sr.statusLock.RLock()
+	defer sr.statusLock.RUnlock()
+	status := sr.status
+	lastSample := status.LastSample
+	if lastSample.IsZero() {
+		lastSample = sr.started
+	}
+	status.Stale = sr.interval > 0 && time.Since(lastSample) > 2*sr.interval
+	return status
+}
+
+func (sr *SamplerRoutine) setStatus(status RoutineStatus) {
+	sr.statusLock.Lock()
+	defer sr.statusLock.Unlock()
+	sr.status = status
+}
+
+func (sr *SamplerRoutine) setInterval(interval time.Duration) {
+	sr.statusLock.Lock()
+	defer sr.statusLock.Unlock()
+	sr.interval = interval
+}
+
 func (sr *SamplerRoutine) Stop() {
 	close(sr.stopChannel)
 	sr.waitForCleanup.Wait()
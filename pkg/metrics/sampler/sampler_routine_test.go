@@ -4,6 +4,7 @@ package sampler
 
 import (
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -57,3 +58,126 @@ func TestSamplerRoutine(t *testing.T) {
 		}
 	}
 }
+
+type failingSampler struct {
+	mockSampler
+}
+
+func (f *failingSampler) Sample() (sample.EventBatch, error) { return nil, errors.New("sample error") }
+
+func TestSamplerRoutine_Status(t *testing.T) {
+	m := &mockSampler{}
+	sampleQueue := make(chan sample.EventBatch)
+	before := time.Now()
+	routine := StartSamplerRoutine(m, sampleQueue)
+	assert.True(t, routine.Status().LastSample.IsZero())
+
+	<-sampleQueue
+	assert.False(t, routine.Status().LastSample.Before(before))
+	routine.Stop()
+
+	f := &failingSampler{}
+	routine = StartSamplerRoutine(f, sampleQueue)
+	defer routine.Stop()
+	assert.Eventually(t, func() bool {
+		return routine.Status().Err != nil
+	}, time.Second, time.Millisecond)
+}
+
+type reloadableSampler struct {
+	mockSampler
+	interval int64
+}
+
+func (r *reloadableSampler) Sample() (sample.EventBatch, error) {
+	return eventBatch, nil
+}
+
+func (r *reloadableSampler) Interval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&r.interval))
+}
+
+// setInterval changes the interval, as an agent config reload would do.
+func (r *reloadableSampler) setInterval(interval time.Duration) {
+	atomic.StoreInt64(&r.interval, int64(interval))
+}
+
+func setIntervalPollPeriod(t *testing.T, period time.Duration) {
+	former := intervalPollPeriod
+	intervalPollPeriod = period
+	t.Cleanup(func() { intervalPollPeriod = former })
+}
+
+func TestSamplerRoutine_IntervalChange(t *testing.T) {
+	setIntervalPollPeriod(t, 10*time.Millisecond)
+
+	// GIVEN a routine sampling every hour
+	r := &reloadableSampler{interval: int64(time.Hour)}
+	sampleQueue := make(chan sample.EventBatch, 10)
+	routine := StartSamplerRoutine(r, sampleQueue)
+	defer routine.Stop()
+
+	// WHEN the interval is lowered before the first sample
+	r.setInterval(time.Millisecond)
+
+	// THEN the routine samples at the new interval without waiting for the former one
+	select {
+	case <-sampleQueue:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "no sample taken at the new interval")
+	}
+}
+
+func TestSamplerRoutine_StartedDisabled(t *testing.T) {
+	setIntervalPollPeriod(t, 10*time.Millisecond)
+
+	// GIVEN a routine whose sampler is disabled
+	r := &reloadableSampler{interval: 0}
+	sampleQueue := make(chan sample.EventBatch, 10)
+	routine := StartSamplerRoutine(r, sampleQueue)
+	defer routine.Stop()
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, sampleQueue)
+	assert.False(t, routine.Status().Stale)
+
+	// WHEN the sampler gets an interval
+	r.setInterval(time.Millisecond)
+
+	// THEN the routine starts sampling
+	select {
+	case <-sampleQueue:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "no sample taken once enabled")
+	}
+}
+
+type blockingSampler struct {
+	mockSampler
+	release chan struct{}
+}
+
+func (b *blockingSampler) Sample() (sample.EventBatch, error) {
+	<-b.release
+	return eventBatch, nil
+}
+func (b *blockingSampler) Interval() time.Duration { return 50 * time.Millisecond }
+
+func TestSamplerRoutine_StatusStale(t *testing.T) {
+	// GIVEN a sampler that gets stuck on its first sample
+	b := &blockingSampler{release: make(chan struct{})}
+	sampleQueue := make(chan sample.EventBatch)
+	routine := StartSamplerRoutine(b, sampleQueue)
+	defer routine.Stop()
+	assert.False(t, routine.Status().Stale)
+
+	// WHEN no sample is taken for more than twice the interval
+	// THEN the routine is reported as stale
+	assert.Eventually(t, func() bool {
+		return routine.Status().Stale
+	}, time.Second, 10*time.Millisecond)
+
+	// AND it's not stale anymore once the sample is taken
+	close(b.release)
+	<-sampleQueue
+	assert.False(t, routine.Status().Stale)
+}
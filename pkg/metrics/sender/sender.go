@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/internal/agent/status"
 	"github.com/newrelic/infrastructure-agent/pkg/log"
 	"github.com/newrelic/infrastructure-agent/pkg/metrics/sampler"
 	"github.com/newrelic/infrastructure-agent/pkg/sample"
@@ -27,6 +28,8 @@ type Sender struct {
 	stopChannel          chan bool       // Channel will be closed when we want to stop all internal goroutines
 	sampleQueue          chan sample.EventBatch
 	samplers             []sampler.Sampler
+	mtx                  sync.RWMutex              // protects samplers and routines
+	routines             []*sampler.SamplerRoutine // running sampler routines, in the same order as samplers
 }
 
 func NewSender(ctx agent.AgentContext) *Sender {
@@ -44,9 +47,38 @@ func (s *Sender) RegisterSampler(sampler sampler.Sampler) {
 		return
 	}
 
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
 	s.samplers = append(s.samplers, sampler)
 }
 
+// SamplersStatus returns the status of the last sample of each registered sampler.
+func (s *Sender) SamplersStatus() []status.SamplerReport {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	reports := make([]status.SamplerReport, 0, len(s.samplers))
+	for i, smp := range s.samplers {
+		report := status.SamplerReport{
+			Name:     smp.Name(),
+			Interval: smp.Interval().String(),
+		}
+		if i < len(s.routines) {
+			st := s.routines[i].Status()
+			report.LastSample = status.FormatTime(st.LastSample)
+			if !st.LastSample.IsZero() {
+				report.Duration = st.Duration.String()
+			}
+			if st.Err != nil {
+				report.Error = st.Err.Error()
+			}
+			report.Stale = st.Stale
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
 // Start will register the sender with the collector, then start a couple of background
 // routines to handle incoming data and post it to the server periodically.
 func (s *Sender) Start() (err error) {
@@ -84,12 +116,20 @@ func (s *Sender) Stop() (err error) {
 func (s *Sender) scheduleSamplers() {
 	var samplerRoutines []*sampler.SamplerRoutine
 
-	for _, t := range s.samplers {
+	s.mtx.RLock()
+	samplers := s.samplers
+	s.mtx.RUnlock()
+
+	for _, t := range samplers {
 		slog.WithField("sampler", t.Name()).Debug("Starting sampler")
 		sr := sampler.StartSamplerRoutine(t, s.sampleQueue)
 		samplerRoutines = append(samplerRoutines, sr)
 	}
 
+	s.mtx.Lock()
+	s.routines = samplerRoutines
+	s.mtx.Unlock()
+
 	for {
 		select {
 		case samples := <-s.sampleQueue:
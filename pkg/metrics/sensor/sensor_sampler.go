@@ -0,0 +1,103 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+package sensor
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/log"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
+)
+
+var sslog = log.WithComponent("SensorSampler")
+
+// Sensor types and the units their values are reported in.
+const (
+	TypeTemperature = "temperature"
+	TypeFan         = "fan"
+	TypeVoltage     = "voltage"
+	TypePower       = "power"
+
+	UnitCelsius = "celsius"
+	UnitRPM     = "rpm"
+	UnitVolts   = "volts"
+	UnitWatts   = "watts"
+)
+
+// Sensor sources.
+const (
+	SourceHwmon   = "hwmon"
+	SourceThermal = "thermal"
+)
+
+type Sampler struct {
+	context agent.AgentContext
+}
+
+// Sample reports the reading of a hardware sensor.
+type Sample struct {
+	sample.BaseEvent
+
+	// Kernel interface the sensor is read from: hwmon or thermal
+	Source string `json:"sensorSource"`
+	// Type of the sensor: temperature, fan, voltage or power
+	SensorType string `json:"sensorType"`
+	// Name of the chip (hwmon) or zone type (thermal) the sensor belongs to, e.g. "coretemp"
+	Chip string `json:"sensorChip"`
+	// Name of the sensor within the chip, e.g. "temp1", or the thermal zone, e.g. "thermal_zone0"
+	Name string `json:"sensorName"`
+	// Label of the sensor, when provided by the driver, e.g. "Package id 0"
+	Label string `json:"sensorLabel,omitempty"`
+	// Unit of the value and thresholds: celsius, rpm, volts or watts
+	Unit string `json:"sensorUnit"`
+	// Current reading of the sensor
+	Value float64 `json:"sensorValue"`
+	// Maximum value of the sensor, usually a warning threshold
+	MaxValue *float64 `json:"sensorMaxValue,omitempty"`
+	// Critical value of the sensor, usually triggering a hardware shutdown
+	CriticalValue *float64 `json:"sensorCriticalValue,omitempty"`
+}
+
+func (s *Sampler) OnStartup() {}
+
+func (s *Sampler) Name() string {
+	return "SensorSampler"
+}
+
+func (s *Sampler) Interval() time.Duration {
+	return time.Second * time.Duration(s.context.Config().MetricsSensorSampleRate)
+}
+
+func (s *Sampler) Disabled() bool {
+	return s.Interval() <= config.FREQ_DISABLE_SAMPLING
+}
+
+func (s *Sampler) Sample() (eventBatch sample.EventBatch, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("Panic in sensor.Sampler: %v\nStack: %s", panicErr, debug.Stack())
+		}
+	}()
+
+	sensors, err := readSensors()
+	if err != nil {
+		sslog.WithError(err).Debug("Unable to retrieve hardware sensors.")
+		return nil, nil
+	}
+
+	for _, ss := range sensors {
+		ss.Type("SensorSample")
+		eventBatch = append(eventBatch, ss)
+	}
+	return eventBatch, nil
+}
+
+func NewSampler(context agent.AgentContext) *Sampler {
+	return &Sampler{
+		context: context,
+	}
+}
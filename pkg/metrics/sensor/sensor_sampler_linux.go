@@ -0,0 +1,177 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package sensor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/newrelic/infrastructure-agent/pkg/helpers"
+)
+
+// hwmonInputRegexp matches the hwmon input attributes, e.g. "temp1_input" or "power1_average".
+var hwmonInputRegexp = regexp.MustCompile(`^(temp|fan|in|power)(\d+)_(input|average)$`)
+
+// hwmonTypes maps the hwmon attribute prefixes to their sensor type, unit and the divisor converting the
+// attribute values to the unit: millidegrees Celsius, RPM, millivolts and microwatts.
+var hwmonTypes = map[string]struct {
+	sensorType string
+	unit       string
+	divisor    float64
+}{
+	"temp":  {TypeTemperature, UnitCelsius, 1000},
+	"fan":   {TypeFan, UnitRPM, 1},
+	"in":    {TypeVoltage, UnitVolts, 1000},
+	"power": {TypePower, UnitWatts, 1000000},
+}
+
+// readSensors reads the hwmon sensors and the thermal zones from the host /sys.
+func readSensors() ([]*Sample, error) {
+	hwmon, err := readHwmon(helpers.HostSys("class", "hwmon"))
+	if err != nil {
+		return nil, err
+	}
+	thermal, err := readThermalZones(helpers.HostSys("class", "thermal"))
+	if err != nil {
+		return nil, err
+	}
+	return append(hwmon, thermal...), nil
+}
+
+// readHwmon reads the sensors of each hwmon device. Older kernels expose the attributes in the "device"
+// directory of the hwmon device.
+func readHwmon(classDir string) (samples []*Sample, err error) {
+	devices, err := filepath.Glob(filepath.Join(classDir, "hwmon*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(devices)
+
+	for _, device := range devices {
+		dir := device
+		if _, err := os.Stat(filepath.Join(dir, "name")); err != nil {
+			dir = filepath.Join(device, "device")
+		}
+		chip, ok := readString(filepath.Join(dir, "name"))
+		if !ok {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			sslog.WithError(err).WithField("device", device).Debug("Unable to read hwmon device.")
+			continue
+		}
+		for _, f := range files {
+			match := hwmonInputRegexp.FindStringSubmatch(f.Name())
+			if match == nil {
+				continue
+			}
+			sensorName := match[1] + match[2]
+			// the average is only reported by the power sensors without input
+			if match[3] == "average" {
+				if _, err := os.Stat(filepath.Join(dir, sensorName+"_input")); err == nil {
+					continue
+				}
+			}
+			hwType := hwmonTypes[match[1]]
+			value, ok := readScaled(filepath.Join(dir, f.Name()), hwType.divisor)
+			if !ok {
+				continue
+			}
+
+			label, _ := readString(filepath.Join(dir, sensorName+"_label"))
+			s := &Sample{
+				Source:     SourceHwmon,
+				SensorType: hwType.sensorType,
+				Chip:       chip,
+				Name:       sensorName,
+				Label:      label,
+				Unit:       hwType.unit,
+				Value:      value,
+			}
+			if maxValue, ok := readScaled(filepath.Join(dir, sensorName+"_max"), hwType.divisor); ok {
+				s.MaxValue = &maxValue
+			}
+			if critValue, ok := readScaled(filepath.Join(dir, sensorName+"_crit"), hwType.divisor); ok {
+				s.CriticalValue = &critValue
+			}
+			samples = append(samples, s)
+		}
+	}
+	return samples, nil
+}
+
+// readThermalZones reads the temperature of each thermal zone. The "hot" and "critical" trip points are
+// reported as the max and critical values.
+func readThermalZones(classDir string) (samples []*Sample, err error) {
+	zones, err := filepath.Glob(filepath.Join(classDir, "thermal_zone*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(zones)
+
+	for _, zone := range zones {
+		value, ok := readScaled(filepath.Join(zone, "temp"), 1000)
+		if !ok {
+			continue
+		}
+		zoneType, _ := readString(filepath.Join(zone, "type"))
+		s := &Sample{
+			Source:     SourceThermal,
+			SensorType: TypeTemperature,
+			Chip:       zoneType,
+			Name:       filepath.Base(zone),
+			Unit:       UnitCelsius,
+			Value:      value,
+		}
+
+		tripTypes, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+		for _, tripType := range tripTypes {
+			kind, ok := readString(tripType)
+			if !ok || (kind != "hot" && kind != "critical") {
+				continue
+			}
+			temp, ok := readScaled(strings.TrimSuffix(tripType, "_type")+"_temp", 1000)
+			if !ok {
+				continue
+			}
+			if kind == "hot" {
+				s.MaxValue = &temp
+			} else {
+				s.CriticalValue = &temp
+			}
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+func readString(path string) (string, bool) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(content)), true
+}
+
+// readScaled reads an integer attribute, dividing it to convert it to the reported unit. Unreadable
+// attributes, e.g. of sensors whose device is not available, are ignored.
+func readScaled(path string, divisor float64) (float64, bool) {
+	content, ok := readString(path)
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(content, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return float64(value) / divisor, true
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+//go:build linux
+// +build linux
+
+package sensor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/infrastructure-agent/internal/agent/mocks"
+	"github.com/newrelic/infrastructure-agent/pkg/config"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func fixtureSys(t *testing.T) {
+	t.Helper()
+	sys, err := filepath.Abs(filepath.Join("fixtures", "sys"))
+	require.NoError(t, err)
+	t.Setenv("HOST_SYS", sys)
+}
+
+func TestReadSensors(t *testing.T) {
+	// GIVEN a host with hwmon sensors and thermal zones
+	fixtureSys(t)
+
+	// WHEN the sensors are read
+	sensors, err := readSensors()
+
+	// THEN every readable sensor is reported with its thresholds, converted to its unit
+	require.NoError(t, err)
+	expected := []*Sample{
+		{Source: SourceHwmon, SensorType: TypeTemperature, Chip: "coretemp", Name: "temp1", Label: "Package id 0",
+			Unit: UnitCelsius, Value: 45, MaxValue: floatPtr(80), CriticalValue: floatPtr(100)},
+		{Source: SourceHwmon, SensorType: TypeTemperature, Chip: "coretemp", Name: "temp2", Label: "Core 0",
+			Unit: UnitCelsius, Value: 43.5, MaxValue: floatPtr(80), CriticalValue: floatPtr(100)},
+		{Source: SourceHwmon, SensorType: TypeFan, Chip: "nct6775", Name: "fan1", Unit: UnitRPM, Value: 1200},
+		{Source: SourceHwmon, SensorType: TypeFan, Chip: "nct6775", Name: "fan2", Unit: UnitRPM, Value: 0},
+		{Source: SourceHwmon, SensorType: TypeVoltage, Chip: "nct6775", Name: "in0", Label: "Vcore",
+			Unit: UnitVolts, Value: 1.032, MaxValue: floatPtr(1.744)},
+		{Source: SourceHwmon, SensorType: TypePower, Chip: "power_meter", Name: "power1", Unit: UnitWatts, Value: 123.5},
+		{Source: SourceThermal, SensorType: TypeTemperature, Chip: "acpitz", Name: "thermal_zone0",
+			Unit: UnitCelsius, Value: 27.8, MaxValue: floatPtr(95), CriticalValue: floatPtr(119)},
+		{Source: SourceThermal, SensorType: TypeTemperature, Chip: "x86_pkg_temp", Name: "thermal_zone1",
+			Unit: UnitCelsius, Value: 46},
+	}
+	assert.Equal(t, expected, sensors)
+}
+
+func TestReadSensors_NoSensors(t *testing.T) {
+	// GIVEN a host without hwmon nor thermal devices, e.g. a virtual machine
+	t.Setenv("HOST_SYS", t.TempDir())
+
+	// WHEN the sensors are read
+	sensors, err := readSensors()
+
+	// THEN no sensors are reported
+	require.NoError(t, err)
+	assert.Empty(t, sensors)
+}
+
+func TestSampler(t *testing.T) {
+	fixtureSys(t)
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{MetricsSensorSampleRate: 10})
+
+	s := NewSampler(ctx)
+	assert.False(t, s.Disabled())
+	assert.Equal(t, 10*time.Second, s.Interval())
+
+	batch, err := s.Sample()
+	require.NoError(t, err)
+	require.Len(t, batch, 8)
+	for _, event := range batch {
+		assert.Equal(t, "SensorSample", event.(*Sample).EventType)
+	}
+}
+
+func TestSampler_Disabled(t *testing.T) {
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{MetricsSensorSampleRate: config.FREQ_DISABLE_SAMPLING})
+
+	assert.True(t, NewSampler(ctx).Disabled())
+}
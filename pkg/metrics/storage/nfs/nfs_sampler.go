@@ -167,6 +167,9 @@ func (s *Sampler) Name() string {
 }
 
 func (s *Sampler) Interval() time.Duration {
+	if s.context != nil {
+		return time.Second * time.Duration(s.context.Config().MetricsNFSSampleRate)
+	}
 	return s.sampleRate
 }
 
@@ -37,6 +37,11 @@ type BaseSample struct {
 	Device         string `json:"device"`
 	IsReadOnly     string `json:"isReadOnly"`
 	FileSystemType string `json:"filesystemType"`
+	MountOptions   string `json:"mountOptions,omitempty"`
+	// BecameReadOnly is "true" when the filesystem was writable in the previous sample and is read-only now,
+	// e.g. after being remounted read-only on errors. It's not reported on the first sample of a mount point, and
+	// transitions are seen once the partitions cache expires (partitions_ttl).
+	BecameReadOnly string `json:"becameReadOnly,omitempty"`
 	CountersSource string `json:"countersSource,omitempty"` // Source for the IOCounters: wmi, pdh, diskstats
 
 	UsedBytes               *float64 `json:"diskUsedBytes,omitempty"`
@@ -79,6 +84,7 @@ type Sampler struct {
 	lastRun          time.Time
 	lastDiskStats    map[string]IOCountersStat
 	lastSamples      sample.EventBatch
+	lastReadOnly     map[string]bool // read-only state of the previous sample, by mount point
 	hasBootstrapped  bool
 	stopChannel      chan bool
 	waitForCleanup   *sync.WaitGroup
@@ -104,6 +110,7 @@ func NewSampler(context agent.AgentContext) *Sampler {
 		waitForCleanup:   &sync.WaitGroup{},
 		storageUtilities: NewStorageSampleWrapper(context.Config()),
 		sampleRate:       time.Second * time.Duration(sampleRateSec),
+		lastReadOnly:     map[string]bool{},
 	}
 }
 
@@ -138,6 +145,9 @@ func (p *PartitionStat) IsReadOnly() bool {
 }
 
 func (ss *Sampler) Interval() time.Duration {
+	if ss.context != nil {
+		return time.Second * time.Duration(ss.context.Config().MetricsStorageSampleRate)
+	}
 	return ss.sampleRate
 }
 
@@ -202,6 +212,7 @@ func (ss *Sampler) Sample() (samples sample.EventBatch, err error) {
 
 	//make sure we have a set, not a list
 	var activeDevices = map[string]bool{}
+	readOnly := make(map[string]bool, len(partitions))
 
 	// key: sample deviceKey
 	dev2Samples := map[string][]*Sample{}
@@ -223,25 +234,8 @@ func (ss *Sampler) Sample() (samples sample.EventBatch, err error) {
 
 		helpers.LogStructureDetails(sslog, fsUsage, "PartitionUsage", "raw", nil)
 
-		if cfg != nil && len(cfg.FileDevicesIgnored) > 0 {
-			found := false
-			fileDevicesIgnored := cfg.FileDevicesIgnored
-			sslog.WithField("fileDevicesIgnored", fileDevicesIgnored).Debug("Using file device ignored.")
-			for _, deviceName := range fileDevicesIgnored {
-				if strings.Contains(p.Device, deviceName) {
-					sslog.WithFieldsF(func() logrus.Fields {
-						return logrus.Fields{
-							"fileDeviceIgnored": deviceName,
-							"skippedDevice":     p.Device,
-						}
-					}).Debug("Skipping ignored device.")
-					found = true
-					break
-				}
-			}
-			if found {
-				continue
-			}
+		if cfg != nil && isIgnoredDevice(p.Device, cfg.FileDevicesIgnored) {
+			continue
 		}
 
 		s := &Sample{}
@@ -250,6 +244,11 @@ func (ss *Sampler) Sample() (samples sample.EventBatch, err error) {
 		populatePartition(p, s)
 		populateUsage(fsUsage, s)
 
+		readOnly[p.Mountpoint] = p.IsReadOnly()
+		if wasReadOnly, ok := ss.lastReadOnly[p.Mountpoint]; ok {
+			s.BecameReadOnly = strconv.FormatBool(!wasReadOnly && readOnly[p.Mountpoint])
+		}
+
 		// we can have multiple mountpoints for the same device
 		dev2Samples[p.Device] = append(dev2Samples[p.Device], s)
 
@@ -257,6 +256,7 @@ func (ss *Sampler) Sample() (samples sample.EventBatch, err error) {
 	}
 
 	// Gather IO stats if the OS supports it
+	var devSamples sample.EventBatch
 	ioCounters, err := ss.storageUtilities.IOCounters()
 	if err != nil {
 		sslog.WithError(err).Warn("can't get IOCounters")
@@ -310,6 +310,8 @@ func (ss *Sampler) Sample() (samples sample.EventBatch, err error) {
 			if len(noDeviceMappedList) > 0 {
 				sslog.WithField("devices", noDeviceMappedList).Debug("No device mapping.")
 			}
+
+			devSamples = deviceSamples(ioCounters, ss.lastDiskStats, elapsedMs, cfg)
 		}
 		ss.lastDiskStats = ioCounters
 	}
@@ -320,14 +322,38 @@ func (ss *Sampler) Sample() (samples sample.EventBatch, err error) {
 		}
 	}
 	ss.lastSamples = samples
+	ss.lastReadOnly = readOnly
 
 	for _, s := range samples {
 		helpers.LogStructureDetails(sslog, s.(*Sample), "StorageSample", "final", nil)
 	}
 
+	// whole devices are kept out of the last samples, as the disk monitors aggregate the partitions
+	samples = append(samples, devSamples...)
+
 	return samples, nil
 }
 
+// isIgnoredDevice returns true when the device name contains any of the ignored devices.
+func isIgnoredDevice(device string, fileDevicesIgnored []string) bool {
+	if len(fileDevicesIgnored) == 0 {
+		return false
+	}
+	sslog.WithField("fileDevicesIgnored", fileDevicesIgnored).Debug("Using file device ignored.")
+	for _, deviceName := range fileDevicesIgnored {
+		if strings.Contains(device, deviceName) {
+			sslog.WithFieldsF(func() logrus.Fields {
+				return logrus.Fields{
+					"fileDeviceIgnored": deviceName,
+					"skippedDevice":     device,
+				}
+			}).Debug("Skipping ignored device.")
+			return true
+		}
+	}
+	return false
+}
+
 // PartitionsCache avoids polling for partitions on each sample, since they do not change so frequently
 type PartitionsCache struct {
 	ttl             time.Duration
@@ -414,6 +440,7 @@ func populatePartition(p PartitionStat, dest *Sample) {
 	dest.MountPoint = p.Mountpoint // Ensure we use the reported mount point, not the prefixed one
 	dest.Device = p.Device
 	dest.IsReadOnly = strconv.FormatBool(p.IsReadOnly())
+	dest.MountOptions = p.Opts
 }
 
 func asValidFloatPtr(value *float64) *float64 {
@@ -4,6 +4,7 @@ package storage
 
 import (
 	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
 	"github.com/shirou/gopsutil/v3/disk"
 	"strings"
 	"sync"
@@ -20,6 +21,10 @@ var (
 
 type Sample struct {
 	BaseSample
+	InodesUsed        *uint64  `json:"inodesUsed,omitempty"`
+	InodesFree        *uint64  `json:"inodesFree,omitempty"`
+	InodesTotal       *uint64  `json:"inodesTotal,omitempty"`
+	InodesUsedPercent *float64 `json:"inodesUsedPercent,omitempty"`
 }
 
 type DarwinStorageSampleWrapper struct {
@@ -119,11 +124,20 @@ func populateSampleOS(_, _ *Sample) {
 	//intentionally left empty, no OS specific values
 }
 
-func populateUsageOS(_ *disk.UsageStat, _ *Sample) {
-	//intentionally left empty, no OS specific usage values
+// populateUsageOS copies the Usage Stats inside the destination sample, for those metrics that are exclusive of Unix
+func populateUsageOS(fsUsage *disk.UsageStat, dest *Sample) {
+	dest.InodesFree = &fsUsage.InodesFree
+	dest.InodesTotal = &fsUsage.InodesTotal
+	dest.InodesUsed = &fsUsage.InodesUsed
+	dest.InodesUsedPercent = &fsUsage.InodesUsedPercent
 }
 
 func CalculateDeviceMapping(_ map[string]bool, _ bool) (deviceMap map[string]string) {
 	//intentionally left empty, IO per partition not supported yet in darwin
 	return
 }
+
+// deviceSamples is only supported on Linux, where the IO counters of the whole devices are available
+func deviceSamples(_, _ map[string]IOCountersStat, _ int64, _ *config.Config) sample.EventBatch {
+	return nil
+}
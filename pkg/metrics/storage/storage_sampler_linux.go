@@ -13,6 +13,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/newrelic/infrastructure-agent/pkg/config"
 	"github.com/newrelic/infrastructure-agent/pkg/helpers"
 	"github.com/newrelic/infrastructure-agent/pkg/metrics/acquire"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
 	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 )
@@ -58,6 +60,37 @@ type Sample struct {
 	InodesFree        *uint64  `json:"inodesFree,omitempty"`
 	InodesTotal       *uint64  `json:"inodesTotal,omitempty"`
 	InodesUsedPercent *float64 `json:"inodesUsedPercent,omitempty"`
+	ioLatencySample
+}
+
+// ioLatencySample holds the latency and queue metrics calculated from the /proc/diskstats deltas.
+type ioLatencySample struct {
+	// Average time, in milliseconds, of the read requests completed since the last sample, including queueing
+	ReadAwaitMs *float64 `json:"readAwaitMs,omitempty"`
+	// Average time, in milliseconds, of the write requests completed since the last sample, including queueing
+	WriteAwaitMs *float64 `json:"writeAwaitMs,omitempty"`
+	// Average number of requests queued or being serviced since the last sample
+	AvgQueueLen *float64 `json:"avgQueueLen,omitempty"`
+	// Number of requests in flight when the sample was taken
+	CurrentQueueLen *float64 `json:"currentQueueLen,omitempty"`
+}
+
+// DeviceSample reports the IO metrics of a whole block device (e.g. /dev/sda), which include the IO of all
+// its partitions, mounted or not.
+type DeviceSample struct {
+	sample.BaseEvent
+
+	Device                  string   `json:"device"`
+	CountersSource          string   `json:"countersSource,omitempty"`
+	TotalUtilizationPercent *float64 `json:"totalUtilizationPercent,omitempty"`
+	ReadUtilizationPercent  *float64 `json:"readUtilizationPercent,omitempty"`
+	WriteUtilizationPercent *float64 `json:"writeUtilizationPercent,omitempty"`
+	ReadBytesPerSec         *float64 `json:"readBytesPerSecond,omitempty"`
+	WriteBytesPerSec        *float64 `json:"writeBytesPerSecond,omitempty"`
+	ReadWriteBytesPerSecond *float64 `json:"readWriteBytesPerSecond,omitempty"`
+	ReadsPerSec             *float64 `json:"readIoPerSecond,omitempty"`
+	WritesPerSec            *float64 `json:"writeIoPerSecond,omitempty"`
+	ioLatencySample
 }
 
 // Enhanced from GOPSUtil, Adding Utilization
@@ -72,6 +105,7 @@ type LinuxIoCountersStat struct {
 	WriteTime               uint64 `json:"writeTime"`
 	IopsInProgress          uint64 `json:"iopsInProgress"`
 	IoTime                  uint64 `json:"ioTime"`
+	WeightedIoTime          uint64 `json:"weightedIoTime"`
 	Name                    string `json:"name"`
 	SerialNumber            string `json:"serialNumber"`
 	TotalUtilizationPercent uint64 `json:"totalUtilizationPercent"`
@@ -150,7 +184,17 @@ func NewStorageSampleWrapper(cfg *config.Config) SampleWrapper {
 
 // populateSampleOS complements the populateSample function by copying into the destinations the fields from the source
 // that are exclusive of Linux Storage Samples
-func populateSampleOS(_, _ *Sample) {
+func populateSampleOS(source, dest *Sample) {
+	dest.ioLatencySample = source.ioLatencySample.valid()
+}
+
+func (l ioLatencySample) valid() ioLatencySample {
+	return ioLatencySample{
+		ReadAwaitMs:     asValidFloatPtr(l.ReadAwaitMs),
+		WriteAwaitMs:    asValidFloatPtr(l.WriteAwaitMs),
+		AvgQueueLen:     asValidFloatPtr(l.AvgQueueLen),
+		CurrentQueueLen: asValidFloatPtr(l.CurrentQueueLen),
+	}
 }
 
 // populateUsage copies the Usage Stats inside the destination sample, for those metrics that are exclusive of Linux
@@ -195,7 +239,24 @@ func CalculateSampleValues(ioCounter IOCountersStat, ioLastStats IOCountersStat,
 			result.WriteUtilizationPercent = &writeUtilizationPercent
 		}
 		result.TotalUtilizationPercent = &percentUtilized
+
+		// the weighted IO time grows by the number of requests in flight for each millisecond
+		if counter.WeightedIoTime >= lastStats.WeightedIoTime {
+			avgQueueLen := float64(counter.WeightedIoTime-lastStats.WeightedIoTime) / float64(elapsedMs)
+			result.AvgQueueLen = &avgQueueLen
+		}
+	}
+
+	if readCountDelta > 0 && counter.ReadCount > lastStats.ReadCount && counter.ReadTime >= lastStats.ReadTime {
+		readAwait := float64(readTimeDelta) / float64(readCountDelta)
+		result.ReadAwaitMs = &readAwait
+	}
+	if writeCountDelta > 0 && counter.WriteCount > lastStats.WriteCount && counter.WriteTime >= lastStats.WriteTime {
+		writeAwait := float64(writeTimeDelta) / float64(writeCountDelta)
+		result.WriteAwaitMs = &writeAwait
 	}
+	currentQueueLen := float64(counter.IopsInProgress)
+	result.CurrentQueueLen = &currentQueueLen
 
 	readsPerSec := acquire.CalculateSafeDelta(counter.ReadCount, lastStats.ReadCount, elapsedSeconds)
 	writesPerSec := acquire.CalculateSafeDelta(counter.WriteCount, lastStats.WriteCount, elapsedSeconds)
@@ -613,6 +674,10 @@ func fetchIoCounters() (map[string]IOCountersStat, error) {
 		if err != nil {
 			return ret, err
 		}
+		weightedIotime, err := strconv.ParseUint(fields[13], 10, 64)
+		if err != nil {
+			return ret, err
+		}
 		d := LinuxIoCountersStat{
 			ReadBytes:        rbytes * SectorSize,
 			WriteBytes:       wbytes * SectorSize,
@@ -624,6 +689,7 @@ func fetchIoCounters() (map[string]IOCountersStat, error) {
 			WriteTime:        wtime,
 			IopsInProgress:   iopsInProgress,
 			IoTime:           iotime,
+			WeightedIoTime:   weightedIotime,
 		}
 		if d == empty {
 			continue
@@ -636,6 +702,55 @@ func fetchIoCounters() (map[string]IOCountersStat, error) {
 	return ret, nil
 }
 
+// deviceSamples returns a StorageDeviceSample for each whole block device with IO counters in both the current
+// and the last sample. Loop and RAM disks, as well as the ignored devices, are not reported.
+func deviceSamples(ioCounters, lastDiskStats map[string]IOCountersStat, elapsedMs int64, cfg *config.Config) (samples sample.EventBatch) {
+	names := make([]string, 0, len(ioCounters))
+	for name := range ioCounters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		lastStats, ok := lastDiskStats[name]
+		if !ok || !isWholeDevice(name) {
+			continue
+		}
+		device := "/dev/" + name
+		if cfg != nil && isIgnoredDevice(device, cfg.FileDevicesIgnored) {
+			continue
+		}
+		counter := ioCounters[name]
+		ioSample := CalculateSampleValues(counter, lastStats, elapsedMs)
+
+		ds := &DeviceSample{
+			Device:                  device,
+			CountersSource:          counter.Source(),
+			TotalUtilizationPercent: asValidFloatPtr(ioSample.TotalUtilizationPercent),
+			ReadUtilizationPercent:  asValidFloatPtr(ioSample.ReadUtilizationPercent),
+			WriteUtilizationPercent: asValidFloatPtr(ioSample.WriteUtilizationPercent),
+			ReadBytesPerSec:         asValidFloatPtr(ioSample.ReadBytesPerSec),
+			WriteBytesPerSec:        asValidFloatPtr(ioSample.WriteBytesPerSec),
+			ReadWriteBytesPerSecond: asValidFloatPtr(calculateReadWriteBytesPerSecond(ioSample.ReadBytesPerSec, ioSample.WriteBytesPerSec)),
+			ReadsPerSec:             asValidFloatPtr(ioSample.ReadsPerSec),
+			WritesPerSec:            asValidFloatPtr(ioSample.WritesPerSec),
+			ioLatencySample:         ioSample.ioLatencySample.valid(),
+		}
+		ds.Type("StorageDeviceSample")
+		samples = append(samples, ds)
+	}
+	return samples
+}
+
+// isWholeDevice returns true for the devices listed in /sys/block, which doesn't list their partitions.
+func isWholeDevice(name string) bool {
+	if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+		return false
+	}
+	_, err := os.Stat(helpers.HostSys("block", name))
+	return err == nil
+}
+
 // GetDiskSerialNumber returns Serial Number of given device or empty string
 // on error. Name of device is expected, eg. /dev/sda
 func GetDiskSerialNumber(name string) string {
@@ -17,6 +17,7 @@ import (
 	"github.com/shirou/gopsutil/v3/disk"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeviceRegexp(t *testing.T) {
@@ -467,3 +468,139 @@ func TestIgnoredDevice(t *testing.T) {
 	assert.EqualValues(t, usageTotal1, *sample.TotalBytes)
 	assert.EqualValues(t, usageFree1, *sample.FreeBytes)
 }
+
+func TestSample_ReadOnlyTransition(t *testing.T) {
+	ctx := new(mocks.AgentContext)
+	ctx.On("Config").Return(&config.Config{})
+
+	// GIVEN a writable root filesystem
+	wrapper := &MockStorageSampleWrapper{partitions: []PartitionStat{
+		{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Opts: "rw,relatime"},
+	}}
+	ss := NewSampler(ctx)
+	ss.storageUtilities = wrapper
+
+	// WHEN it's sampled for the first time
+	results, err := ss.Sample()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	// THEN the mount options are reported, but not the transition
+	sample := results[0].(*Sample)
+	assert.Equal(t, "rw,relatime", sample.MountOptions)
+	assert.Equal(t, "false", sample.IsReadOnly)
+	assert.Empty(t, sample.BecameReadOnly)
+
+	// WHEN it's still writable in the next sample
+	results, err = ss.Sample()
+	require.NoError(t, err)
+
+	// THEN it didn't become read-only
+	assert.Equal(t, "false", results[0].(*Sample).BecameReadOnly)
+
+	// WHEN it's remounted read-only
+	wrapper.partitions = []PartitionStat{
+		{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Opts: "ro,relatime"},
+	}
+	results, err = ss.Sample()
+	require.NoError(t, err)
+
+	// THEN the transition is reported
+	sample = results[0].(*Sample)
+	assert.Equal(t, "true", sample.IsReadOnly)
+	assert.Equal(t, "true", sample.BecameReadOnly)
+
+	// AND only once
+	results, err = ss.Sample()
+	require.NoError(t, err)
+	assert.Equal(t, "false", results[0].(*Sample).BecameReadOnly)
+}
+
+func TestPopulateUsage_Inodes(t *testing.T) {
+	s := &Sample{}
+	populateUsage(&disk.UsageStat{
+		Total:             1000,
+		Free:              600,
+		Used:              400,
+		InodesTotal:       200,
+		InodesUsed:        150,
+		InodesFree:        50,
+		InodesUsedPercent: 75,
+	}, s)
+
+	assert.EqualValues(t, 200, *s.InodesTotal)
+	assert.EqualValues(t, 150, *s.InodesUsed)
+	assert.EqualValues(t, 50, *s.InodesFree)
+	assert.EqualValues(t, 75, *s.InodesUsedPercent)
+}
+
+func TestCalculateSampleValues_Latency(t *testing.T) {
+	lastStats := &LinuxIoCountersStat{
+		ReadCount: 100, WriteCount: 200, ReadTime: 1000, WriteTime: 5000, IoTime: 2000, WeightedIoTime: 10000,
+	}
+	counter := &LinuxIoCountersStat{
+		ReadCount: 150, WriteCount: 200, ReadTime: 1250, WriteTime: 5000, IoTime: 2500, WeightedIoTime: 13000,
+		IopsInProgress: 3,
+	}
+
+	ioSample := CalculateSampleValues(counter, lastStats, 1000)
+
+	// 250ms for 50 reads
+	require.NotNil(t, ioSample.ReadAwaitMs)
+	assert.Equal(t, 5.0, *ioSample.ReadAwaitMs)
+	// no writes were completed
+	assert.Nil(t, ioSample.WriteAwaitMs)
+	// 3000ms of weighted IO time in 1000ms
+	require.NotNil(t, ioSample.AvgQueueLen)
+	assert.Equal(t, 3.0, *ioSample.AvgQueueLen)
+	require.NotNil(t, ioSample.CurrentQueueLen)
+	assert.Equal(t, 3.0, *ioSample.CurrentQueueLen)
+}
+
+func TestCalculateSampleValues_LatencyCounterReset(t *testing.T) {
+	lastStats := &LinuxIoCountersStat{ReadCount: 100, ReadTime: 1000, WeightedIoTime: 10000}
+	counter := &LinuxIoCountersStat{ReadCount: 10, ReadTime: 100, WeightedIoTime: 500}
+
+	ioSample := CalculateSampleValues(counter, lastStats, 1000)
+
+	assert.Nil(t, ioSample.ReadAwaitMs)
+	assert.Nil(t, ioSample.AvgQueueLen)
+}
+
+func TestDeviceSamples(t *testing.T) {
+	// GIVEN a host with the sda and nvme0n1 whole devices
+	sysDir := t.TempDir()
+	for _, name := range []string{"sda", "nvme0n1", "loop0"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(sysDir, "block", name), 0755))
+	}
+	t.Setenv("HOST_SYS", sysDir)
+
+	last := map[string]IOCountersStat{
+		"sda":     &LinuxIoCountersStat{ReadCount: 10, ReadBytes: 1000, ReadTime: 10},
+		"sda1":    &LinuxIoCountersStat{ReadCount: 10, ReadBytes: 1000, ReadTime: 10},
+		"nvme0n1": &LinuxIoCountersStat{WriteCount: 10},
+		"loop0":   &LinuxIoCountersStat{ReadCount: 10},
+	}
+	current := map[string]IOCountersStat{
+		"sda":     &LinuxIoCountersStat{ReadCount: 20, ReadBytes: 3000, ReadTime: 30, IoTime: 500},
+		"sda1":    &LinuxIoCountersStat{ReadCount: 20, ReadBytes: 3000, ReadTime: 30, IoTime: 500},
+		"nvme0n1": &LinuxIoCountersStat{WriteCount: 20},
+		"loop0":   &LinuxIoCountersStat{ReadCount: 20},
+		"sdb":     &LinuxIoCountersStat{ReadCount: 20},
+	}
+
+	// WHEN the device samples are calculated, ignoring nvme devices
+	samples := deviceSamples(current, last, 1000, &config.Config{FileDevicesIgnored: []string{"nvme"}})
+
+	// THEN only the whole devices with previous counters are reported
+	require.Len(t, samples, 1)
+	ds := samples[0].(*DeviceSample)
+	assert.Equal(t, "StorageDeviceSample", ds.EventType)
+	assert.Equal(t, "/dev/sda", ds.Device)
+	assert.Equal(t, "diskstats", ds.CountersSource)
+	assert.Equal(t, 2000.0, *ds.ReadBytesPerSec)
+	assert.Equal(t, 10.0, *ds.ReadsPerSec)
+	assert.Equal(t, 50.0, *ds.TotalUtilizationPercent)
+	assert.Equal(t, 2.0, *ds.ReadAwaitMs)
+	assert.Nil(t, ds.WriteAwaitMs)
+}
@@ -215,6 +215,7 @@ func TestPopulatePartition(t *testing.T) {
 					MountPoint:     "/",
 					Device:         "/dev/sda",
 					IsReadOnly:     "false",
+					MountOptions:   "rw",
 					FileSystemType: "ext4",
 				},
 			},
@@ -232,6 +233,7 @@ func TestPopulatePartition(t *testing.T) {
 					MountPoint:     "/",
 					Device:         "/dev/sda",
 					IsReadOnly:     "true",
+					MountOptions:   "ro",
 					FileSystemType: "ext2",
 				},
 			},
@@ -249,6 +251,7 @@ func TestPopulatePartition(t *testing.T) {
 					MountPoint:     "/",
 					Device:         "/dev/sda",
 					IsReadOnly:     "true",
+					MountOptions:   "rw,ro",
 					FileSystemType: "ext3",
 				},
 			},
@@ -266,6 +269,7 @@ func TestPopulatePartition(t *testing.T) {
 					MountPoint:     "/",
 					Device:         "/dev/sda",
 					IsReadOnly:     "true",
+					MountOptions:   "ro,rw",
 					FileSystemType: "ext3",
 				},
 			},
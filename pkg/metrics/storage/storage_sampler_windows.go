@@ -12,6 +12,7 @@ import (
 	"unsafe"
 
 	"github.com/newrelic/infrastructure-agent/pkg/config"
+	"github.com/newrelic/infrastructure-agent/pkg/sample"
 	"github.com/pkg/errors"
 	"github.com/shirou/gopsutil/v3/disk"
 )
@@ -193,3 +194,8 @@ func populateSampleOS(source, dest *Sample) {
 // populateUsage copies the Usage Stats inside the destination sample, for those metrics that are exclusive of Windows
 func populateUsageOS(fsUsage *disk.UsageStat, dest *Sample) {
 }
+
+// deviceSamples is only supported on Linux, where the IO counters of the whole devices are available
+func deviceSamples(_, _ map[string]IOCountersStat, _ int64, _ *config.Config) sample.EventBatch {
+	return nil
+}
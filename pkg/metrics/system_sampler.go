@@ -29,29 +29,32 @@ type SystemSample struct {
 	*MemorySample
 	*DiskSample
 	*HostSample
+	*PressureSample
 }
 
 type SystemSampler struct {
-	CpuMonitor     *CPUMonitor
-	DiskMonitor    *DiskMonitor
-	LoadMonitor    *LoadMonitor
-	MemoryMonitor  *MemoryMonitor
-	HostMonitor    *HostMonitor
-	context        agent.AgentContext
-	stopChannel    chan bool
-	waitForCleanup *sync.WaitGroup
+	CpuMonitor      *CPUMonitor
+	DiskMonitor     *DiskMonitor
+	LoadMonitor     *LoadMonitor
+	MemoryMonitor   *MemoryMonitor
+	HostMonitor     *HostMonitor
+	PressureMonitor *PressureMonitor
+	context         agent.AgentContext
+	stopChannel     chan bool
+	waitForCleanup  *sync.WaitGroup
 }
 
 func NewSystemSampler(context agent.AgentContext, storageSampler *storage.Sampler, ntpMonitor NtpMonitor) *SystemSampler {
 	cfg := context.Config()
 	return &SystemSampler{
-		CpuMonitor:     NewCPUMonitor(context),
-		DiskMonitor:    NewDiskMonitor(storageSampler),
-		LoadMonitor:    NewLoadMonitor(),
-		MemoryMonitor:  NewMemoryMonitor(cfg.IgnoreReclaimable),
-		HostMonitor:    NewHostMonitor(ntpMonitor),
-		context:        context,
-		waitForCleanup: &sync.WaitGroup{},
+		CpuMonitor:      NewCPUMonitor(context),
+		DiskMonitor:     NewDiskMonitor(storageSampler),
+		LoadMonitor:     NewLoadMonitor(),
+		MemoryMonitor:   NewMemoryMonitor(cfg.IgnoreReclaimable),
+		HostMonitor:     NewHostMonitor(ntpMonitor),
+		PressureMonitor: NewPressureMonitor(),
+		context:         context,
+		waitForCleanup:  &sync.WaitGroup{},
 	}
 }
 
@@ -143,7 +146,7 @@ func (s *SystemSampler) Sample() (results sample.EventBatch, err error) {
 	seg.End()
 
 	// Collect Host
-	_, seg = trx.StartSegment(ctx, "host sample")
+	ctx, seg = trx.StartSegment(ctx, "host sample")
 
 	hostSample, err := s.HostMonitor.Sample()
 	if err != nil {
@@ -155,6 +158,17 @@ func (s *SystemSampler) Sample() (results sample.EventBatch, err error) {
 	sysSample.HostSample = hostSample
 	seg.End()
 
+	// Collect Pressure Stall Information, not available on all kernels
+	_, seg = trx.StartSegment(ctx, "pressure sample")
+
+	pressureSample, pressureErr := s.PressureMonitor.Sample()
+	if pressureErr != nil {
+		syslog.WithError(pressureErr).Debug("Cannot sample pressure stall information.")
+	}
+
+	sysSample.PressureSample = pressureSample
+	seg.End()
+
 	helpers.LogStructureDetails(syslog, sysSample, "SystemSample", "final", nil)
 	results = append(results, sysSample)
 
@@ -11,7 +11,6 @@ import (
 
 type CustomAttrsPlugin struct {
 	agent.PluginCommon
-	customAttributes map[string]interface{}
 }
 
 type CustomAttrs map[string]interface{}
@@ -26,20 +25,21 @@ func NewCustomAttrsPlugin(ctx agent.AgentContext) agent.Plugin {
 			ID:      ids.CustomAttrsID,
 			Context: ctx,
 		},
-		customAttributes: ctx.Config().CustomAttributes,
 	}
 }
 
 // This plugin is pretty simple - it simply returns once with the object containing current custom attributes.
+// It's run again when the custom attributes are reloaded from the config.
 func (self *CustomAttrsPlugin) Run() {
 	self.Context.AddReconnecting(self)
 
-	data := agent.PluginInventoryDataset{CustomAttrs(self.customAttributes)}
+	customAttributes := self.Context.Config().CustomAttributes
+	data := agent.PluginInventoryDataset{CustomAttrs(customAttributes)}
 	entityKey := self.Context.EntityKey()
 
 	aclog.
 		WithField(config.TracesFieldName, config.FeatureTrace).
-		Tracef("run, entity: %s, data: %+v", entityKey, self.customAttributes)
+		Tracef("run, entity: %s, data: %+v", entityKey, customAttributes)
 
 	self.EmitInventory(data, entity.NewFromNameWithoutID(entityKey))
 }
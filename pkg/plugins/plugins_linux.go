@@ -9,9 +9,12 @@ import (
 	config2 "github.com/newrelic/infrastructure-agent/pkg/config"
 	"github.com/newrelic/infrastructure-agent/pkg/helpers"
 	"github.com/newrelic/infrastructure-agent/pkg/metrics"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/cgroup"
 	"github.com/newrelic/infrastructure-agent/pkg/metrics/network"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/network/netstat"
 	"github.com/newrelic/infrastructure-agent/pkg/metrics/process"
 	metricsSender "github.com/newrelic/infrastructure-agent/pkg/metrics/sender"
+	"github.com/newrelic/infrastructure-agent/pkg/metrics/sensor"
 	"github.com/newrelic/infrastructure-agent/pkg/metrics/storage"
 	"github.com/newrelic/infrastructure-agent/pkg/metrics/storage/nfs"
 	"github.com/newrelic/infrastructure-agent/pkg/plugins/ids"
@@ -71,9 +74,21 @@ func RegisterPlugins(agent *agnt.Agent) error {
 			agent.RegisterPlugin(NewConfigFilePlugin(ids.PluginID{"files", "config"}, agent.Context))
 		}
 		agent.RegisterPlugin(pluginsLinux.NewUsersPlugin(agent.Context))
+		agent.RegisterPlugin(pluginsLinux.NewAccountsPlugin(ids.PluginID{"config", "accounts"}, agent.Context))
+		agent.RegisterPlugin(pluginsLinux.NewScheduledJobsPlugin(ids.PluginID{"services", "scheduled_jobs"}, agent.Context))
 		agent.RegisterPlugin(pluginsLinux.NewDaemontoolsPlugin(ids.PluginID{"services", "daemontools"}, agent.Context))
 		agent.RegisterPlugin(pluginsLinux.NewSupervisorPlugin(ids.PluginID{"services", "supervisord"}, agent.Context))
 		agent.RegisterPlugin(NewNetworkInterfacePlugin(ids.PluginID{"system", "network_interfaces"}, agent.Context))
+		if config.EnableLanguagePackages {
+			for _, manager := range pluginsLinux.LanguagePackageManagers {
+				p, err := pluginsLinux.NewLanguagePackagesPlugin(manager, agent.Context)
+				if err != nil {
+					slog.WithError(err).Error("cannot initialize language packages plugin")
+					continue
+				}
+				agent.RegisterPlugin(p)
+			}
+		}
 
 		if config.RunMode == config2.ModeRoot || config.RunMode == config2.ModePrivileged {
 			id := ids.PluginID{"kernel", "sysctl"}
@@ -90,6 +105,7 @@ func RegisterPlugins(agent *agnt.Agent) error {
 			agent.RegisterPlugin(pluginsLinux.NewKernelModulesPlugin(ids.PluginID{"kernel", "modules"}, agent.Context))
 			agent.RegisterPlugin(pluginsLinux.NewSysvInitPlugin(ids.PluginID{"services", "pidfile"}, agent.Context))
 			agent.RegisterPlugin(pluginsLinux.NewSshdConfigPlugin(ids.PluginID{"config", "sshd"}, agent.Context))
+			agent.RegisterPlugin(pluginsLinux.NewListeningPortsPlugin(ids.PluginID{"system", "listening_ports"}, agent.Context))
 
 			// platform specific plugins
 			switch helpers.GetLinuxDistro() {
@@ -100,7 +116,17 @@ func RegisterPlugins(agent *agnt.Agent) error {
 			case helpers.LINUX_REDHAT, helpers.LINUX_AWS_REDHAT, helpers.LINUX_SUSE:
 				slog.Debug("Registering RPM plugins.")
 				agent.RegisterPlugin(pluginsLinux.NewRpmPlugin(agent.Context))
+
+			case helpers.LINUX_ALPINE:
+				slog.Debug("Registering Alpine plugins.")
+				agent.RegisterPlugin(pluginsLinux.NewApkPlugin(ids.PluginID{"packages", "apk"}, agent.Context))
+
+			case helpers.LINUX_ARCH:
+				slog.Debug("Registering Arch Linux plugins.")
+				agent.RegisterPlugin(pluginsLinux.NewPacmanPlugin(ids.PluginID{"packages", "pacman"}, agent.Context))
 			}
+			// snaps can be installed on any distro, the plugin unregisters itself when snapd isn't found
+			agent.RegisterPlugin(pluginsLinux.NewSnapPlugin(ids.PluginID{"packages", "snap"}, agent.Context))
 		}
 
 		if config.RunMode == config2.ModeRoot {
@@ -116,13 +142,21 @@ func RegisterPlugins(agent *agnt.Agent) error {
 	procSampler := process.NewProcessSampler(agent.Context)
 	storageSampler := storage.NewSampler(agent.Context)
 	nfsSampler := nfs.NewSampler(agent.Context)
+	cgroupSampler := cgroup.NewSampler(agent.Context)
 	networkSampler := network.NewNetworkSampler(agent.Context)
+	netstatSampler := netstat.NewSampler(agent.Context)
+	sensorSampler := sensor.NewSampler(agent.Context)
 
 	var ntpMonitor metrics.NtpMonitor
 	if config.NtpMetrics.Enabled {
-		ntpMonitor = metrics.NewNtp(config.NtpMetrics.Pool, config.NtpMetrics.Timeout, config.NtpMetrics.Interval)
+		ntp := metrics.NewNtp(config.NtpMetrics.Pool, config.NtpMetrics.Timeout, config.NtpMetrics.Interval)
+		ntpMonitor = ntp
+		if config.NtpMetrics.Local {
+			ntpMonitor = metrics.NewLocalNtp(ntp)
+		}
 	}
 	systemSampler := metrics.NewSystemSampler(agent.Context, storageSampler, ntpMonitor)
+	cpuCoreSampler := metrics.NewCPUCoreSampler(agent.Context)
 
 	// Prime Storage Sampler, ignoring results
 	if !storageSampler.Disabled() {
@@ -141,9 +175,13 @@ func RegisterPlugins(agent *agnt.Agent) error {
 	}
 
 	sender.RegisterSampler(systemSampler)
+	sender.RegisterSampler(cpuCoreSampler)
 	sender.RegisterSampler(storageSampler)
 	sender.RegisterSampler(nfsSampler)
+	sender.RegisterSampler(cgroupSampler)
 	sender.RegisterSampler(networkSampler)
+	sender.RegisterSampler(netstatSampler)
+	sender.RegisterSampler(sensorSampler)
 	sender.RegisterSampler(procSampler)
 
 	agent.RegisterMetricsSender(sender)